@@ -0,0 +1,149 @@
+package aeskw
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+)
+
+// Algorithm identifies one of the AES key wrap algorithms
+type Algorithm int
+
+const (
+	// A128KW is AES Key Wrap using a 128-bit key
+	A128KW Algorithm = 1
+
+	// A192KW is AES Key Wrap using a 192-bit key
+	A192KW Algorithm = 2
+
+	// A256KW is AES Key Wrap using a 256-bit key
+	A256KW Algorithm = 3
+)
+
+func algToString(alg Algorithm) string {
+	switch alg {
+	case A128KW:
+		return "A128KW"
+	case A192KW:
+		return "A192KW"
+	case A256KW:
+		return "A256KW"
+	default:
+		return ""
+	}
+}
+
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
+func keySize(alg Algorithm) int {
+	switch alg {
+	case A128KW:
+		return 16
+	case A192KW:
+		return 24
+	case A256KW:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// Provider provides AES Key Wrap as JWE key management algorithm
+type Provider struct {
+	alg      Algorithm
+	settings Settings
+	random   io.Reader
+}
+
+var _ jwt.EncryptionProvider = Provider{}
+
+// NewProvider creates a new Provider generating the necessary key
+func NewProvider(t Algorithm) (Provider, error) {
+	return NewProviderWithRandom(t, nil)
+}
+
+// NewProviderWithRandom works just like NewProvider but reads the randomness needed for the key, the key ID and content encryption keys from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	size := keySize(t)
+	if size == 0 {
+		return Provider{}, errors.New("type invalid")
+	}
+	kid, err := keyid.New(source)
+	if err != nil {
+		return Provider{}, err
+	}
+	key := make([]byte, size)
+	if _, err := io.ReadFull(source, key); err != nil {
+		return Provider{}, err
+	}
+	return Provider{t, Settings{key, kid}, random}, nil
+}
+
+// LoadProvider returns a Provider using the supplied settings
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	size := keySize(t)
+	if size == 0 {
+		return Provider{}, errors.New("type invalid")
+	}
+	if len(s.key) != size {
+		return Provider{}, errors.New("key size does not match algorithm")
+	}
+	return Provider{t, s, nil}, nil
+}
+
+// SetRandom sets the source of randomness used for content encryption keys. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for content encryption keys
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
+// Header sets the necessary JWT header fields
+func (p Provider) Header(h *jwt.Header) {
+	h.Alg = algToString(p.alg)
+	if p.settings.kid != "" {
+		h.Kid = p.settings.kid
+	}
+}
+
+// WrapKey generates a random content encryption key of the requested size and wraps it using the key
+func (p Provider) WrapKey(size int, h *jwt.Header) ([]byte, []byte, error) {
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(p.randomSource(), cek); err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := Wrap(p.settings.key, cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, wrapped, nil
+}
+
+// UnwrapKey decrypts the content encryption key
+func (p Provider) UnwrapKey(encryptedKey []byte, size int, h jwt.Header) ([]byte, error) {
+	if h.Kid != "" && h.Kid != p.settings.kid {
+		return nil, errors.New("unknown key id")
+	}
+	cek, err := Unwrap(p.settings.key, encryptedKey)
+	if err != nil || len(cek) != size {
+		return nil, errors.New("decryption failed")
+	}
+	return cek, nil
+}
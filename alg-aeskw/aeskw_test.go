@@ -0,0 +1,132 @@
+package aeskw
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func Test_algToString(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  Algorithm
+		want string
+	}{
+		{"A128KW", A128KW, "A128KW"},
+		{"A192KW", A192KW, "A192KW"},
+		{"A256KW", A256KW, "A256KW"},
+		{"Invalid", 12, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := algToString(tt.alg); got != tt.want {
+				t.Errorf("algToString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := jwt.Header{Typ: "JWT"}
+	Provider{A192KW, Settings{nil, "key_id"}, nil}.Header(&h)
+	if h.Alg != "A192KW" {
+		t.Errorf("Provider.Header() should set Alg to \"A192KW\" but instead it is %q", h.Alg)
+	}
+	if h.Kid != "key_id" {
+		t.Errorf("Provider.Header() should set Kid to \"key_id\" but instead it is %q", h.Kid)
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Settings
+		alg     Algorithm
+		wantErr bool
+	}{
+		{"A128KW", Settings{make([]byte, 16), "key_id"}, A128KW, false},
+		{"A256KW", Settings{make([]byte, 32), "key_id"}, A256KW, false},
+		{"WrongKeySize", Settings{make([]byte, 16), "key_id"}, A256KW, true},
+		{"UnknownAlgorithm", Settings{make([]byte, 16), "key_id"}, 12, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadProvider(tt.s, tt.alg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (got.alg != tt.alg || got.settings.kid != tt.s.kid) {
+				t.Errorf("LoadProvider() did not pass the data from the input settings onto the provider")
+			}
+		})
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	if _, err := NewProvider(12); err == nil {
+		t.Error("NewProvider() with an unknown algorithm type should fail but returned no error.")
+	}
+}
+
+func TestProvider_UnwrapKey(t *testing.T) {
+	p, err := NewProvider(A128KW)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	h := jwt.Header{}
+	p.Header(&h)
+	cek, encryptedKey, err := p.WrapKey(32, &h)
+	if err != nil {
+		t.Fatalf("Provider.WrapKey() failed: %s", err.Error())
+	}
+	tests := []struct {
+		name         string
+		encryptedKey []byte
+		size         int
+		kid          string
+		wantErr      bool
+	}{
+		{"Normal", encryptedKey, 32, h.Kid, false},
+		{"NoKeyID", encryptedKey, 32, "", false},
+		{"UnknownKeyID", encryptedKey, 32, "unknown", true},
+		{"WrongSize", encryptedKey, 16, h.Kid, true},
+		{"Modified", append([]byte{0x00}, encryptedKey[1:]...), 32, h.Kid, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.UnwrapKey(tt.encryptedKey, tt.size, jwt.Header{Kid: tt.kid})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Provider.UnwrapKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, cek) {
+				t.Errorf("Provider.UnwrapKey() = %x, want %x", got, cek)
+			}
+		})
+	}
+}
+
+func TestNewProviderWithRandom(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 16+32)
+	a, err := NewProviderWithRandom(A256KW, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	b, err := NewProviderWithRandom(A256KW, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(a.settings, b.settings) {
+		t.Errorf("NewProviderWithRandom() generated different keys from the same random data: %v and %v", a.settings, b.settings)
+	}
+	if _, err := NewProviderWithRandom(A256KW, bytes.NewReader(seed[:16])); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	a.SetRandom(bytes.NewReader(nil))
+	if _, _, err := a.WrapKey(16, &jwt.Header{}); err == nil {
+		t.Error("WrapKey() did not use the random source set by SetRandom()")
+	}
+}
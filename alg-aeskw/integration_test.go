@@ -0,0 +1,35 @@
+package aeskw
+
+import (
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func TestEncryption(t *testing.T) {
+	for _, alg := range []Algorithm{A128KW, A192KW, A256KW} {
+		t.Run(algToString(alg), func(t *testing.T) {
+			p, err := NewProvider(alg)
+			if err != nil {
+				t.Fatalf("Could not initialize provider: %s", err.Error())
+			}
+			jwt.SetEncryptionProvider(algToString(alg), p)
+			jwt.SetEncryptionAlgorithm(algToString(alg)) // nolint:errcheck
+			token, err := jwt.New([]byte(`{"test": 1}`)).EncodeEncrypted()
+			if err != nil {
+				t.Fatalf("Could not encrypt JWT: %s", err.Error())
+			}
+			t.Logf("JWT encrypted to: %s", string(token))
+			dec, err := jwt.DecodeEncrypted(token)
+			if err != nil {
+				t.Fatalf("Could not decode JWT: %s", err.Error())
+			}
+			if !dec.Valid() {
+				t.Errorf("Decoded JWT could not be decrypted: %s", dec.ValidationError().Error())
+			}
+			if string(dec.Content) != `{"test": 1}` {
+				t.Errorf("Decrypted content %s does not match original content", dec.Content)
+			}
+		})
+	}
+}
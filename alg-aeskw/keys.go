@@ -0,0 +1,27 @@
+package aeskw
+
+import (
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Settings stores the key for an algorithm
+type Settings struct {
+	key []byte
+	kid string
+}
+
+// NewSettings creates new settings for the parameters. The key has to be 16, 24 or 32 bytes long.
+func NewSettings(key []byte, keyID string) (Settings, error) {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return Settings{}, errors.New("key has invalid length")
+	}
+	return Settings{key, keyID}, nil
+}
+
+// CurrentKey returns the key used for wrapping.
+// CAUTION: The key is used for both encryption and decryption. Do not share the key you obtain using this function
+func (p Provider) CurrentKey() publickey.PublicKey {
+	return publickey.New(p.settings.key, p.settings.kid)
+}
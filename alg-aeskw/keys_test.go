@@ -0,0 +1,40 @@
+package aeskw
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"128Bit", make([]byte, 16), false},
+		{"192Bit", make([]byte, 24), false},
+		{"256Bit", make([]byte, 32), false},
+		{"Empty", nil, true},
+		{"InvalidLength", make([]byte, 20), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettings(tt.key, "key_id")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (!bytes.Equal(got.key, tt.key) || got.kid != "key_id") {
+				t.Errorf("NewSettings() = %v, want key %x with key ID \"key_id\"", got, tt.key)
+			}
+		})
+	}
+}
+
+func TestProvider_CurrentKey(t *testing.T) {
+	p := Provider{A128KW, Settings{[]byte("0123456789abcdef"), "key_id"}, nil}
+	k := p.CurrentKey()
+	if string(k.GetPublicKey()) != "0123456789abcdef" || k.GetKeyID() != "key_id" {
+		t.Errorf("Provider.CurrentKey() returned unexpected key %v", k)
+	}
+}
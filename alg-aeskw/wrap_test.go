@@ -0,0 +1,75 @@
+package aeskw
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestWrap(t *testing.T) {
+	// Test vectors from RFC 3394 section 4
+	tests := []struct {
+		name    string
+		kek     string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"128BitKEK", "000102030405060708090A0B0C0D0E0F", "00112233445566778899AABBCCDDEEFF", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5", false},
+		{"192BitKEK", "000102030405060708090A0B0C0D0E0F1011121314151617", "00112233445566778899AABBCCDDEEFF", "96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D", false},
+		{"256BitKEK192BitKey", "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F", "00112233445566778899AABBCCDDEEFF0001020304050607", "A8F9BC1612C68B3FF6E6F4FBE30E71E4769C8B80A32CB8958CD5D17D6B254DA1", false},
+		{"ShortKey", "000102030405060708090A0B0C0D0E0F", "0011223344556677", "", true},
+		{"UnalignedKey", "000102030405060708090A0B0C0D0E0F", "00112233445566778899AABBCCDDEEFF00", "", true},
+		{"InvalidKEK", "0001020304", "00112233445566778899AABBCCDDEEFF", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Wrap(mustDecodeHex(tt.kek), mustDecodeHex(tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Wrap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, mustDecodeHex(tt.want)) {
+				t.Errorf("Wrap() = %X, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		kek     string
+		wrapped string
+		want    string
+		wantErr bool
+	}{
+		{"128BitKEK", "000102030405060708090A0B0C0D0E0F", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5", "00112233445566778899AABBCCDDEEFF", false},
+		{"256BitKEK192BitKey", "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F", "A8F9BC1612C68B3FF6E6F4FBE30E71E4769C8B80A32CB8958CD5D17D6B254DA1", "00112233445566778899AABBCCDDEEFF0001020304050607", false},
+		{"WrongKEK", "0F0E0D0C0B0A09080706050403020100", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5", "", true},
+		{"Modified", "000102030405060708090A0B0C0D0E0F", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE6", "", true},
+		{"Short", "000102030405060708090A0B0C0D0E0F", "1FA68B0A8112B447AEF34BD8FB5A7B82", "", true},
+		{"Unaligned", "000102030405060708090A0B0C0D0E0F", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE500", "", true},
+		{"InvalidKEK", "0001020304", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unwrap(mustDecodeHex(tt.kek), mustDecodeHex(tt.wrapped))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Unwrap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, mustDecodeHex(tt.want)) {
+				t.Errorf("Unwrap() = %X, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package dir
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+)
+
+// Provider provides direct encryption using a shared symmetric key as JWE key management algorithm.
+// The key is used as content encryption key so its size has to match the content encryption algorithm.
+type Provider struct {
+	settings Settings
+}
+
+var _ jwt.EncryptionProvider = Provider{}
+
+// NewProvider creates a new Provider generating a key of the specified size in bytes.
+// A128GCM, A192GCM and A256GCM require 16, 24 and 32 bytes while A128CBC-HS256, A192CBC-HS384 and A256CBC-HS512 require 32, 48 and 64 bytes.
+func NewProvider(size int) (Provider, error) {
+	return NewProviderWithRandom(size, nil)
+}
+
+// NewProviderWithRandom works just like NewProvider but reads the key and the key ID from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(size int, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	if size <= 0 {
+		return Provider{}, errors.New("key size must be positive")
+	}
+	kid, err := keyid.New(source)
+	if err != nil {
+		return Provider{}, err
+	}
+	key := make([]byte, size)
+	if _, err := io.ReadFull(source, key); err != nil {
+		return Provider{}, err
+	}
+	return Provider{Settings{key, kid}}, nil
+}
+
+// LoadProvider returns a Provider using the supplied settings
+func LoadProvider(s Settings) (Provider, error) {
+	if len(s.key) == 0 {
+		return Provider{}, errors.New("empty keys are not allowed")
+	}
+	return Provider{s}, nil
+}
+
+// Header sets the necessary JWT header fields
+func (p Provider) Header(h *jwt.Header) {
+	h.Alg = "dir"
+	if p.settings.kid != "" {
+		h.Kid = p.settings.kid
+	}
+}
+
+// WrapKey returns the key as content encryption key and an empty encrypted key
+func (p Provider) WrapKey(size int, h *jwt.Header) ([]byte, []byte, error) {
+	if len(p.settings.key) != size {
+		return nil, nil, errors.New("key size does not match content encryption algorithm")
+	}
+	return p.settings.key, nil, nil
+}
+
+// UnwrapKey returns the key as content encryption key
+func (p Provider) UnwrapKey(encryptedKey []byte, size int, h jwt.Header) ([]byte, error) {
+	if h.Kid != "" && h.Kid != p.settings.kid {
+		return nil, errors.New("unknown key id")
+	}
+	if len(encryptedKey) != 0 {
+		return nil, errors.New("encrypted key must be empty for direct encryption")
+	}
+	if len(p.settings.key) != size {
+		return nil, errors.New("key size does not match content encryption algorithm")
+	}
+	return p.settings.key, nil
+}
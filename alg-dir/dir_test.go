@@ -0,0 +1,107 @@
+package dir
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func TestHeader(t *testing.T) {
+	h := jwt.Header{Typ: "JWT"}
+	Provider{Settings{nil, "key_id"}}.Header(&h)
+	if h.Alg != "dir" {
+		t.Errorf("Provider.Header() should set Alg to \"dir\" but instead it is %q", h.Alg)
+	}
+	if h.Kid != "key_id" {
+		t.Errorf("Provider.Header() should set Kid to \"key_id\" but instead it is %q", h.Kid)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(0); err == nil {
+		t.Error("NewProvider() should fail for key size 0")
+	}
+	p, err := NewProvider(32)
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %s", err.Error())
+	}
+	if len(p.settings.key) != 32 {
+		t.Errorf("NewProvider() generated key of size %d, want 32", len(p.settings.key))
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	if _, err := LoadProvider(Settings{}); err == nil {
+		t.Error("LoadProvider() should fail for empty key")
+	}
+	p, err := LoadProvider(Settings{[]byte("key"), "key_id"})
+	if err != nil {
+		t.Fatalf("LoadProvider() failed: %s", err.Error())
+	}
+	if p.settings.kid != "key_id" {
+		t.Errorf("LoadProvider() did not pass the data from the input settings onto the provider")
+	}
+}
+
+func TestProvider_WrapKey(t *testing.T) {
+	p := Provider{Settings{[]byte("0123456789abcdef"), "key_id"}}
+	cek, encryptedKey, err := p.WrapKey(16, &jwt.Header{})
+	if err != nil {
+		t.Fatalf("Provider.WrapKey() failed: %s", err.Error())
+	}
+	if !bytes.Equal(cek, p.settings.key) || len(encryptedKey) != 0 {
+		t.Errorf("Provider.WrapKey() = %x, %x, want %x and empty encrypted key", cek, encryptedKey, p.settings.key)
+	}
+	if _, _, err := p.WrapKey(32, &jwt.Header{}); err == nil {
+		t.Error("Provider.WrapKey() should fail when key size does not match")
+	}
+}
+
+func TestProvider_UnwrapKey(t *testing.T) {
+	p := Provider{Settings{[]byte("0123456789abcdef"), "key_id"}}
+	tests := []struct {
+		name         string
+		encryptedKey []byte
+		size         int
+		kid          string
+		wantErr      bool
+	}{
+		{"Normal", nil, 16, "key_id", false},
+		{"NoKeyID", nil, 16, "", false},
+		{"UnknownKeyID", nil, 16, "unknown", true},
+		{"EncryptedKey", []byte("key"), 16, "key_id", true},
+		{"WrongSize", nil, 32, "key_id", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.UnwrapKey(tt.encryptedKey, tt.size, jwt.Header{Kid: tt.kid})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Provider.UnwrapKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, p.settings.key) {
+				t.Errorf("Provider.UnwrapKey() = %x, want %x", got, p.settings.key)
+			}
+		})
+	}
+}
+
+func TestNewProviderWithRandom(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 16+32)
+	a, err := NewProviderWithRandom(32, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	b, err := NewProviderWithRandom(32, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("NewProviderWithRandom() generated different keys from the same random data: %v and %v", a, b)
+	}
+	if _, err := NewProviderWithRandom(32, bytes.NewReader(seed[:16])); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+}
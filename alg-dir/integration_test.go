@@ -0,0 +1,46 @@
+package dir
+
+import (
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func TestEncryption(t *testing.T) {
+	tests := []struct {
+		enc  string
+		size int
+	}{
+		{"A128GCM", 16},
+		{"A256GCM", 32},
+		{"A128CBC-HS256", 32},
+		{"A256CBC-HS512", 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.enc, func(t *testing.T) {
+			p, err := NewProvider(tt.size)
+			if err != nil {
+				t.Fatalf("Could not initialize provider: %s", err.Error())
+			}
+			jwt.SetEncryptionProvider("dir", p)
+			jwt.SetEncryptionAlgorithm("dir")         // nolint:errcheck
+			jwt.SetContentEncryptionAlgorithm(tt.enc) // nolint:errcheck
+			token, err := jwt.New([]byte(`{"test": 1}`)).EncodeEncrypted()
+			if err != nil {
+				t.Fatalf("Could not encrypt JWT: %s", err.Error())
+			}
+			t.Logf("JWT encrypted to: %s", string(token))
+			dec, err := jwt.DecodeEncrypted(token)
+			if err != nil {
+				t.Fatalf("Could not decode JWT: %s", err.Error())
+			}
+			if !dec.Valid() {
+				t.Errorf("Decoded JWT could not be decrypted: %s", dec.ValidationError().Error())
+			}
+			if string(dec.Content) != `{"test": 1}` {
+				t.Errorf("Decrypted content %s does not match original content", dec.Content)
+			}
+		})
+	}
+	jwt.SetContentEncryptionAlgorithm("A256GCM") // nolint:errcheck
+}
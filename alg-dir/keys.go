@@ -0,0 +1,27 @@
+package dir
+
+import (
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Settings stores the key for an algorithm
+type Settings struct {
+	key []byte
+	kid string
+}
+
+// NewSettings creates new settings for the parameters
+func NewSettings(key []byte, keyID string) (Settings, error) {
+	if len(key) == 0 {
+		return Settings{}, errors.New("empty keys are not allowed")
+	}
+	return Settings{key, keyID}, nil
+}
+
+// CurrentKey returns the key used for encryption.
+// CAUTION: The key is used for both encryption and decryption. Do not share the key you obtain using this function
+func (p Provider) CurrentKey() publickey.PublicKey {
+	return publickey.New(p.settings.key, p.settings.kid)
+}
@@ -0,0 +1,25 @@
+package dir
+
+import (
+	"testing"
+)
+
+func TestNewSettings(t *testing.T) {
+	if _, err := NewSettings(nil, "key_id"); err == nil {
+		t.Error("NewSettings() should fail for empty key")
+	}
+	s, err := NewSettings([]byte("key"), "key_id")
+	if err != nil {
+		t.Fatalf("NewSettings() failed: %s", err.Error())
+	}
+	if string(s.key) != "key" || s.kid != "key_id" {
+		t.Errorf("NewSettings() = %v, want key \"key\" with key ID \"key_id\"", s)
+	}
+}
+
+func TestProvider_CurrentKey(t *testing.T) {
+	k := Provider{Settings{[]byte("key"), "key_id"}}.CurrentKey()
+	if string(k.GetPublicKey()) != "key" || k.GetKeyID() != "key_id" {
+		t.Errorf("Provider.CurrentKey() returned unexpected key %v", k)
+	}
+}
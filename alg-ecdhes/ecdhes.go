@@ -0,0 +1,256 @@
+package ecdhes
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/alg-aeskw"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+	"github.com/fossoreslp/go-jwt/jwk"
+)
+
+// Algorithm identifies one of the ECDH-ES key agreement algorithms
+type Algorithm int
+
+const (
+	// ECDHES is Elliptic Curve Diffie-Hellman Ephemeral Static key agreement using Concat KDF to derive the content encryption key
+	ECDHES Algorithm = 1
+
+	// ECDHESA128KW is ECDH-ES using Concat KDF and content encryption key wrapped with A128KW
+	ECDHESA128KW Algorithm = 2
+
+	// ECDHESA192KW is ECDH-ES using Concat KDF and content encryption key wrapped with A192KW
+	ECDHESA192KW Algorithm = 3
+
+	// ECDHESA256KW is ECDH-ES using Concat KDF and content encryption key wrapped with A256KW
+	ECDHESA256KW Algorithm = 4
+)
+
+func algToString(alg Algorithm) string {
+	switch alg {
+	case ECDHES:
+		return "ECDH-ES"
+	case ECDHESA128KW:
+		return "ECDH-ES+A128KW"
+	case ECDHESA192KW:
+		return "ECDH-ES+A192KW"
+	case ECDHESA256KW:
+		return "ECDH-ES+A256KW"
+	default:
+		return ""
+	}
+}
+
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
+// wrapSize returns the size of the key used to wrap the content encryption key or zero for direct key agreement
+func wrapSize(alg Algorithm) int {
+	switch alg {
+	case ECDHESA128KW:
+		return 16
+	case ECDHESA192KW:
+		return 24
+	case ECDHESA256KW:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// Provider provides ECDH-ES using the NIST curves as JWE key management algorithm.
+// Content encryption keys are agreed upon with the recipient and derived using the private key of the settings for decryption.
+type Provider struct {
+	alg       Algorithm
+	settings  Settings
+	recipient *ecdsa.PublicKey
+	kid       string
+	jku       string
+	random    io.Reader
+}
+
+var _ jwt.EncryptionProvider = Provider{}
+
+// NewProvider creates a new Provider generating the necessary keypairs using P-256
+func NewProvider(t Algorithm) (Provider, error) {
+	return NewProviderWithKeyURL(t, "")
+}
+
+// NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key, the key ID, ephemeral keys and content encryption keys from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	if algToString(t) == "" {
+		return Provider{}, errors.New("type invalid")
+	}
+	kid, err := keyid.New(source)
+	if err != nil {
+		return Provider{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), source)
+	if err != nil {
+		return Provider{}, err
+	}
+	p, err := LoadProvider(Settings{key, kid, keyURL}, t)
+	if err != nil {
+		return Provider{}, err
+	}
+	p.random = random
+	return p, nil
+}
+
+// LoadProvider returns a Provider using the supplied settings.
+// Tokens will be encrypted for the public key belonging to the private key of the settings until a different recipient is set.
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if algToString(t) == "" {
+		return Provider{}, errors.New("type invalid")
+	}
+	if s.private == nil {
+		return Provider{}, errors.New("settings do not contain a private key")
+	}
+	return Provider{t, s, &s.private.PublicKey, s.kid, s.jku, nil}, nil
+}
+
+// SetRandom sets the source of randomness used for ephemeral keys and content encryption keys. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for ephemeral keys and content encryption keys
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
+// Header sets the necessary JWT header fields
+func (p Provider) Header(h *jwt.Header) {
+	h.Alg = algToString(p.alg)
+	if p.kid != "" {
+		h.Kid = p.kid
+	}
+	if p.jku != "" {
+		h.Jku = p.jku
+	}
+}
+
+// WrapKey generates an ephemeral key, adds it to the header and derives the content encryption key or the key used to wrap it from the shared secret
+func (p Provider) WrapKey(size int, h *jwt.Header) ([]byte, []byte, error) {
+	apu, apv, err := partyInfo(*h)
+	if err != nil {
+		return nil, nil, err
+	}
+	eph, err := ecdsa.GenerateKey(p.recipient.Curve, p.randomSource())
+	if err != nil {
+		return nil, nil, err
+	}
+	epk, err := jwk.NewECKey(&eph.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	h.Epk = &epk
+	z, err := sharedSecret(eph, p.recipient)
+	if err != nil {
+		return nil, nil, err
+	}
+	kw := wrapSize(p.alg)
+	if kw == 0 {
+		return deriveKey(z, h.Enc, apu, apv, size), nil, nil
+	}
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(p.randomSource(), cek); err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := aeskw.Wrap(deriveKey(z, h.Alg, apu, apv, kw), cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, wrapped, nil
+}
+
+// UnwrapKey derives the content encryption key or the key used to wrap it from the ephemeral key in the header and the private key
+func (p Provider) UnwrapKey(encryptedKey []byte, size int, h jwt.Header) ([]byte, error) {
+	if h.Kid != "" && h.Kid != p.settings.kid {
+		return nil, errors.New("unknown key id")
+	}
+	if h.Epk == nil {
+		return nil, errors.New("header does not contain an ephemeral public key")
+	}
+	k, err := h.Epk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	// The key has already been checked to be an EC key on a supported curve by the jwk package
+	pub, err := x509.ParsePKIXPublicKey(k.GetPublicKey())
+	if err != nil {
+		return nil, err
+	}
+	epk, ok := pub.(*ecdsa.PublicKey)
+	if !ok || epk.Curve != p.settings.private.Curve {
+		return nil, errors.New("ephemeral public key does not match curve")
+	}
+	apu, apv, err := partyInfo(h)
+	if err != nil {
+		return nil, err
+	}
+	z, err := sharedSecret(p.settings.private, epk)
+	if err != nil {
+		return nil, err
+	}
+	kw := wrapSize(p.alg)
+	if kw == 0 {
+		if len(encryptedKey) != 0 {
+			return nil, errors.New("encrypted key must be empty for direct key agreement")
+		}
+		return deriveKey(z, h.Enc, apu, apv, size), nil
+	}
+	cek, err := aeskw.Unwrap(deriveKey(z, h.Alg, apu, apv, kw), encryptedKey)
+	if err != nil || len(cek) != size {
+		return nil, errors.New("decryption failed")
+	}
+	return cek, nil
+}
+
+// partyInfo decodes the agreement party information from the header
+func partyInfo(h jwt.Header) ([]byte, []byte, error) {
+	apu, err := base64.RawURLEncoding.DecodeString(h.Apu)
+	if err != nil {
+		return nil, nil, err
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(h.Apv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return apu, apv, nil
+}
+
+// sharedSecret returns the x coordinate of the shared point encoded using the byte size of the curve.
+// The keys are converted to crypto/ecdh which computes the shared secret in constant time and rejects points that are not on the curve.
+func sharedSecret(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) ([]byte, error) {
+	ecdhPriv, err := priv.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	ecdhPub, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return ecdhPriv.ECDH(ecdhPub)
+}
@@ -0,0 +1,152 @@
+package ecdhes
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/jwk"
+)
+
+var testKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+func Test_algToString(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  Algorithm
+		want string
+	}{
+		{"ECDHES", ECDHES, "ECDH-ES"},
+		{"ECDHESA128KW", ECDHESA128KW, "ECDH-ES+A128KW"},
+		{"ECDHESA192KW", ECDHESA192KW, "ECDH-ES+A192KW"},
+		{"ECDHESA256KW", ECDHESA256KW, "ECDH-ES+A256KW"},
+		{"Invalid", 12, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := algToString(tt.alg); got != tt.want {
+				t.Errorf("algToString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := jwt.Header{Typ: "JWT"}
+	Provider{alg: ECDHESA128KW, kid: "key_id", jku: "key_url"}.Header(&h)
+	if h.Alg != "ECDH-ES+A128KW" {
+		t.Errorf("Provider.Header() should set Alg to \"ECDH-ES+A128KW\" but instead it is %q", h.Alg)
+	}
+	if h.Kid != "key_id" {
+		t.Errorf("Provider.Header() should set Kid to \"key_id\" but instead it is %q", h.Kid)
+	}
+	if h.Jku != "key_url" {
+		t.Errorf("Provider.Header() should set Jku to \"key_url\" but instead it is %q", h.Jku)
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	if _, err := LoadProvider(Settings{testKey, "key_id", ""}, 12); err == nil {
+		t.Error("LoadProvider() with an unknown algorithm type did not return an error.")
+	}
+	if _, err := LoadProvider(Settings{nil, "key_id", ""}, ECDHES); err == nil {
+		t.Error("LoadProvider() without a private key did not return an error.")
+	}
+	p, err := LoadProvider(Settings{testKey, "key_id", "key_url"}, ECDHES)
+	if err != nil {
+		t.Fatalf("LoadProvider() failed: %s", err.Error())
+	}
+	if p.alg != ECDHES || p.kid != "key_id" || p.jku != "key_url" || p.recipient != &testKey.PublicKey {
+		t.Errorf("LoadProvider() did not pass the data from the input settings onto the provider")
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	if _, err := NewProvider(12); err == nil {
+		t.Error("NewProvider() with an unknown algorithm type should fail but returned no error.")
+	}
+}
+
+func TestProvider_WrapKey(t *testing.T) {
+	p, _ := LoadProvider(Settings{testKey, "key_id", ""}, ECDHES)
+	if _, _, err := p.WrapKey(16, &jwt.Header{Enc: "A128GCM", Apu: "invalid base64!"}); err == nil {
+		t.Error("Provider.WrapKey() should fail for invalid agreement party information")
+	}
+	p224, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	p.recipient = &p224.PublicKey
+	if _, _, err := p.WrapKey(16, &jwt.Header{Enc: "A128GCM"}); err == nil {
+		t.Error("Provider.WrapKey() should fail for recipient on unsupported curve")
+	}
+}
+
+func TestProvider_UnwrapKey(t *testing.T) {
+	for _, alg := range []Algorithm{ECDHES, ECDHESA256KW} {
+		t.Run(algToString(alg), func(t *testing.T) {
+			p, err := LoadProvider(Settings{testKey, "key_id", ""}, alg)
+			if err != nil {
+				t.Fatalf("Could not initialize provider: %s", err.Error())
+			}
+			h := jwt.Header{Enc: "A128GCM", Apu: "QWxpY2U", Apv: "Qm9i"}
+			p.Header(&h)
+			cek, encryptedKey, err := p.WrapKey(16, &h)
+			if err != nil {
+				t.Fatalf("Provider.WrapKey() failed: %s", err.Error())
+			}
+			p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+			wrongCurve, _ := jwk.NewECKey(&p384.PublicKey)
+			withHeader := func(f func(h *jwt.Header)) jwt.Header {
+				c := h
+				f(&c)
+				return c
+			}
+			tests := []struct {
+				name         string
+				encryptedKey []byte
+				size         int
+				h            jwt.Header
+				wantErr      bool
+			}{
+				{"Normal", encryptedKey, 16, h, false},
+				{"UnknownKeyID", encryptedKey, 16, withHeader(func(h *jwt.Header) { h.Kid = "unknown" }), true},
+				{"NoEphemeralKey", encryptedKey, 16, withHeader(func(h *jwt.Header) { h.Epk = nil }), true},
+				{"InvalidEphemeralKey", encryptedKey, 16, withHeader(func(h *jwt.Header) { h.Epk = &jwk.Key{Kty: "EC"} }), true},
+				{"WrongCurve", encryptedKey, 16, withHeader(func(h *jwt.Header) { h.Epk = &wrongCurve }), true},
+				{"InvalidPartyInfo", encryptedKey, 16, withHeader(func(h *jwt.Header) { h.Apv = "invalid base64!" }), true},
+				{"InvalidEncryptedKey", []byte("key"), 16, h, true},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, err := p.UnwrapKey(tt.encryptedKey, tt.size, tt.h)
+					if (err != nil) != tt.wantErr {
+						t.Errorf("Provider.UnwrapKey() error = %v, wantErr %v", err, tt.wantErr)
+						return
+					}
+					if !tt.wantErr && !bytes.Equal(got, cek) {
+						t.Errorf("Provider.UnwrapKey() = %x, want %x", got, cek)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestProvider_SetRandom(t *testing.T) {
+	if _, err := NewProviderWithRandom(ECDHESA128KW, "key_url", bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	p, err := NewProviderWithRandom(ECDHESA128KW, "key_url", rand.Reader)
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	p.SetRandom(bytes.NewReader(nil))
+	if _, _, err := p.WrapKey(16, &jwt.Header{Alg: algToString(ECDHESA128KW), Enc: "A128GCM"}); err == nil {
+		t.Error("WrapKey() did not use the random source set by SetRandom()")
+	}
+	p.SetRandom(nil)
+	if _, _, err := p.WrapKey(16, &jwt.Header{Alg: algToString(ECDHESA128KW), Enc: "A128GCM"}); err != nil {
+		t.Errorf("WrapKey() failed after resetting the random source: %s", err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+package ecdhes
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// deriveKey derives a key of the requested size from the shared secret using the Concat KDF with SHA-256 as specified in RFC 7518 section 4.6.2
+func deriveKey(z []byte, alg string, apu, apv []byte, size int) []byte {
+	info := lengthPrefixed([]byte(alg))
+	info = append(info, lengthPrefixed(apu)...)
+	info = append(info, lengthPrefixed(apv)...)
+	info = append(info, uint32Bytes(uint32(size*8))...)
+	var out []byte
+	for counter := uint32(1); len(out) < size; counter++ {
+		h := sha256.New()
+		// SHA2 does not return errors
+		h.Write(uint32Bytes(counter)) // nolint:errcheck
+		h.Write(z)                    // nolint:errcheck
+		h.Write(info)                 // nolint:errcheck
+		out = h.Sum(out)
+	}
+	return out[:size]
+}
+
+func lengthPrefixed(data []byte) []byte {
+	return append(uint32Bytes(uint32(len(data))), data...)
+}
+
+func uint32Bytes(i uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, i)
+	return b
+}
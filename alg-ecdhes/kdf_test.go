@@ -0,0 +1,60 @@
+package ecdhes
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"math/big"
+	"testing"
+)
+
+func decodeInt(s string) *big.Int {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func Test_deriveKey(t *testing.T) {
+	// Example from RFC 7518 appendix C
+	bob := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     decodeInt("weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ"),
+			Y:     decodeInt("e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck"),
+		},
+		D: decodeInt("VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw"),
+	}
+	alice := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     decodeInt("gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0"),
+		Y:     decodeInt("SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps"),
+	}
+	want := []byte{86, 170, 141, 234, 248, 35, 109, 32, 92, 34, 40, 205, 113, 167, 16, 26}
+	z, err := sharedSecret(bob, alice)
+	if err != nil {
+		t.Fatalf("sharedSecret() failed: %s", err.Error())
+	}
+	got := deriveKey(z, "A128GCM", []byte("Alice"), []byte("Bob"), 16)
+	if !bytes.Equal(got, want) {
+		t.Errorf("deriveKey() = %v, want %v", got, want)
+	}
+	if _, err := sharedSecret(bob, &ecdsa.PublicKey{Curve: elliptic.P256(), X: alice.X, Y: alice.X}); err == nil {
+		t.Error("sharedSecret() should fail for a point that is not on the curve")
+	}
+}
+
+func Test_deriveKey_Size(t *testing.T) {
+	z := []byte("shared secret")
+	for _, size := range []int{16, 24, 32, 48, 64} {
+		got := deriveKey(z, "A256CBC-HS512", nil, nil, size)
+		if len(got) != size {
+			t.Errorf("deriveKey() returned %d bytes, want %d", len(got), size)
+		}
+		if !bytes.Equal(got[:16], deriveKey(z, "A256CBC-HS512", nil, nil, size)[:16]) {
+			t.Error("deriveKey() is not deterministic")
+		}
+	}
+}
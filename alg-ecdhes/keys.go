@@ -0,0 +1,63 @@
+package ecdhes
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Settings stores the key for an algorithm
+type Settings struct {
+	private *ecdsa.PrivateKey
+	kid     string
+	jku     string
+}
+
+// NewSettings creates new settings for the parameters
+func NewSettings(key []byte, keyID string) (Settings, error) {
+	return NewSettingsWithKeyURL(key, keyID, "")
+}
+
+// NewSettingsWithKeyURL creates new settings for the parameters
+func NewSettingsWithKeyURL(key []byte, keyID, keyURL string) (Settings, error) {
+	priv, err := x509.ParseECPrivateKey(key)
+	if err != nil {
+		k, err := x509.ParsePKCS8PrivateKey(key)
+		if err != nil {
+			return Settings{}, errors.New("could not decode private key as either EC or PKCS8")
+		}
+		ecKey, ok := k.(*ecdsa.PrivateKey)
+		if !ok {
+			return Settings{}, errors.New("PKCS8 does not contain an ECDSA private key")
+		}
+		priv = ecKey
+	}
+	return Settings{priv, keyID, keyURL}, nil
+}
+
+// SetRecipient sets the public key tokens will be encrypted for. The key has to be encoded as PKIX.
+func (p *Provider) SetRecipient(key publickey.PublicKey) error {
+	if err := key.CheckUsage(algToString(p.alg), "EC", "enc", "deriveKey"); err != nil {
+		return err
+	}
+	pub, err := x509.ParsePKIXPublicKey(key.GetPublicKey())
+	if err != nil {
+		return errors.New("could not decode public key")
+	}
+	ecKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("public key is not an ECDSA public key")
+	}
+	p.recipient = ecKey
+	p.kid = key.GetKeyID()
+	p.jku = ""
+	return nil
+}
+
+// CurrentKey returns the public key belonging to the private key used for decryption. Other parties can use it to encrypt tokens for this provider.
+func (p Provider) CurrentKey() publickey.PublicKey {
+	key, _ := x509.MarshalPKIXPublicKey(&p.settings.private.PublicKey) // No need to check error as marshaling an EC public key can only fail for an unsupported curve which cannot be introduced as it would fail to unmarshal.
+	return publickey.New(key, p.settings.kid)
+}
@@ -0,0 +1,81 @@
+package ecdhes
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestNewSettings(t *testing.T) {
+	ec, _ := x509.MarshalECPrivateKey(testKey)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(testKey)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	rsa8, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"EC", ec, false},
+		{"PKCS8", pkcs8, false},
+		{"PKCS8NotEC", rsa8, true},
+		{"Invalid", []byte("key"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettingsWithKeyURL(tt.key, "key_id", "key_url")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettingsWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (got.private.D.Cmp(testKey.D) != 0 || got.kid != "key_id" || got.jku != "key_url") {
+				t.Errorf("NewSettingsWithKeyURL() did not return the expected settings")
+			}
+		})
+	}
+}
+
+func TestProvider_SetRecipient(t *testing.T) {
+	p, err := LoadProvider(Settings{testKey, "key_id", "key_url"}, ECDHES)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	r, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if p.SetRecipient(publickey.New([]byte("key"), "invalid")) == nil {
+		t.Error("Provider.SetRecipient() should fail for invalid public key")
+	}
+	if p.SetRecipient(publickey.New(r, "rsa")) == nil {
+		t.Error("Provider.SetRecipient() should fail for non ECDSA public key")
+	}
+	other, _ := ecdsa.GenerateKey(testKey.Curve, rand.Reader)
+	b, _ := x509.MarshalPKIXPublicKey(&other.PublicKey)
+	if p.SetRecipient(publickey.New(b, "recipient").WithUse("sig")) == nil {
+		t.Error("Provider.SetRecipient() should fail for keys intended for signatures")
+	}
+	if p.SetRecipient(publickey.New(b, "recipient").WithAlgorithm("ECDH-ES+A256KW")) == nil {
+		t.Error("Provider.SetRecipient() should fail for keys intended for another algorithm")
+	}
+	if err := p.SetRecipient(publickey.New(b, "recipient").WithAlgorithm("ECDH-ES").WithUse("enc").WithKeyOps("deriveKey")); err != nil {
+		t.Fatalf("Provider.SetRecipient() failed: %s", err.Error())
+	}
+	if p.recipient.X.Cmp(other.X) != 0 || p.kid != "recipient" || p.jku != "" {
+		t.Error("Provider.SetRecipient() did not set the recipient")
+	}
+}
+
+func TestProvider_CurrentKey(t *testing.T) {
+	p, _ := LoadProvider(Settings{testKey, "key_id", ""}, ECDHES)
+	k := p.CurrentKey()
+	pub, err := x509.ParsePKIXPublicKey(k.GetPublicKey())
+	if err != nil {
+		t.Fatalf("Provider.CurrentKey() returned invalid key: %s", err.Error())
+	}
+	if pub.(*ecdsa.PublicKey).X.Cmp(testKey.X) != 0 || k.GetKeyID() != "key_id" {
+		t.Errorf("Provider.CurrentKey() returned unexpected key")
+	}
+}
@@ -0,0 +1,67 @@
+package eddsa
+
+import (
+	"errors"
+	"io"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"golang.org/x/crypto/sha3"
+)
+
+// This file wraps the constant time Ed448 implementation of circl as specified in RFC 8032 section 5.2 without context (pure Ed448).
+
+const (
+	// ed448SeedSize is the size of Ed448 private keys in seed form as defined in RFC 8032
+	ed448SeedSize = ed448.SeedSize
+	// ed448PublicKeySize is the size of an encoded Ed448 public key
+	ed448PublicKeySize = ed448.PublicKeySize
+	// ed448PrivateKeySize is the size of the private keys stored in settings consisting of the seed followed by the public key
+	ed448PrivateKeySize = ed448.PrivateKeySize
+	// ed448SignatureSize is the size of an Ed448 signature
+	ed448SignatureSize = ed448.SignatureSize
+	// ed448LegacyKeySize is the size of the private keys used by previous versions of this package
+	ed448LegacyKeySize = 144
+)
+
+// errLegacyEd448Key is returned when a private key in the format used by previous versions of this package is loaded
+var errLegacyEd448Key = errors.New("Ed448 private key uses the legacy 144 byte format which is not compatible with RFC 8032: use DeriveEd448KeyFromLegacy to derive a new key")
+
+// ed448NewKeyFromSeed calculates the private key consisting of seed and public key from a seed
+func ed448NewKeyFromSeed(seed []byte) []byte {
+	return ed448.NewKeyFromSeed(seed)
+}
+
+// ed448GenerateKey generates a new private key reading the seed from rand
+func ed448GenerateKey(rand io.Reader) ([]byte, error) {
+	_, priv, err := ed448.GenerateKey(rand)
+	return priv, err
+}
+
+// ed448Sign signs the message using the private key as defined in RFC 8032 section 5.2.6
+func ed448Sign(priv, msg []byte) ([]byte, error) {
+	if len(priv) != ed448PrivateKeySize {
+		return nil, errors.New("private key has wrong size")
+	}
+	return ed448.Sign(priv, msg, ""), nil
+}
+
+// ed448Verify verifies the signature of the message as defined in RFC 8032 section 5.2.7
+func ed448Verify(pub, msg, sig []byte) bool {
+	return ed448.Verify(pub, msg, sig, "")
+}
+
+// DeriveEd448KeyFromLegacy derives a new 57 byte seed from a private key in the 144 byte format used by previous versions of this package.
+// The legacy format is not compatible with RFC 8032 and its scalar can not be preserved, so the result is a different key.
+// CAUTION: The public key of the derived key differs from the legacy one and has to be distributed again. Tokens signed using the legacy key can not be verified with it.
+// Deriving the seed ensures that every instance using the same legacy key ends up with the same new key.
+func DeriveEd448KeyFromLegacy(key []byte) ([]byte, error) {
+	if len(key) != ed448LegacyKeySize {
+		return nil, errors.New("private key has wrong size")
+	}
+	seed := make([]byte, ed448SeedSize)
+	h := sha3.NewShake256()
+	h.Write([]byte("go-jwt legacy Ed448 key")) // nolint:errcheck
+	h.Write(key)                               // nolint:errcheck
+	h.Read(seed)                               // nolint:errcheck
+	return seed, nil
+}
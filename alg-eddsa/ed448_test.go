@@ -0,0 +1,119 @@
+package eddsa
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Could not decode test vector: %s", err.Error())
+	}
+	return b
+}
+
+// Test vectors from RFC 8032 section 7.4
+func TestEd448Vectors(t *testing.T) {
+	tests := []struct {
+		name string
+		seed string
+		pub  string
+		msg  string
+		sig  string
+	}{
+		{"Blank", "6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b", "5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180", "", "533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600"},
+		{"1 octet", "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e", "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480", "03", "26b8f91727bd62897af15e41eb43c377efb9c610d48f2335cb0bd0087810f4352541b143c4b981b7e18f62de8ccdf633fc1bf037ab7cd779805e0dbcc0aae1cbcee1afb2e027df36bc04dcecbf154336c19f0af7e0a6472905e799f1953d2a0ff3348ab21aa4adafd1d234441cf807c03a00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv := ed448NewKeyFromSeed(decodeHex(t, tt.seed))
+			if pub := priv[ed448SeedSize:]; !bytes.Equal(pub, decodeHex(t, tt.pub)) {
+				t.Errorf("ed448NewKeyFromSeed() public key = %x, want %s", pub, tt.pub)
+			}
+			msg := decodeHex(t, tt.msg)
+			sig, err := ed448Sign(priv, msg)
+			if err != nil {
+				t.Fatalf("ed448Sign() failed: %s", err.Error())
+			}
+			if !bytes.Equal(sig, decodeHex(t, tt.sig)) {
+				t.Errorf("ed448Sign() = %x, want %s", sig, tt.sig)
+			}
+			if !ed448Verify(decodeHex(t, tt.pub), msg, decodeHex(t, tt.sig)) {
+				t.Error("ed448Verify() failed for valid signature")
+			}
+			if ed448Verify(decodeHex(t, tt.pub), append(msg, 0x00), decodeHex(t, tt.sig)) {
+				t.Error("ed448Verify() succeeded for modified message")
+			}
+			sig[0] ^= 0x01
+			if ed448Verify(decodeHex(t, tt.pub), msg, sig) {
+				t.Error("ed448Verify() succeeded for modified signature")
+			}
+		})
+	}
+}
+
+func TestEd448Verify(t *testing.T) {
+	sig, err := ed448Sign(ed448PrivateKey, []byte("test"))
+	if err != nil {
+		t.Fatalf("ed448Sign() failed: %s", err.Error())
+	}
+	if !ed448Verify(ed448PublicKey[:], []byte("test"), sig) {
+		t.Error("ed448Verify() failed for valid signature")
+	}
+	if ed448Verify(ed448PublicKey[:], []byte("test"), sig[:ed448SignatureSize-1]) {
+		t.Error("ed448Verify() succeeded for truncated signature")
+	}
+	if ed448Verify(ed448PublicKey[:ed448PublicKeySize-1], []byte("test"), sig) {
+		t.Error("ed448Verify() succeeded for truncated public key")
+	}
+	invalid := append([]byte{}, sig...)
+	for i := ed448PublicKeySize; i < ed448SignatureSize; i++ {
+		invalid[i] = 0xff
+	}
+	if ed448Verify(ed448PublicKey[:], []byte("test"), invalid) {
+		t.Error("ed448Verify() succeeded for S larger than the group order")
+	}
+	if _, err := ed448Sign(ed448Seed[:], []byte("test")); err == nil {
+		t.Error("ed448Sign() succeeded for private key with wrong size")
+	}
+}
+
+func Test_ed448VerifyInvalidPublicKey(t *testing.T) {
+	sig, err := ed448Sign(ed448PrivateKey, []byte("test"))
+	if err != nil {
+		t.Fatalf("ed448Sign() failed: %s", err.Error())
+	}
+	invalid := append([]byte{}, ed448PublicKey[:]...)
+	invalid[ed448PublicKeySize-1] |= 0x01
+	if ed448Verify(invalid, []byte("test"), sig) {
+		t.Error("ed448Verify() succeeded although unused bits of the public key are set")
+	}
+	if ed448Verify(make([]byte, ed448PublicKeySize), []byte("test"), sig) {
+		t.Error("ed448Verify() succeeded for invalid public key")
+	}
+}
+
+func TestDeriveEd448KeyFromLegacy(t *testing.T) {
+	seed, err := DeriveEd448KeyFromLegacy(ed448LegacyKey[:])
+	if err != nil {
+		t.Fatalf("DeriveEd448KeyFromLegacy() failed: %s", err.Error())
+	}
+	if len(seed) != ed448SeedSize {
+		t.Errorf("DeriveEd448KeyFromLegacy() returned seed of length %d", len(seed))
+	}
+	again, _ := DeriveEd448KeyFromLegacy(ed448LegacyKey[:])
+	if !bytes.Equal(seed, again) {
+		t.Error("DeriveEd448KeyFromLegacy() is not deterministic")
+	}
+	if _, err := DeriveEd448KeyFromLegacy(ed448Seed[:]); err == nil {
+		t.Error("DeriveEd448KeyFromLegacy() succeeded for key with wrong size")
+	}
+	if _, err := NewSettings(ed448LegacyKey[:], "key_id"); err != errLegacyEd448Key {
+		t.Errorf("NewSettings() error = %v for legacy key, want %v", err, errLegacyEd448Key)
+	}
+	if _, err := NewSettings(seed, "key_id"); err != nil {
+		t.Errorf("NewSettings() failed for derived key: %s", err.Error())
+	}
+}
@@ -1,37 +1,58 @@
 package eddsa
 
 import (
+	"crypto"
+	"crypto/rand"
 	"errors"
+	"io"
+	"time"
 
 	"github.com/fossoreslp/go-jwt"
-	"github.com/otrv4/ed448"
+	"github.com/fossoreslp/go-jwt/publickey"
 	"golang.org/x/crypto/ed25519"
 )
 
+// Curve identifies one of the Edwards curves used for EdDSA
+type Curve int
+
 const (
 	// Ed25519 is a twisted Edwards curve designed by Daniel J. Bernstein et. al. with a 126-bit security level.
-	Ed25519 = 1
-	// Ed448 is am Edwards curve designed by Mike Hamburg with a 223-bit security level. It's implementation in Go is currently not stable so use with care.
-	Ed448 = 2
+	Ed25519 Curve = 1
+	// Ed448 is an Edwards curve designed by Mike Hamburg with a 223-bit security level. Keys and signatures follow RFC 8032.
+	Ed448 Curve = 2
 )
 
 // Provider is a struct that stores all necessary data to sign and verify EdDSA signatures
 type Provider struct {
-	settings Settings                     // Signature settings
-	c2       map[string]ed25519.PublicKey // Ed25519 key collection
-	c4       map[string][56]byte          // Ed448 key collection
-	curve    int                          // Curve curve
+	settings Settings                      // Signature settings
+	c2       map[string]ed25519.PublicKey  // Ed25519 key collection
+	c4       map[string][]byte             // Ed448 key collection
+	curve    Curve                         // Default curve
+	resolver jwt.KeyResolver               // Source for keys unknown to the provider
+	validity map[string]publickey.Validity // Validity periods of the keys in the collections
 }
 
+var _ jwt.KeyedSignatureProvider = (*Provider)(nil)
+
 // NewProvider creates a new Provider generating the necessary keypairs
-func NewProvider(alg int) (Provider, error) {
+func NewProvider(alg Curve) (Provider, error) {
 	return NewProviderWithKeyURL(alg, "")
 }
 
 // NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
-func NewProviderWithKeyURL(alg int, keyURL string) (Provider, error) {
+func NewProviderWithKeyURL(alg Curve, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(alg, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the keys and the key ID from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(alg Curve, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
 	if alg == Ed25519 {
-		priv, pub, id, err := generateEd25519Keys()
+		priv, pub, id, err := generateEd25519Keys(source)
 		if err != nil {
 			return Provider{}, err
 		}
@@ -39,45 +60,47 @@ func NewProviderWithKeyURL(alg int, keyURL string) (Provider, error) {
 			id: pub,
 			"": pub,
 		}
-		return Provider{Settings{Ed25519, priv, [144]byte{0x0}, id, keyURL}, m, make(map[string][56]byte), alg}, nil
+		return Provider{Settings{Ed25519, priv, nil, id, keyURL, nil}, m, make(map[string][]byte), alg, nil, make(map[string]publickey.Validity)}, nil
 	}
 	if alg == Ed448 {
-		priv, pub, id, err := generateEd448Keys()
+		priv, pub, id, err := generateEd448Keys(source)
 		if err != nil {
 			return Provider{}, err
 		}
-		m := map[string][56]byte{
+		m := map[string][]byte{
 			id: pub,
 			"": pub,
 		}
-		return Provider{Settings{Ed448, nil, priv, id, keyURL}, make(map[string]ed25519.PublicKey), m, alg}, nil
+		return Provider{Settings{Ed448, nil, priv, id, keyURL, nil}, make(map[string]ed25519.PublicKey), m, alg, nil, make(map[string]publickey.Validity)}, nil
 	}
 	return Provider{}, errors.New("invalid algorithm ID")
 }
 
 // LoadProvider returns a Provider using the supplied keypairs
-func LoadProvider(settings Settings, alg int) (Provider, error) {
+func LoadProvider(settings Settings, alg Curve) (Provider, error) {
 	if alg == Ed25519 {
 		if settings.typ != Ed25519 {
 			return Provider{}, errors.New("signature settings are not for Ed25519")
 		}
 		m := map[string]ed25519.PublicKey{
-			settings.kid: settings.ed25519.Public().(ed25519.PublicKey),
-			"":           settings.ed25519.Public().(ed25519.PublicKey),
+			settings.kid: settings.ed25519PublicKey(),
+			"":           settings.ed25519PublicKey(),
 		}
-		return Provider{settings, m, make(map[string][56]byte), alg}, nil
+		return Provider{settings, m, make(map[string][]byte), alg, nil, make(map[string]publickey.Validity)}, nil
 	}
 	if alg == Ed448 {
 		if settings.typ != Ed448 {
 			return Provider{}, errors.New("signature settings are not for Ed448")
 		}
-		var dec [56]byte
-		copy(dec[:], settings.ed448[56:112])
-		m := map[string][56]byte{
-			settings.kid: dec,
-			"":           dec,
+		if len(settings.ed448) != ed448PrivateKeySize {
+			return Provider{}, errors.New("private key has wrong size")
+		}
+		pub := settings.ed448PublicKey()
+		m := map[string][]byte{
+			settings.kid: pub,
+			"":           pub,
 		}
-		return Provider{settings, make(map[string]ed25519.PublicKey), m, alg}, nil
+		return Provider{settings, make(map[string]ed25519.PublicKey), m, alg, nil, make(map[string]publickey.Validity)}, nil
 	}
 	return Provider{}, errors.New("invalid algorithm ID")
 }
@@ -103,13 +126,13 @@ func (p Provider) Header(h *jwt.Header) {
 func (p Provider) Sign(c []byte) ([]byte, error) {
 	switch p.curve {
 	case Ed25519:
+		if p.settings.signer != nil {
+			// EdDSA signs the message itself which is indicated by not using a hash function
+			return p.settings.signer.Sign(rand.Reader, c, crypto.Hash(0))
+		}
 		return ed25519.Sign(p.settings.ed25519, c), nil
 	case Ed448:
-		sig, ok := ed448.NewCurve().Sign(p.settings.ed448, c)
-		if !ok {
-			return nil, errors.New("signing failed")
-		}
-		return sig[:], nil
+		return ed448Sign(p.settings.ed448, c)
 	}
 	return nil, errors.New("unknown curve")
 }
@@ -118,22 +141,50 @@ func (p Provider) Sign(c []byte) ([]byte, error) {
 func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
 	switch h.Crv {
 	case "Ed25519":
+		keysMu.RLock()
 		pub, ok := p.c2[h.Kid]
+		validity := p.validity[validityKey(h.Crv, h.Kid)]
+		keysMu.RUnlock()
 		if !ok {
-			return errors.New("unknown key id")
+			keys, err := p.resolveKeys(data, h, ed25519.PublicKeySize)
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if ed25519.Verify(ed25519.PublicKey(k), data, sig) {
+					return nil
+				}
+			}
+			return errors.New("signature invalid")
+		}
+		if err := validity.Check(time.Now()); err != nil {
+			return err
 		}
 		if ed25519.Verify(pub, data, sig) {
 			return nil
 		}
 		return errors.New("signature invalid")
 	case "Ed448":
+		keysMu.RLock()
 		pub, ok := p.c4[h.Kid]
+		validity := p.validity[validityKey(h.Crv, h.Kid)]
+		keysMu.RUnlock()
 		if !ok {
-			return errors.New("unknown key id")
+			keys, err := p.resolveKeys(data, h, ed448PublicKeySize)
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if ed448Verify(k, data, sig) {
+					return nil
+				}
+			}
+			return errors.New("signature invalid")
+		}
+		if err := validity.Check(time.Now()); err != nil {
+			return err
 		}
-		var signature [112]byte
-		copy(signature[:], sig)
-		if ed448.NewCurve().Verify(signature, data, pub) {
+		if ed448Verify(pub, data, sig) {
 			return nil
 		}
 		return errors.New("signature invalid")
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
 	"golang.org/x/crypto/ed25519"
 )
 
@@ -65,7 +66,7 @@ func TestNewProviderWithKeyURL(t *testing.T) {
 func TestLoadProvider(t *testing.T) {
 	type args struct {
 		settings Settings
-		curve    int
+		curve    Curve
 	}
 	tests := []struct {
 		name    string
@@ -75,8 +76,9 @@ func TestLoadProvider(t *testing.T) {
 	}{
 		{"Ed25519 invalid settings type", args{Settings{}, Ed25519}, Provider{}, true},
 		{"Ed448 invalid settings type", args{Settings{}, Ed448}, Provider{}, true},
-		{"Ed25519", args{Settings{typ: Ed25519, ed25519: ed25519PrivateKey[:], kid: "key_id"}, Ed25519}, Provider{Settings{typ: Ed25519, ed25519: ed25519PrivateKey[:], kid: "key_id"}, map[string]ed25519.PublicKey{"": ed25519.PublicKey(ed25519PublicKey[:]), "key_id": ed25519.PublicKey(ed25519PublicKey[:])}, map[string][56]byte{}, Ed25519}, false},
-		{"Ed448", args{Settings{typ: Ed448, ed448: ed448PrivateKey, kid: "key_id"}, Ed448}, Provider{Settings{typ: Ed448, ed448: ed448PrivateKey, kid: "key_id"}, map[string]ed25519.PublicKey{}, map[string][56]byte{"": ed448PublicKey, "key_id": ed448PublicKey}, Ed448}, false},
+		{"Ed25519", args{Settings{typ: Ed25519, ed25519: ed25519PrivateKey[:], kid: "key_id"}, Ed25519}, Provider{Settings{typ: Ed25519, ed25519: ed25519PrivateKey[:], kid: "key_id"}, map[string]ed25519.PublicKey{"": ed25519.PublicKey(ed25519PublicKey[:]), "key_id": ed25519.PublicKey(ed25519PublicKey[:])}, map[string][]byte{}, Ed25519, nil, make(map[string]publickey.Validity)}, false},
+		{"Ed448", args{Settings{typ: Ed448, ed448: ed448PrivateKey, kid: "key_id"}, Ed448}, Provider{Settings{typ: Ed448, ed448: ed448PrivateKey, kid: "key_id"}, map[string]ed25519.PublicKey{}, map[string][]byte{"": ed448PublicKey[:], "key_id": ed448PublicKey[:]}, Ed448, nil, make(map[string]publickey.Validity)}, false},
+		{"Ed448 invalid private key", args{Settings{typ: Ed448, ed448: ed448Seed[:], kid: "key_id"}, Ed448}, Provider{}, true},
 		{"Unknown", args{Settings{}, 12}, Provider{}, true},
 	}
 	for _, tt := range tests {
@@ -95,7 +97,7 @@ func TestLoadProvider(t *testing.T) {
 
 func TestProvider_Header(t *testing.T) {
 	h := jwt.Header{Typ: "JWT"}
-	Provider{Settings{kid: "key_id", jku: "key_url"}, nil, nil, Ed25519}.Header(&h)
+	Provider{Settings{kid: "key_id", jku: "key_url"}, nil, nil, Ed25519, nil, make(map[string]publickey.Validity)}.Header(&h)
 	if h.Alg != "EdDSA" {
 		t.Errorf("Provider.Header() should set Alg to \"EdDSA\" but instead it is %q", h.Alg)
 	}
@@ -110,7 +112,7 @@ func TestProvider_Header(t *testing.T) {
 	}
 
 	h = jwt.Header{Typ: "JWT"}
-	Provider{Settings{kid: "key_id", jku: "key_url"}, nil, nil, Ed448}.Header(&h)
+	Provider{Settings{kid: "key_id", jku: "key_url"}, nil, nil, Ed448, nil, make(map[string]publickey.Validity)}.Header(&h)
 	if h.Alg != "EdDSA" {
 		t.Errorf("Provider.Header() should set Alg to \"EdDSA\" but instead it is %q", h.Alg)
 	}
@@ -126,18 +128,18 @@ func TestProvider_Header(t *testing.T) {
 }
 
 func TestProvider_Sign(t *testing.T) {
-	p448invalid := Provider{Settings{typ: Ed448, ed448: [144]byte{33, 147, 45, 233, 236, 0, 92, 221, 111, 132, 50, 172, 83, 220, 197, 251, 46, 83, 98, 113, 173, 250, 128, 15, 127, 85, 149, 81, 253, 149, 84, 233, 76, 193, 173, 2, 193, 133, 5, 110, 215, 167, 6, 246, 145, 232, 50, 246, 120, 203, 191, 73, 226, 187, 134, 244, 139, 144, 55, 7, 217, 55, 48, 50, 59, 69, 52, 245, 17, 88, 150, 144, 192, 86, 215, 194, 107, 27, 105, 18, 204, 119, 213, 231, 70, 116, 232, 126, 57, 115, 221, 8, 152, 154, 20, 204, 37, 255, 227, 237, 136, 58, 151, 207, 108, 214, 113, 87, 22, 144, 227, 121, 79, 213, 114, 45, 207, 192, 160, 60, 193, 149, 53, 220, 34, 103, 37, 25, 90, 18, 60, 190, 209, 191, 147, 242, 127, 173, 86, 221, 233, 192, 44, 167}}, nil, nil, Ed448}
+	p448invalid := Provider{Settings{typ: Ed448, ed448: ed448Seed[:]}, nil, nil, Ed448, nil, make(map[string]publickey.Validity)}
 	if _, err := p448invalid.Sign(nil); err == nil {
 		t.Error("Provider.Sign() should fail because Ed448 private key is invalid")
 	}
-	punknown := Provider{Settings{}, nil, nil, 12}
+	punknown := Provider{Settings{}, nil, nil, 12, nil, make(map[string]publickey.Validity)}
 	if _, err := punknown.Sign(nil); err == nil {
 		t.Error("Provider.Sign() should fail because default curve is unknown")
 	}
 }
 
 func TestProvider_Verify(t *testing.T) {
-	p25519 := Provider{Settings{kid: "test"}, map[string]ed25519.PublicKey{"test": ed25519.PublicKey{0x9a, 0xe1, 0x6f, 0x74, 0x0d, 0xc1, 0x49, 0x0a, 0xa7, 0x36, 0x9f, 0xb5, 0xce, 0x09, 0xe6, 0x07, 0xa3, 0xd9, 0x78, 0xd4, 0x8e, 0xa2, 0x87, 0x19, 0x1e, 0x92, 0x95, 0x5b, 0xa2, 0x9d, 0x74, 0xb2}}, nil, Ed25519}
+	p25519 := Provider{Settings{kid: "test"}, map[string]ed25519.PublicKey{"test": ed25519.PublicKey{0x9a, 0xe1, 0x6f, 0x74, 0x0d, 0xc1, 0x49, 0x0a, 0xa7, 0x36, 0x9f, 0xb5, 0xce, 0x09, 0xe6, 0x07, 0xa3, 0xd9, 0x78, 0xd4, 0x8e, 0xa2, 0x87, 0x19, 0x1e, 0x92, 0x95, 0x5b, 0xa2, 0x9d, 0x74, 0xb2}}, nil, Ed25519, nil, make(map[string]publickey.Validity)}
 	// Unknown public key
 	if p25519.Verify([]byte("test"), []byte("signature"), jwt.Header{Crv: "Ed25519"}) == nil {
 		t.Error("Provider.Verify() should fail for unknown public key")
@@ -151,12 +153,12 @@ func TestProvider_Verify(t *testing.T) {
 	if p25519.Verify([]byte("test"), []byte("signature"), jwt.Header{Crv: "Ed25519"}) == nil {
 		t.Error("Provider.Verify() should fail for invalid signature")
 	}
-	p448 := Provider{Settings{kid: "test"}, nil, map[string][56]byte{"test": [56]byte{0x65, 0x0d, 0x46, 0xb1, 0x0c, 0x4f, 0xd2, 0x2e, 0xd9, 0x4c, 0x97, 0x34, 0x49, 0x88, 0x16, 0xd1, 0xc8, 0x6a, 0x34, 0xa7, 0xae, 0x4d, 0xcb, 0x81, 0x4c, 0xd9, 0x45, 0xfb, 0x31, 0x4d, 0xe2, 0xaa, 0x04, 0xde, 0x17, 0xee, 0xf5, 0xae, 0x27, 0x29, 0xa0, 0x33, 0x25, 0x98, 0x27, 0x3f, 0xce, 0x9d, 0xe1, 0x4c, 0xf3, 0x24, 0x6b, 0x89, 0x4b, 0x60}}, Ed448}
+	p448 := Provider{Settings{kid: "test"}, nil, map[string][]byte{"test": ed448PublicKey[:]}, Ed448, nil, make(map[string]publickey.Validity)}
 	// Unknown public key
 	if p448.Verify([]byte("test"), []byte("signature"), jwt.Header{Crv: "Ed448"}) == nil {
 		t.Error("Provider.Verify() should fail for unknown public key")
 	}
-	p448.c4[""] = [56]byte{0x65, 0x0d, 0x46, 0xb1, 0x0c, 0x4f, 0xd2, 0x2e, 0xd9, 0x4c, 0x97, 0x34, 0x49, 0x88, 0x16, 0xd1, 0xc8, 0x6a, 0x34, 0xa7, 0xae, 0x4d, 0xcb, 0x81, 0x4c, 0xd9, 0x45, 0xfb, 0x31, 0x4d, 0xe2, 0xaa, 0x04, 0xde, 0x17, 0xee, 0xf5, 0xae, 0x27, 0x29, 0xa0, 0x33, 0x25, 0x98, 0x27, 0x3f, 0xce, 0x9d, 0xe1, 0x4c, 0xf3, 0x24, 0x6b, 0x89, 0x4b, 0x60}
+	p448.c4[""] = ed448PublicKey[:]
 	// Invalid signature with public key "test"
 	if p448.Verify([]byte("test"), []byte("signature"), jwt.Header{Crv: "Ed448", Kid: "test"}) == nil {
 		t.Error("Provider.Verify() should fail for invalid signature")
@@ -170,3 +172,23 @@ func TestProvider_Verify(t *testing.T) {
 		t.Error("Provider.Verify() should fail because specified curve is unknown")
 	}
 }
+
+func TestNewProviderWithRandom(t *testing.T) {
+	for _, curve := range []Curve{Ed25519, Ed448} {
+		seed := bytes.Repeat([]byte{0x42}, 57+16)
+		a, err := NewProviderWithRandom(curve, "key_url", bytes.NewReader(seed))
+		if err != nil {
+			t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+		}
+		b, err := NewProviderWithRandom(curve, "key_url", bytes.NewReader(seed))
+		if err != nil {
+			t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+		}
+		if !reflect.DeepEqual(a.CurrentKey(), b.CurrentKey()) {
+			t.Errorf("NewProviderWithRandom() generated different keys from the same random data: %v and %v", a.CurrentKey(), b.CurrentKey())
+		}
+		if _, err := NewProviderWithRandom(curve, "key_url", bytes.NewReader(seed[:20])); err == nil {
+			t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package eddsa
 
 import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io"
 	"testing"
 
 	jwt "github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/providertest"
+	"golang.org/x/crypto/ed25519"
 )
 
 func TestEd25519(t *testing.T) {
@@ -11,7 +17,9 @@ func TestEd25519(t *testing.T) {
 	if err != nil {
 		t.Errorf("Could not initialize provider: %s", err.Error())
 	}
-	jwt.SetSignatureProvider("EdDSA", p)
+	if err := jwt.SetSignatureProvider("EdDSA", p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm("EdDSA") // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -33,7 +41,9 @@ func TestEd448(t *testing.T) {
 	if err != nil {
 		t.Errorf("Could not initialize provider: %s", err.Error())
 	}
-	jwt.SetSignatureProvider("EdDSA", p)
+	if err := jwt.SetSignatureProvider("EdDSA", p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm("EdDSA") // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -49,3 +59,64 @@ func TestEd448(t *testing.T) {
 		t.Errorf("Decoded JWT could not be validated: %s", dec.ValidationError().Error())
 	}
 }
+
+func TestKeyResolver(t *testing.T) {
+	signer, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.KeyResolver(t, &signer, &verifier, jwt.Header{Alg: "EdDSA", Crv: "Ed25519"})
+}
+
+func TestSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	s, err := NewSettingsFromSigner(signer, "key_id")
+	if err != nil {
+		t.Fatalf("NewSettingsFromSigner() failed: %s", err.Error())
+	}
+	p, err := LoadProvider(s, Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	if _, err := p.Settings().Export(); err == nil {
+		t.Error("Settings.Export() should fail when using a signer")
+	}
+	if _, err := p.Settings().ExportPEM(); err == nil {
+		t.Error("Settings.ExportPEM() should fail when using a signer")
+	}
+	local, err := LoadProvider(Settings{Ed25519, key, nil, "key_id", "", nil}, Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.Signer(t, &p, &local, jwt.Header{Alg: "EdDSA", Crv: "Ed25519"})
+	if signer.calls != 1 {
+		t.Errorf("Signer should have been called once but was called %d times", signer.calls)
+	}
+
+	signer.sign = func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+		return nil, errors.New("signer unavailable")
+	}
+	if _, err := p.Sign([]byte("test")); err == nil {
+		t.Error("Provider.Sign() should fail when the signer fails")
+	}
+}
+
+func TestKeyValidity(t *testing.T) {
+	signer, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.KeyValidity(t, &signer, &verifier, jwt.Header{Alg: "EdDSA", Crv: "Ed25519"})
+}
@@ -2,78 +2,141 @@ package eddsa
 
 import (
 	"errors"
+	"io"
+	"sync"
+	"time"
 
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
 	"github.com/fossoreslp/go-jwt/publickey"
-	"github.com/fossoreslp/go-uuid-v4"
-	"github.com/otrv4/ed448"
 	"golang.org/x/crypto/ed25519"
 )
 
+// keysMu guards the key collections of all providers so keys can be managed while tokens are being verified
+var keysMu sync.RWMutex
+
 // AddPublicKey adds a public key for verification
 func (p *Provider) AddPublicKey(key publickey.PublicKey) error {
+	if err := key.CheckMetadata("EdDSA", "OKP", "sig", "verify"); err != nil {
+		return err
+	}
+	validity := key.GetValidity()
+	if validity.Expired(time.Now()) {
+		return errors.New("key expired")
+	}
 	id := key.GetKeyID()
 	enc := key.GetPublicKey()
+	keysMu.Lock()
+	defer keysMu.Unlock()
 	if len(enc) == ed25519.PublicKeySize {
 		if _, ok := p.c2[id]; ok {
 			return errors.New("key ID already exists")
 		}
 		p.c2[id] = ed25519.PublicKey(enc)
+		p.setValidity("Ed25519", id, validity)
 		return nil
 	}
-	if len(enc) == 56 {
+	if len(enc) == ed448PublicKeySize {
 		if _, ok := p.c4[id]; ok {
 			return errors.New("key ID already exists")
 		}
-		var pub [56]byte
-		copy(pub[:], enc)
-		p.c4[id] = pub
+		p.c4[id] = enc
+		p.setValidity("Ed448", id, validity)
 		return nil
 	}
 	return errors.New("key has invalid length")
 }
 
+// SetKeyResolver sets a resolver that is consulted for keys when the key ID of a token is unknown to the provider
+func (p *Provider) SetKeyResolver(r jwt.KeyResolver) {
+	p.resolver = r
+}
+
+// resolveKeys retrieves the keys of the specified size that may have been used to sign a token from the key resolver
+func (p Provider) resolveKeys(data []byte, h jwt.Header, size int) ([][]byte, error) {
+	if p.resolver == nil {
+		return nil, errors.New("unknown key id")
+	}
+	c, err := jwt.ContentFromSigningInput(data)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := p.resolver.Resolve(h, c)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		// A resolver may return keys for the other curve which are skipped
+		// Keys whose metadata does not permit verifying the token are skipped as well
+		if len(k.GetPublicKey()) == size && k.CheckUsage(h.Alg, "OKP", "sig", "verify") == nil {
+			parsed = append(parsed, k.GetPublicKey())
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("unknown key id")
+	}
+	return parsed, nil
+}
+
 // RemovePublicKey removes a public key by it's key ID from the verification set
 func (p *Provider) RemovePublicKey(keyid string) {
 	if keyid == p.settings.kid {
 		return
 	}
+	keysMu.Lock()
 	delete(p.c2, keyid)
 	delete(p.c4, keyid)
+	delete(p.validity, validityKey("Ed25519", keyid))
+	delete(p.validity, validityKey("Ed448", keyid))
+	keysMu.Unlock()
+}
+
+// validityKey returns the key of the validity period for the curve as used in the header and the key ID since a key ID may be used for both curves
+func validityKey(crv, id string) string {
+	return crv + " " + id
+}
+
+// setValidity stores the validity period of a key unless it is unrestricted. The caller must hold keysMu.
+func (p *Provider) setValidity(crv, id string, v publickey.Validity) {
+	if !v.IsZero() {
+		p.validity[validityKey(crv, id)] = v
+	}
 }
 
 // CurrentKey returns the public key belonging to the private key used for signing
 func (p Provider) CurrentKey() publickey.PublicKey {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
 	if p.curve == Ed25519 {
 		return publickey.New(p.c2[p.settings.kid], p.settings.kid)
 	}
 	if p.curve == Ed448 {
-		k := p.c4[p.settings.kid]
-		return publickey.New(k[:], p.settings.kid)
+		return publickey.New(p.c4[p.settings.kid], p.settings.kid)
 	}
 	return publickey.PublicKey{}
 }
 
-func generateEd25519Keys() (ed25519.PrivateKey, ed25519.PublicKey, string, error) {
-	pub, priv, err := ed25519.GenerateKey(nil)
+func generateEd25519Keys(random io.Reader) (ed25519.PrivateKey, ed25519.PublicKey, string, error) {
+	pub, priv, err := ed25519.GenerateKey(random)
 	if err != nil {
 		return nil, nil, "", err
 	}
-	id, err := uuid.NewString()
+	id, err := keyid.New(random)
 	if err != nil {
 		return nil, nil, "", err
 	}
 	return priv, pub, id, nil
 }
 
-func generateEd448Keys() ([144]byte, [56]byte, string, error) {
-	curve := ed448.NewCurve()
-	priv, pub, ok := curve.GenerateKeys()
-	if !ok {
-		return [144]byte{0x0}, [56]byte{0x0}, "", errors.New("failed to generate Ed448 keys")
+func generateEd448Keys(random io.Reader) ([]byte, []byte, string, error) {
+	priv, err := ed448GenerateKey(random)
+	if err != nil {
+		return nil, nil, "", err
 	}
-	id, err := uuid.NewString()
+	id, err := keyid.New(random)
 	if err != nil {
-		return [144]byte{0x0}, [56]byte{0x0}, "", err
+		return nil, nil, "", err
 	}
-	return priv, pub, id, nil
+	return priv, priv[ed448SeedSize:], id, nil
 }
@@ -13,13 +13,13 @@ import (
 func Test_generateEd25519Keys(t *testing.T) {
 	random := rand.Reader
 	rand.Reader = bytes.NewReader(nil)
-	_, _, _, err := generateEd25519Keys()
+	_, _, _, err := generateEd25519Keys(rand.Reader)
 	if err == nil {
 		t.Error("Generating new Ed25519 keys did not fail with invalid random generator")
 	}
 	b := [32]byte{0x00}
 	rand.Reader = bytes.NewReader(b[:])
-	_, _, _, err = generateEd25519Keys()
+	_, _, _, err = generateEd25519Keys(rand.Reader)
 	if err == nil {
 		t.Error("Generating new UUID for key did not fail with empty random generator")
 	}
@@ -29,13 +29,13 @@ func Test_generateEd25519Keys(t *testing.T) {
 func Test_generateEd448Keys(t *testing.T) {
 	random := rand.Reader
 	rand.Reader = bytes.NewReader(nil)
-	_, _, _, err := generateEd448Keys()
+	_, _, _, err := generateEd448Keys(rand.Reader)
 	if err == nil {
 		t.Error("Generating new Ed448 keys did not fail with invalid random generator")
 	}
 	b := [32]byte{0x00}
 	rand.Reader = bytes.NewReader(b[:])
-	_, _, _, err = generateEd448Keys()
+	_, _, _, err = generateEd448Keys(rand.Reader)
 	if err == nil {
 		t.Error("Generating new UUID for key did not fail with empty random generator")
 	}
@@ -44,7 +44,7 @@ func Test_generateEd448Keys(t *testing.T) {
 
 var (
 	ed25519PublicKey = [32]byte{0x4a, 0x84, 0x0b, 0x8b, 0xcf, 0x8d, 0xac, 0xe4, 0xfe, 0x25, 0x86, 0x1d, 0xe2, 0x96, 0xfe, 0x0a, 0xd3, 0x7c, 0xdd, 0x9d, 0xb2, 0xf6, 0xd6, 0x28, 0x85, 0xb3, 0x86, 0x6d, 0x78, 0xe9, 0xb7, 0x9f}
-	ed448PublicKey   = [56]byte{0x00}
+	ed448PublicKey   = [57]byte{0x5f, 0xd7, 0x44, 0x9b, 0x59, 0xb4, 0x61, 0xfd, 0x2c, 0xe7, 0x87, 0xec, 0x61, 0x6a, 0xd4, 0x6a, 0x1d, 0xa1, 0x34, 0x24, 0x85, 0xa7, 0x0e, 0x1f, 0x8a, 0x0e, 0xa7, 0x5d, 0x80, 0xe9, 0x67, 0x78, 0xed, 0xf1, 0x24, 0x76, 0x9b, 0x46, 0xc7, 0x06, 0x1b, 0xd6, 0x78, 0x3d, 0xf1, 0xe5, 0x0f, 0x6c, 0xd1, 0xfa, 0x1a, 0xbe, 0xaf, 0xe8, 0x25, 0x61, 0x80}
 )
 
 func TestProvider_AddPublicKey(t *testing.T) {
@@ -54,11 +54,13 @@ func TestProvider_AddPublicKey(t *testing.T) {
 		key     publickey.PublicKey
 		wantErr bool
 	}{
-		{"Ed25519", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][56]byte)}, publickey.New(ed25519PublicKey[:], "key_id"), false},
-		{"Ed25519 already exists", &Provider{c2: map[string]ed25519.PublicKey{"key_id": ed25519.PublicKey(ed25519PublicKey[:])}, c4: make(map[string][56]byte)}, publickey.New(ed25519PublicKey[:], "key_id"), true},
-		{"Ed448", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][56]byte)}, publickey.New(ed448PublicKey[:], "key_id"), false},
-		{"Ed448 already exists", &Provider{c2: make(map[string]ed25519.PublicKey), c4: map[string][56]byte{"key_id": ed448PublicKey}}, publickey.New(ed448PublicKey[:], "key_id"), true},
-		{"Invalid public key length", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][56]byte)}, publickey.New(nil, "key_id"), true},
+		{"Ed25519", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, publickey.New(ed25519PublicKey[:], "key_id"), false},
+		{"Ed25519 already exists", &Provider{c2: map[string]ed25519.PublicKey{"key_id": ed25519.PublicKey(ed25519PublicKey[:])}, c4: make(map[string][]byte)}, publickey.New(ed25519PublicKey[:], "key_id"), true},
+		{"Ed448", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, publickey.New(ed448PublicKey[:], "key_id"), false},
+		{"Ed448 already exists", &Provider{c2: make(map[string]ed25519.PublicKey), c4: map[string][]byte{"key_id": ed448PublicKey[:]}}, publickey.New(ed448PublicKey[:], "key_id"), true},
+		{"Invalid public key length", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, publickey.New(nil, "key_id"), true},
+		{"Matching metadata", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, publickey.New(ed25519PublicKey[:], "key_id").WithAlgorithm("EdDSA").WithKeyType("OKP"), false},
+		{"Verification not permitted", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, publickey.New(ed25519PublicKey[:], "key_id").WithKeyOps("encrypt"), true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -75,8 +77,8 @@ func TestProvider_RemovePublicKey(t *testing.T) {
 		p     *Provider
 		keyid string
 	}{
-		{"Normal", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][56]byte)}, "key_id"},
-		{"Same as signing key", &Provider{settings: Settings{kid: "key_id"}, c2: make(map[string]ed25519.PublicKey), c4: make(map[string][56]byte)}, "key_id"},
+		{"Normal", &Provider{c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, "key_id"},
+		{"Same as signing key", &Provider{settings: Settings{kid: "key_id"}, c2: make(map[string]ed25519.PublicKey), c4: make(map[string][]byte)}, "key_id"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -92,7 +94,7 @@ func TestProvider_CurrentKey(t *testing.T) {
 		want publickey.PublicKey
 	}{
 		{"Ed25519", Provider{curve: Ed25519, settings: Settings{kid: "key_id"}, c2: map[string]ed25519.PublicKey{"key_id": ed25519.PublicKey(ed25519PublicKey[:])}}, publickey.New(ed25519PublicKey[:], "key_id")},
-		{"Ed448", Provider{curve: Ed448, settings: Settings{kid: "key_id"}, c4: map[string][56]byte{"key_id": ed448PublicKey}}, publickey.New(ed448PublicKey[:], "key_id")},
+		{"Ed448", Provider{curve: Ed448, settings: Settings{kid: "key_id"}, c4: map[string][]byte{"key_id": ed448PublicKey[:]}}, publickey.New(ed448PublicKey[:], "key_id")},
 		{"Invalid curve", Provider{curve: 12}, publickey.PublicKey{}},
 	}
 	for _, tt := range tests {
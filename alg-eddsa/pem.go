@@ -0,0 +1,191 @@
+package eddsa
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+	"github.com/fossoreslp/go-jwt/publickey"
+	"golang.org/x/crypto/ed25519"
+)
+
+// keyDir lists the PEM block types used for keys by this package.
+// Public keys in the legacy Ed448 format cannot verify RFC 8032 signatures and are skipped as they have to be replaced along with the private key.
+var keyDir = pemutil.KeyDir{Private: []string{"PRIVATE KEY", "ED448 PRIVATE KEY"}, Public: []string{"PUBLIC KEY"}, Ignored: []string{"ED448 PUBLIC KEY"}, PublicKey: publicKeyFromBlock}
+
+// Object identifiers for Ed25519 and Ed448 keys as defined in RFC 8410
+var (
+	oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+	oidEd448   = asn1.ObjectIdentifier{1, 3, 101, 113}
+)
+
+type pkcs8PrivateKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type pkixPublicKey struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// NewSettingsFromPEM creates new signature settings from a PEM-encoded private key.
+// Keys have to be encoded as PKCS8. Ed448 keys in the 144 byte format using the block type ED448 PRIVATE KEY written by previous versions are rejected.
+// Key ID and key URL are read from the Key-ID and Key-URL headers of the PEM block.
+func NewSettingsFromPEM(key []byte) (Settings, error) {
+	b, err := pemutil.Decode(key)
+	if err != nil {
+		return Settings{}, err
+	}
+	return settingsFromBlock(b)
+}
+
+func settingsFromBlock(b pemutil.Block) (Settings, error) {
+	switch b.Type {
+	case "PRIVATE KEY":
+		var k pkcs8PrivateKey
+		if rest, err := asn1.Unmarshal(b.Bytes, &k); err != nil || len(rest) > 0 {
+			return Settings{}, errors.New("could not decode PKCS8 private key")
+		}
+		var size int
+		switch {
+		case k.Algorithm.Algorithm.Equal(oidEd25519):
+			size = ed25519.SeedSize
+		case k.Algorithm.Algorithm.Equal(oidEd448):
+			size = ed448SeedSize
+		default:
+			return Settings{}, errors.New("PKCS8 does not contain an EdDSA private key")
+		}
+		var seed []byte
+		if rest, err := asn1.Unmarshal(k.PrivateKey, &seed); err != nil || len(rest) > 0 || len(seed) != size {
+			return Settings{}, errors.New("could not decode EdDSA private key")
+		}
+		return NewSettingsWithKeyURL(seed, b.KeyID, b.KeyURL)
+	case "ED448 PRIVATE KEY":
+		if len(b.Bytes) != ed448LegacyKeySize {
+			return Settings{}, errors.New("private key has wrong size")
+		}
+		return Settings{}, errLegacyEd448Key
+	}
+	return Settings{}, errors.New("PEM block does not contain a private key")
+}
+
+// Export returns the private key so it can be loaded using NewSettings.
+// It fails for settings using a signer as the private key is not available.
+func (s Settings) Export() ([]byte, error) {
+	if s.signer != nil {
+		return nil, errors.New("settings do not contain a private key")
+	}
+	switch s.typ {
+	case Ed25519:
+		return s.ed25519.Seed(), nil
+	case Ed448:
+		if len(s.ed448) == ed448PrivateKeySize {
+			return append([]byte(nil), s.ed448[:ed448SeedSize]...), nil
+		}
+	}
+	return nil, errors.New("settings do not contain a private key")
+}
+
+// ExportPEM returns the PEM-encoded private key including key ID and key URL so it can be loaded using NewSettingsFromPEM
+func (s Settings) ExportPEM() ([]byte, error) {
+	if s.signer != nil {
+		return nil, errors.New("settings do not contain a private key")
+	}
+	var oid asn1.ObjectIdentifier
+	switch s.typ {
+	case Ed25519:
+		oid = oidEd25519
+	case Ed448:
+		oid = oidEd448
+	default:
+		return nil, errors.New("settings do not contain a private key")
+	}
+	raw, err := s.Export()
+	if err != nil {
+		return nil, err
+	}
+	seed, err := asn1.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	key, err := asn1.Marshal(pkcs8PrivateKey{0, pkix.AlgorithmIdentifier{Algorithm: oid}, seed})
+	if err != nil {
+		return nil, err
+	}
+	return pemutil.Encode("PRIVATE KEY", key, s.kid, s.jku), nil
+}
+
+// Settings returns the signature settings of the provider which can be used to export the private key
+func (p Provider) Settings() Settings {
+	return p.settings
+}
+
+// EncodePublicKeyPEM returns the PKIX- and PEM-encoded public key including the key ID
+func EncodePublicKeyPEM(key publickey.PublicKey) ([]byte, error) {
+	enc := key.GetPublicKey()
+	var oid asn1.ObjectIdentifier
+	switch len(enc) {
+	case ed25519.PublicKeySize:
+		oid = oidEd25519
+	case ed448PublicKeySize:
+		oid = oidEd448
+	default:
+		return nil, errors.New("key has invalid length")
+	}
+	der, err := asn1.Marshal(pkixPublicKey{pkix.AlgorithmIdentifier{Algorithm: oid}, asn1.BitString{Bytes: enc, BitLength: 8 * len(enc)}})
+	if err != nil {
+		return nil, err
+	}
+	return pemutil.Encode("PUBLIC KEY", der, key.GetKeyID(), ""), nil
+}
+
+// DecodePublicKeyPEM decodes a PEM-encoded public key reading the key ID from the Key-ID header
+func DecodePublicKeyPEM(key []byte) (publickey.PublicKey, error) {
+	b, err := pemutil.Decode(key)
+	if err != nil {
+		return publickey.PublicKey{}, err
+	}
+	return publicKeyFromBlock(b)
+}
+
+func publicKeyFromBlock(b pemutil.Block) (publickey.PublicKey, error) {
+	switch b.Type {
+	case "PUBLIC KEY":
+		var k pkixPublicKey
+		if rest, err := asn1.Unmarshal(b.Bytes, &k); err != nil || len(rest) > 0 {
+			return publickey.PublicKey{}, errors.New("could not decode public key")
+		}
+		if k.Algorithm.Algorithm.Equal(oidEd25519) && len(k.PublicKey.Bytes) == ed25519.PublicKeySize {
+			return publickey.New(k.PublicKey.Bytes, b.KeyID), nil
+		}
+		if k.Algorithm.Algorithm.Equal(oidEd448) && len(k.PublicKey.Bytes) == ed448PublicKeySize {
+			return publickey.New(k.PublicKey.Bytes, b.KeyID), nil
+		}
+		return publickey.PublicKey{}, errors.New("public key is not an EdDSA public key")
+	case "ED448 PUBLIC KEY":
+		return publickey.PublicKey{}, errors.New("Ed448 public keys in the legacy format are not compatible with RFC 8032")
+	}
+	return publickey.PublicKey{}, errors.New("PEM block does not contain a public key")
+}
+
+// LoadProviderFromDir returns a Provider using the PEM-encoded keys in the files with the extension .pem in dir.
+// The directory has to contain exactly one private key which is used for signing. All public keys are added for verification.
+// Key IDs are read from the Key-ID header and default to the file name without extension.
+func LoadProviderFromDir(dir string, alg Curve) (Provider, error) {
+	var p Provider
+	err := keyDir.Load(dir, func(b pemutil.Block) (pemutil.KeyAdder, error) {
+		s, err := settingsFromBlock(b)
+		if err != nil {
+			return nil, err
+		}
+		p, err = LoadProvider(s, alg)
+		return &p, err
+	})
+	if err != nil {
+		return Provider{}, err
+	}
+	return p, nil
+}
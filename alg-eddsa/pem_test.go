@@ -0,0 +1,178 @@
+package eddsa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestSettings_ExportPEM(t *testing.T) {
+	p, err := NewProviderWithKeyURL(Ed25519, "key_url")
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	enc, err := p.Settings().ExportPEM()
+	if err != nil {
+		t.Fatalf("Settings.ExportPEM() failed: %s", err.Error())
+	}
+	s, err := NewSettingsFromPEM(enc)
+	if err != nil {
+		t.Fatalf("NewSettingsFromPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettingsFromPEM() = %+v, want %+v", s, p.Settings())
+	}
+	der, err := p.Settings().Export()
+	if err != nil {
+		t.Fatalf("Settings.Export() failed: %s", err.Error())
+	}
+	s, err = NewSettingsWithKeyURL(der, p.settings.kid, "key_url")
+	if err != nil {
+		t.Fatalf("NewSettings() failed for exported key: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettings() = %+v, want %+v", s, p.Settings())
+	}
+}
+
+func TestNewSettingsFromPEM(t *testing.T) {
+	if _, err := NewSettingsFromPEM([]byte("no PEM block")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail when there is no PEM block")
+	}
+	if _, err := NewSettingsFromPEM(pemutil.Encode("CERTIFICATE", []byte("test"), "", "")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail for unsupported block type")
+	}
+	if _, err := NewSettingsFromPEM(pemutil.Encode("PRIVATE KEY", []byte("test"), "", "")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail for invalid key")
+	}
+	if _, err := NewSettingsFromPEM(pemutil.Encode("ED448 PRIVATE KEY", []byte("test"), "", "")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail for invalid legacy key")
+	}
+	if _, err := NewSettingsFromPEM(pemutil.Encode("ED448 PRIVATE KEY", ed448LegacyKey[:], "key_id", "")); err != errLegacyEd448Key {
+		t.Errorf("NewSettingsFromPEM() error = %v for legacy key, want %v", err, errLegacyEd448Key)
+	}
+	if _, err := DecodePublicKeyPEM(pemutil.Encode("ED448 PUBLIC KEY", ed448PublicKey[:56], "", "")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail for legacy key")
+	}
+}
+
+func TestEncodePublicKeyPEM(t *testing.T) {
+	p, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	enc, err := EncodePublicKeyPEM(p.CurrentKey())
+	if err != nil {
+		t.Fatalf("EncodePublicKeyPEM() failed: %s", err.Error())
+	}
+	key, err := DecodePublicKeyPEM(enc)
+	if err != nil {
+		t.Fatalf("DecodePublicKeyPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(key, p.CurrentKey()) {
+		t.Errorf("DecodePublicKeyPEM() = %v, want %v", key, p.CurrentKey())
+	}
+	if _, err := EncodePublicKeyPEM(publickey.New([]byte("test"), "")); err == nil {
+		t.Error("EncodePublicKeyPEM() should fail for invalid key")
+	}
+	if _, err := DecodePublicKeyPEM([]byte("no PEM block")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail when there is no PEM block")
+	}
+	if _, err := DecodePublicKeyPEM(pemutil.Encode("CERTIFICATE", []byte("test"), "", "")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail for unsupported block type")
+	}
+	if _, err := DecodePublicKeyPEM(pemutil.Encode("PUBLIC KEY", []byte("test"), "", "")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail for invalid key")
+	}
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+}
+
+// The handling of the directory itself is tested in internal/pemutil
+func TestLoadProviderFromDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		t.Fatalf("Could not create directory: %s", err.Error())
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	signer, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	other, err := NewProvider(Ed25519)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	priv, _ := signer.Settings().ExportPEM()
+	pub, _ := EncodePublicKeyPEM(signer.CurrentKey())
+	otherPub, _ := EncodePublicKeyPEM(other.CurrentKey())
+	writeFile(t, dir, "signing.pem", priv)
+	writeFile(t, dir, "signing_public.pem", pub)
+	writeFile(t, dir, "other.pem", otherPub)
+	writeFile(t, dir, "legacy.pem", pemutil.Encode("ED448 PUBLIC KEY", ed448PublicKey[:56], "legacy", ""))
+
+	p, err := LoadProviderFromDir(dir, Ed25519)
+	if err != nil {
+		t.Fatalf("LoadProviderFromDir() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(p.CurrentKey(), signer.CurrentKey()) {
+		t.Errorf("LoadProviderFromDir() loaded signing key %v, want %v", p.CurrentKey(), signer.CurrentKey())
+	}
+	if _, ok := p.c2[other.CurrentKey().GetKeyID()]; !ok {
+		t.Error("LoadProviderFromDir() did not add public key")
+	}
+	if _, err := LoadProviderFromDir(dir, 12); err == nil {
+		t.Error("LoadProviderFromDir() should fail for unknown algorithm")
+	}
+
+	os.Remove(filepath.Join(dir, "signing.pem")) // nolint:errcheck
+	writeFile(t, dir, "signing.pem", pemutil.Encode("PRIVATE KEY", []byte("test"), "", ""))
+	if _, err := LoadProviderFromDir(dir, Ed25519); err == nil {
+		t.Error("LoadProviderFromDir() should fail for invalid private key")
+	}
+}
+
+func TestSettings_ExportPEM_Ed448(t *testing.T) {
+	p, err := NewProvider(Ed448)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	enc, err := p.Settings().ExportPEM()
+	if err != nil {
+		t.Fatalf("Settings.ExportPEM() failed: %s", err.Error())
+	}
+	s, err := NewSettingsFromPEM(enc)
+	if err != nil {
+		t.Fatalf("NewSettingsFromPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettingsFromPEM() = %+v, want %+v", s, p.Settings())
+	}
+	pub, err := EncodePublicKeyPEM(p.CurrentKey())
+	if err != nil {
+		t.Fatalf("EncodePublicKeyPEM() failed: %s", err.Error())
+	}
+	key, err := DecodePublicKeyPEM(pub)
+	if err != nil {
+		t.Fatalf("DecodePublicKeyPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(key, p.CurrentKey()) {
+		t.Errorf("DecodePublicKeyPEM() = %v, want %v", key, p.CurrentKey())
+	}
+	if _, err := (Settings{}).ExportPEM(); err == nil {
+		t.Error("Settings.ExportPEM() should fail for empty settings")
+	}
+	if _, err := (Settings{}).Export(); err == nil {
+		t.Error("Settings.Export() should fail for empty settings")
+	}
+}
@@ -1,6 +1,7 @@
 package eddsa
 
 import (
+	"crypto"
 	"errors"
 
 	"golang.org/x/crypto/ed25519"
@@ -8,11 +9,12 @@ import (
 
 // Settings stores the signature settings for an EdDSA curve
 type Settings struct {
-	typ     int
+	typ     Curve
 	ed25519 ed25519.PrivateKey
-	ed448   [144]byte
+	ed448   []byte
 	kid     string
 	jku     string
+	signer  crypto.Signer
 }
 
 // NewSettings creates new signature settings for the parameters
@@ -20,15 +22,48 @@ func NewSettings(key []byte, keyid string) (Settings, error) {
 	return NewSettingsWithKeyURL(key, keyid, "")
 }
 
-// NewSettingsWithKeyURL creates new signature settings for the parameters
+// NewSettingsWithKeyURL creates new signature settings for the parameters.
+// Ed448 keys in the 144 byte format used by previous versions are rejected and have to be replaced using DeriveEd448KeyFromLegacy.
 func NewSettingsWithKeyURL(key []byte, keyid, keyurl string) (Settings, error) {
 	if len(key) == ed25519.SeedSize {
-		return Settings{Ed25519, ed25519.NewKeyFromSeed(key), [144]byte{0x00}, keyid, keyurl}, nil
+		return Settings{Ed25519, ed25519.NewKeyFromSeed(key), nil, keyid, keyurl, nil}, nil
 	}
-	if len(key) == 144 {
-		var priv [144]byte
-		copy(priv[:], key)
-		return Settings{Ed448, nil, priv, keyid, keyurl}, nil
+	if len(key) == ed448SeedSize {
+		return Settings{Ed448, nil, ed448NewKeyFromSeed(key), keyid, keyurl, nil}, nil
+	}
+	if len(key) == ed448LegacyKeySize {
+		return Settings{}, errLegacyEd448Key
 	}
 	return Settings{}, errors.New("private key has wrong size")
 }
+
+// NewSettingsFromSigner creates new Ed25519 signature settings that sign using the supplied signer instead of a private key.
+// This allows keeping the private key in an agent, a KMS or a separate signing process.
+// Ed448 is not supported as there is no common type for Ed448 public keys.
+func NewSettingsFromSigner(signer crypto.Signer, keyid string) (Settings, error) {
+	return NewSettingsFromSignerWithKeyURL(signer, keyid, "")
+}
+
+// NewSettingsFromSignerWithKeyURL works just like NewSettingsFromSigner but also sets the key URL
+func NewSettingsFromSignerWithKeyURL(signer crypto.Signer, keyid, keyurl string) (Settings, error) {
+	if signer == nil {
+		return Settings{}, errors.New("signer must not be nil")
+	}
+	if pub, ok := signer.Public().(ed25519.PublicKey); !ok || len(pub) != ed25519.PublicKeySize {
+		return Settings{}, errors.New("signer does not use an Ed25519 key")
+	}
+	return Settings{Ed25519, nil, nil, keyid, keyurl, signer}, nil
+}
+
+// ed25519PublicKey returns the Ed25519 public key belonging to the key used for signing
+func (s Settings) ed25519PublicKey() ed25519.PublicKey {
+	if s.signer != nil {
+		return s.signer.Public().(ed25519.PublicKey)
+	}
+	return s.ed25519.Public().(ed25519.PublicKey)
+}
+
+// ed448PublicKey returns the Ed448 public key belonging to the key used for signing
+func (s Settings) ed448PublicKey() []byte {
+	return s.ed448[ed448SeedSize:]
+}
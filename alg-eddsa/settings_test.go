@@ -1,6 +1,11 @@
 package eddsa
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
 	"reflect"
 	"testing"
 
@@ -8,10 +13,11 @@ import (
 )
 
 var (
-	ed25519PrivateKey    = [64]byte{0x40, 0xb7, 0xd9, 0xb5, 0x60, 0x97, 0x87, 0xfd, 0xee, 0x7d, 0x4e, 0xf9, 0x34, 0xa5, 0xfd, 0x44, 0xc1, 0x8b, 0x80, 0xfa, 0xd9, 0xfd, 0x2f, 0x5a, 0x73, 0xa6, 0x70, 0xc2, 0xab, 0x2c, 0xcb, 0x2e, 0x4a, 0x84, 0x0b, 0x8b, 0xcf, 0x8d, 0xac, 0xe4, 0xfe, 0x25, 0x86, 0x1d, 0xe2, 0x96, 0xfe, 0x0a, 0xd3, 0x7c, 0xdd, 0x9d, 0xb2, 0xf6, 0xd6, 0x28, 0x85, 0xb3, 0x86, 0x6d, 0x78, 0xe9, 0xb7, 0x9f}
-	ed25519Seed          = [32]byte{0x40, 0xb7, 0xd9, 0xb5, 0x60, 0x97, 0x87, 0xfd, 0xee, 0x7d, 0x4e, 0xf9, 0x34, 0xa5, 0xfd, 0x44, 0xc1, 0x8b, 0x80, 0xfa, 0xd9, 0xfd, 0x2f, 0x5a, 0x73, 0xa6, 0x70, 0xc2, 0xab, 0x2c, 0xcb, 0x2e}
-	ed448PrivateKey      = [144]byte{0xFF}
-	ed448EmptyPrivateKey = [144]byte{0x0}
+	ed25519PrivateKey = [64]byte{0x40, 0xb7, 0xd9, 0xb5, 0x60, 0x97, 0x87, 0xfd, 0xee, 0x7d, 0x4e, 0xf9, 0x34, 0xa5, 0xfd, 0x44, 0xc1, 0x8b, 0x80, 0xfa, 0xd9, 0xfd, 0x2f, 0x5a, 0x73, 0xa6, 0x70, 0xc2, 0xab, 0x2c, 0xcb, 0x2e, 0x4a, 0x84, 0x0b, 0x8b, 0xcf, 0x8d, 0xac, 0xe4, 0xfe, 0x25, 0x86, 0x1d, 0xe2, 0x96, 0xfe, 0x0a, 0xd3, 0x7c, 0xdd, 0x9d, 0xb2, 0xf6, 0xd6, 0x28, 0x85, 0xb3, 0x86, 0x6d, 0x78, 0xe9, 0xb7, 0x9f}
+	ed25519Seed       = [32]byte{0x40, 0xb7, 0xd9, 0xb5, 0x60, 0x97, 0x87, 0xfd, 0xee, 0x7d, 0x4e, 0xf9, 0x34, 0xa5, 0xfd, 0x44, 0xc1, 0x8b, 0x80, 0xfa, 0xd9, 0xfd, 0x2f, 0x5a, 0x73, 0xa6, 0x70, 0xc2, 0xab, 0x2c, 0xcb, 0x2e}
+	ed448Seed         = [57]byte{0x6c, 0x82, 0xa5, 0x62, 0xcb, 0x80, 0x8d, 0x10, 0xd6, 0x32, 0xbe, 0x89, 0xc8, 0x51, 0x3e, 0xbf, 0x6c, 0x92, 0x9f, 0x34, 0xdd, 0xfa, 0x8c, 0x9f, 0x63, 0xc9, 0x96, 0x0e, 0xf6, 0xe3, 0x48, 0xa3, 0x52, 0x8c, 0x8a, 0x3f, 0xcc, 0x2f, 0x04, 0x4e, 0x39, 0xa3, 0xfc, 0x5b, 0x94, 0x49, 0x2f, 0x8f, 0x03, 0x2e, 0x75, 0x49, 0xa2, 0x00, 0x98, 0xf9, 0x5b}
+	ed448PrivateKey   = append(ed448Seed[:], ed448PublicKey[:]...)
+	ed448LegacyKey    = [144]byte{0xFF}
 )
 
 func TestNewSettings(t *testing.T) {
@@ -26,7 +32,7 @@ func TestNewSettings(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"Normal", args{ed25519Seed[:], "key_id"}, Settings{Ed25519, ed25519.PrivateKey(ed25519PrivateKey[:]), ed448EmptyPrivateKey, "key_id", ""}, false},
+		{"Normal", args{ed25519Seed[:], "key_id"}, Settings{Ed25519, ed25519.PrivateKey(ed25519PrivateKey[:]), []byte(nil), "key_id", "", nil}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,8 +60,8 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"Ed25519", args{ed25519Seed[:], "key_id", "key_url"}, Settings{Ed25519, ed25519.PrivateKey(ed25519PrivateKey[:]), ed448EmptyPrivateKey, "key_id", "key_url"}, false},
-		{"Ed448", args{ed448PrivateKey[:], "key_id", "key_url"}, Settings{Ed448, ed25519.PrivateKey(nil), ed448PrivateKey, "key_id", "key_url"}, false},
+		{"Ed25519", args{ed25519Seed[:], "key_id", "key_url"}, Settings{Ed25519, ed25519.PrivateKey(ed25519PrivateKey[:]), []byte(nil), "key_id", "key_url", nil}, false},
+		{"Ed448", args{ed448Seed[:], "key_id", "key_url"}, Settings{Ed448, ed25519.PrivateKey(nil), ed448PrivateKey, "key_id", "key_url", nil}, false},
 		{"Wrong length", args{nil, "key_id", "key_url"}, Settings{}, true},
 	}
 	for _, tt := range tests {
@@ -71,3 +77,60 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 		})
 	}
 }
+
+// testSigner stands in for an agent or a KMS client. The private key is only reachable through the sign function so it cannot end up in the provider.
+type testSigner struct {
+	public crypto.PublicKey
+	sign   func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error)
+	calls  int
+}
+
+func newTestSigner(key crypto.Signer) *testSigner {
+	return &testSigner{public: key.Public(), sign: key.Sign}
+}
+
+func (s *testSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *testSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	return s.sign(rand, digest, opts)
+}
+
+func TestNewSettingsFromSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	wrong, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		want    Settings
+		wantErr bool
+	}{
+		{"Signer", signer, Settings{Ed25519, nil, nil, "key_id", "key_url", signer}, false},
+		{"Wrong key type", newTestSigner(wrong), Settings{}, true},
+		{"No signer", nil, Settings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettingsFromSignerWithKeyURL(tt.signer, "key_id", "key_url")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got, err := NewSettingsFromSigner(signer, "key_id"); err != nil || got.signer != signer || got.kid != "key_id" || got.jku != "" {
+		t.Errorf("NewSettingsFromSigner() = %v, %v", got, err)
+	}
+}
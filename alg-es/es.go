@@ -7,32 +7,39 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/asn1"
 	"errors"
+	"io"
 	"math/big"
+	"time"
 
 	"github.com/fossoreslp/go-jwt"
-	"github.com/fossoreslp/go-uuid-v4"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
 type curve struct {
-	alg   int
+	alg   Algorithm
 	curve elliptic.Curve
 	hash  crypto.Hash
 	ilen  int
 }
 
+// Algorithm identifies one of the ECDSA signature algorithms
+type Algorithm int
+
 const (
 	// ES256 is ECDSA using P-256 and SHA-256
-	ES256 = 1
+	ES256 Algorithm = 1
 
 	// ES384 is ECDSA using P-384 and SHA-384
-	ES384 = 2
+	ES384 Algorithm = 2
 
 	// ES512 is ECDSA using P-521 and SHA-512
-	ES512 = 3
+	ES512 Algorithm = 3
 )
 
-func algToString(alg int) string {
+func algToString(alg Algorithm) string {
 	switch alg {
 	case ES256:
 		return "ES256"
@@ -45,6 +52,11 @@ func algToString(alg int) string {
 	}
 }
 
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
 var (
 	c256 = curve{ES256, elliptic.P256(), crypto.SHA256, 32}
 	c384 = curve{ES384, elliptic.P384(), crypto.SHA384, 48}
@@ -59,21 +71,37 @@ func init() {
 
 // Provider provides ECDSA using the NIST curves and SHA2 for JWS signing and verification
 type Provider struct {
-	alg      int
+	alg      Algorithm
 	hash     crypto.Hash
 	settings Settings
 	keys     map[string]*ecdsa.PublicKey
 	ilen     int
+	resolver jwt.KeyResolver
+	random   io.Reader
+	nonce    NonceGeneration
+	validity map[string]publickey.Validity
 }
 
+var _ jwt.KeyedSignatureProvider = (*Provider)(nil)
+
 // NewProvider creates a new Provider generating the necessary keypairs
-func NewProvider(t int) (Provider, error) {
+func NewProvider(t Algorithm) (Provider, error) {
 	return NewProviderWithKeyURL(t, "")
 }
 
 // NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
-func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
-	kid, err := uuid.NewString()
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key, the key ID and signing from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	kid, err := keyid.New(source)
 	if err != nil {
 		return Provider{}, err
 	}
@@ -88,7 +116,7 @@ func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
 	default:
 		return Provider{}, errors.New("type invalid")
 	}
-	key, err := ecdsa.GenerateKey(c.curve, rand.Reader)
+	key, err := ecdsa.GenerateKey(c.curve, source)
 	if err != nil {
 		return Provider{}, err
 	}
@@ -96,27 +124,40 @@ func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
 		kid: &key.PublicKey,
 		"":  &key.PublicKey,
 	}
-	return Provider{c.alg, c.hash, Settings{key, kid, keyURL}, m, c.ilen}, nil
+	return Provider{c.alg, c.hash, Settings{key, kid, keyURL, nil}, m, c.ilen, nil, random, NonceRandom, make(map[string]publickey.Validity)}, nil
 }
 
 // LoadProvider returns a Provider using the supplied settings.
 // The public key will be ignored as the settings include all necessary information.
-func LoadProvider(s Settings, t int) (Provider, error) {
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
 	m := map[string]*ecdsa.PublicKey{
-		s.kid: &s.private.PublicKey,
-		"":    &s.private.PublicKey,
+		s.kid: s.publicKey(),
+		"":    s.publicKey(),
 	}
 	switch t {
 	case ES256:
-		return Provider{ES256, crypto.SHA256, s, m, 32}, nil
+		return Provider{ES256, crypto.SHA256, s, m, 32, nil, nil, NonceRandom, make(map[string]publickey.Validity)}, nil
 	case ES384:
-		return Provider{ES384, crypto.SHA384, s, m, 48}, nil
+		return Provider{ES384, crypto.SHA384, s, m, 48, nil, nil, NonceRandom, make(map[string]publickey.Validity)}, nil
 	case ES512:
-		return Provider{ES512, crypto.SHA512, s, m, 66}, nil
+		return Provider{ES512, crypto.SHA512, s, m, 66, nil, nil, NonceRandom, make(map[string]publickey.Validity)}, nil
 	}
 	return Provider{}, errors.New("type invalid")
 }
 
+// SetRandom sets the source of randomness used for signing. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for signing
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
 // Header sets the necessary JWT header fields
 func (p Provider) Header(h *jwt.Header) {
 	h.Alg = algToString(p.alg)
@@ -133,7 +174,7 @@ func (p Provider) Sign(c []byte) ([]byte, error) {
 	hash := p.hash.New()
 	// SHA2 does not return errors
 	hash.Write(c) // nolint:errcheck
-	r, s, err := ecdsa.Sign(rand.Reader, p.settings.private, hash.Sum(nil))
+	r, s, err := p.sign(hash.Sum(nil))
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +202,32 @@ func (p Provider) Sign(c []byte) ([]byte, error) {
 	return append(rb, sb...), nil
 }
 
+// sign signs the hash using the private key or the signer of the settings
+func (p Provider) sign(hash []byte) (*big.Int, *big.Int, error) {
+	if p.settings.signer == nil {
+		if p.nonce == NonceDeterministic {
+			return signDeterministic(p.settings.private, p.hash, hash)
+		}
+		return ecdsa.Sign(p.randomSource(), p.settings.private, hash)
+	}
+	der, err := p.settings.signer.Sign(p.randomSource(), hash, p.hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseSignature(der)
+}
+
+// parseSignature decodes an ASN.1 encoded signature as returned by crypto.Signer
+func parseSignature(der []byte) (*big.Int, *big.Int, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 {
+		return nil, nil, errors.New("signer returned invalid signature")
+	}
+	return sig.R, sig.S, nil
+}
+
 // Verify verifies if the content matches it's signature.
 func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
 	if len(sig) != 2*p.ilen {
@@ -173,11 +240,27 @@ func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
 	s := big.Int{}
 	r.SetBytes(sig[:p.ilen])
 	s.SetBytes(sig[p.ilen:])
+	sum := hash.Sum(nil)
+	keysMu.RLock()
 	pub, ok := p.keys[h.Kid]
+	validity := p.validity[h.Kid]
+	keysMu.RUnlock()
 	if !ok {
-		return errors.New("unknown key id")
+		keys, err := p.resolveKeys(data, h)
+		if err != nil {
+			return err
+		}
+		for _, pub := range keys {
+			if ecdsa.Verify(pub, sum, &r, &s) {
+				return nil
+			}
+		}
+		return errors.New("signature invalid")
+	}
+	if err := validity.Check(time.Now()); err != nil {
+		return err
 	}
-	if ecdsa.Verify(pub, hash.Sum(nil), &r, &s) {
+	if ecdsa.Verify(pub, sum, &r, &s) {
 		return nil
 	}
 	return errors.New("signature invalid")
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
 func TestNewProviderWithKeyURL(t *testing.T) {
@@ -42,7 +43,7 @@ func TestNewProviderWithKeyURL(t *testing.T) {
 func TestLoadProvider(t *testing.T) {
 	type args struct {
 		s Settings
-		t int
+		t Algorithm
 	}
 	tests := []struct {
 		name    string
@@ -50,9 +51,9 @@ func TestLoadProvider(t *testing.T) {
 		want    Provider
 		wantErr bool
 	}{
-		{"RS256", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}}, ES256}, Provider{ES256, crypto.SHA256, Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}}, map[string]*ecdsa.PublicKey{"": &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, 32}, false},
-		{"RS384", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}}}, ES384}, Provider{ES384, crypto.SHA384, Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}}}, map[string]*ecdsa.PublicKey{"": &ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}}, 48}, false},
-		{"RS512", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P521(), X: x, Y: y}}}, ES512}, Provider{ES512, crypto.SHA512, Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P521(), X: x, Y: y}}}, map[string]*ecdsa.PublicKey{"": &ecdsa.PublicKey{Curve: elliptic.P521(), X: x, Y: y}}, 66}, false},
+		{"RS256", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}}, ES256}, Provider{ES256, crypto.SHA256, Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}}, map[string]*ecdsa.PublicKey{"": &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, 32, nil, nil, NonceRandom, make(map[string]publickey.Validity)}, false},
+		{"RS384", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}}}, ES384}, Provider{ES384, crypto.SHA384, Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}}}, map[string]*ecdsa.PublicKey{"": &ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}}, 48, nil, nil, NonceRandom, make(map[string]publickey.Validity)}, false},
+		{"RS512", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P521(), X: x, Y: y}}}, ES512}, Provider{ES512, crypto.SHA512, Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P521(), X: x, Y: y}}}, map[string]*ecdsa.PublicKey{"": &ecdsa.PublicKey{Curve: elliptic.P521(), X: x, Y: y}}, 66, nil, nil, NonceRandom, make(map[string]publickey.Validity)}, false},
 		{"Unknown type", args{Settings{private: &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}}, 12}, Provider{}, true},
 	}
 	for _, tt := range tests {
@@ -132,3 +133,24 @@ func TestProvider_Verify(t *testing.T) {
 		t.Error("Verify() did not return an error when encountering a wrong signature")
 	}
 }
+
+func TestProvider_SetRandom(t *testing.T) {
+	if _, err := NewProviderWithRandom(ES256, "key_url", bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	p, err := NewProviderWithRandom(ES256, "key_url", rand.Reader)
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	if _, err := p.Sign([]byte("test")); err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	p.SetRandom(bytes.NewReader(nil))
+	if _, err := p.Sign([]byte("test")); err == nil {
+		t.Error("Sign() did not use the random source set by SetRandom()")
+	}
+	p.SetRandom(nil)
+	if _, err := p.Sign([]byte("test")); err != nil {
+		t.Errorf("Sign() failed after resetting the random source: %s", err.Error())
+	}
+}
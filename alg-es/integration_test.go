@@ -1,10 +1,18 @@
 package es
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/pem"
+	"errors"
+	"io"
+	"reflect"
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/providertest"
 )
 
 func TestES256(t *testing.T) {
@@ -15,7 +23,9 @@ func TestES256(t *testing.T) {
 	rk := p.CurrentKey()
 	b := pem.Block{Type: "PUBLIC KEY", Headers: nil, Bytes: rk.GetPublicKey()}
 	t.Logf("Created provider with key: %s", string(pem.EncodeToMemory(&b)))
-	jwt.SetSignatureProvider(algToString(ES256), p)
+	if err := jwt.SetSignatureProvider(algToString(ES256), p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm(algToString(ES256)) // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -40,7 +50,9 @@ func TestES384(t *testing.T) {
 	rk := p.CurrentKey()
 	b := pem.Block{Type: "PUBLIC KEY", Headers: nil, Bytes: rk.GetPublicKey()}
 	t.Logf("Created provider with key: %s", string(pem.EncodeToMemory(&b)))
-	jwt.SetSignatureProvider(algToString(ES384), p)
+	if err := jwt.SetSignatureProvider(algToString(ES384), p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm(algToString(ES384)) // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -65,7 +77,9 @@ func TestES512(t *testing.T) {
 	rk := p.CurrentKey()
 	b := pem.Block{Type: "PUBLIC KEY", Headers: nil, Bytes: rk.GetPublicKey()}
 	t.Logf("Created provider with key: %s", string(pem.EncodeToMemory(&b)))
-	jwt.SetSignatureProvider(algToString(ES512), p)
+	if err := jwt.SetSignatureProvider(algToString(ES512), p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm(algToString(ES512)) // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -81,3 +95,106 @@ func TestES512(t *testing.T) {
 		t.Errorf("Decoded JWT could not be validated: %s", dec.ValidationError().Error())
 	}
 }
+
+func TestKeyResolver(t *testing.T) {
+	signer, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.KeyResolver(t, &signer, &verifier, jwt.Header{Alg: "ES256"})
+}
+
+func TestSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	s, err := NewSettingsFromSigner(signer, "key_id")
+	if err != nil {
+		t.Fatalf("NewSettingsFromSigner() failed: %s", err.Error())
+	}
+	p, err := LoadProvider(s, ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	if _, err := p.Settings().Export(); err == nil {
+		t.Error("Settings.Export() should fail when using a signer")
+	}
+	if _, err := p.Settings().ExportPEM(); err == nil {
+		t.Error("Settings.ExportPEM() should fail when using a signer")
+	}
+	local, err := LoadProvider(Settings{key, "key_id", "", nil}, ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.Signer(t, &p, &local, jwt.Header{Alg: "ES256"})
+	if signer.calls != 1 {
+		t.Errorf("Signer should have been called once but was called %d times", signer.calls)
+	}
+
+	signer.sign = func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+		return nil, errors.New("signer unavailable")
+	}
+	if _, err := p.Sign([]byte("test")); err == nil {
+		t.Error("Provider.Sign() should fail when the signer fails")
+	}
+	signer.sign = func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+		return []byte("invalid signature"), nil
+	}
+	if _, err := p.Sign([]byte("test")); err == nil {
+		t.Error("Provider.Sign() should fail when the signer returns an invalid signature")
+	}
+}
+
+func TestRegisterPublicKey(t *testing.T) {
+	signer, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	if err := jwt.SetSignatureProvider(algToString(ES256), &signer); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	jwt.SetSigningAlgorithm(algToString(ES256)) // nolint:errcheck
+	enc, err := jwt.New([]byte(`{"test": 1}`)).Encode()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+
+	if err := jwt.SetSignatureProvider(algToString(ES256), &verifier); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	if dec, err := jwt.Decode(enc); err == nil && dec.Valid() {
+		t.Fatal("Token should not be valid before the key is registered")
+	}
+	names, err := jwt.RegisterPublicKey(signer.CurrentKey(), "ES256", "ES384")
+	if err != nil {
+		t.Fatalf("jwt.RegisterPublicKey() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(names, []string{"ES256"}) {
+		t.Errorf("jwt.RegisterPublicKey() = %v, want [ES256]", names)
+	}
+	if dec, err := jwt.Decode(enc); err != nil || !dec.Valid() {
+		t.Error("Token should be valid after the key was registered")
+	}
+}
+
+func TestKeyValidity(t *testing.T) {
+	signer, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.KeyValidity(t, &signer, &verifier, jwt.Header{Alg: "ES256"})
+}
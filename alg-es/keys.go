@@ -1,10 +1,14 @@
 package es
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/x509"
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/fossoreslp/go-jwt"
 	"github.com/fossoreslp/go-jwt/publickey"
 )
 
@@ -13,6 +17,7 @@ type Settings struct {
 	private *ecdsa.PrivateKey
 	kid     string
 	jku     string
+	signer  crypto.Signer
 }
 
 // NewSettings creates new signature settings for the parameters
@@ -34,38 +39,129 @@ func NewSettingsWithKeyURL(key []byte, keyid, keyurl string) (Settings, error) {
 		}
 		priv = ecKey
 	}
-	return Settings{priv, keyid, keyurl}, nil
+	return Settings{priv, keyid, keyurl, nil}, nil
 }
 
+// NewSettingsFromSigner creates new signature settings that sign using the supplied signer instead of a private key.
+// This allows keeping the private key in an agent, a KMS or a separate signing process.
+// The signer has to return ASN.1 encoded signatures just like ecdsa.PrivateKey does.
+func NewSettingsFromSigner(signer crypto.Signer, keyid string) (Settings, error) {
+	return NewSettingsFromSignerWithKeyURL(signer, keyid, "")
+}
+
+// NewSettingsFromSignerWithKeyURL works just like NewSettingsFromSigner but also sets the key URL
+func NewSettingsFromSignerWithKeyURL(signer crypto.Signer, keyid, keyurl string) (Settings, error) {
+	if signer == nil {
+		return Settings{}, errors.New("signer must not be nil")
+	}
+	if _, ok := signer.Public().(*ecdsa.PublicKey); !ok {
+		return Settings{}, errors.New("signer does not use an ECDSA key")
+	}
+	return Settings{nil, keyid, keyurl, signer}, nil
+}
+
+// publicKey returns the public key belonging to the key used for signing
+func (s Settings) publicKey() *ecdsa.PublicKey {
+	if s.signer != nil {
+		return s.signer.Public().(*ecdsa.PublicKey)
+	}
+	return &s.private.PublicKey
+}
+
+// keysMu guards the key maps of all providers so keys can be managed while tokens are being verified
+var keysMu sync.RWMutex
+
 // AddPublicKey adds a public key for verification
 func (p *Provider) AddPublicKey(key publickey.PublicKey) error {
+	if err := key.CheckMetadata(algToString(p.alg), "EC", "sig", "verify"); err != nil {
+		return err
+	}
+	validity := key.GetValidity()
+	if validity.Expired(time.Now()) {
+		return errors.New("key expired")
+	}
 	id := key.GetKeyID()
+	keysMu.Lock()
+	defer keysMu.Unlock()
 	if _, ok := p.keys[id]; ok {
 		return errors.New("key ID already exists")
 	}
-	enc := key.GetPublicKey()
-	k, err := x509.ParsePKIXPublicKey(enc)
+	ecdsaKey, err := parsePublicKey(key)
 	if err != nil {
-		return errors.New("could not decode public key")
+		return err
 	}
-	ecdsaKey, ok := k.(*ecdsa.PublicKey)
-	if !ok {
-		return errors.New("public key is not an ECDSA public key")
+	if !p.usesCurve(ecdsaKey) {
+		return errors.New("public key uses a different curve than the algorithm")
 	}
 	p.keys[id] = ecdsaKey
+	if !validity.IsZero() {
+		p.validity[id] = validity
+	}
 	return nil
 }
 
+// SetKeyResolver sets a resolver that is consulted for keys when the key ID of a token is unknown to the provider
+func (p *Provider) SetKeyResolver(r jwt.KeyResolver) {
+	p.resolver = r
+}
+
 // RemovePublicKey removes a public key by it's key ID from the verification set
 func (p *Provider) RemovePublicKey(keyid string) {
 	if keyid == p.settings.kid {
 		return
 	}
+	keysMu.Lock()
 	delete(p.keys, keyid)
+	delete(p.validity, keyid)
+	keysMu.Unlock()
 }
 
 // CurrentKey returns the public key belonging to the private key used for signing
 func (p Provider) CurrentKey() publickey.PublicKey {
-	key, _ := x509.MarshalPKIXPublicKey(&p.settings.private.PublicKey) // No need to check error as marshaling an EC public key can only fail for an unsupported curve which cannot be introduced as it would fail to unmarshal.
+	key, _ := x509.MarshalPKIXPublicKey(p.settings.publicKey()) // No need to check error as marshaling an EC public key can only fail for an unsupported curve which cannot be introduced as it would fail to unmarshal.
 	return publickey.New(key, p.settings.kid)
 }
+
+func parsePublicKey(key publickey.PublicKey) (*ecdsa.PublicKey, error) {
+	k, err := x509.ParsePKIXPublicKey(key.GetPublicKey())
+	if err != nil {
+		return nil, errors.New("could not decode public key")
+	}
+	ecdsaKey, ok := k.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an ECDSA public key")
+	}
+	return ecdsaKey, nil
+}
+
+// usesCurve checks whether the public key uses the curve required by the algorithm of the provider
+func (p Provider) usesCurve(pub *ecdsa.PublicKey) bool {
+	return (pub.Curve.Params().BitSize+7)/8 == p.ilen
+}
+
+// resolveKeys retrieves the keys that may have been used to sign a token from the key resolver
+func (p Provider) resolveKeys(data []byte, h jwt.Header) ([]*ecdsa.PublicKey, error) {
+	if p.resolver == nil {
+		return nil, errors.New("unknown key id")
+	}
+	c, err := jwt.ContentFromSigningInput(data)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := p.resolver.Resolve(h, c)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([]*ecdsa.PublicKey, 0, len(keys))
+	for _, k := range keys {
+		// A resolver may return keys for other algorithms or curves which are skipped
+		// Keys whose metadata does not permit verifying the token are skipped as well
+		if pub, err := parsePublicKey(k); err == nil && p.usesCurve(pub) && k.CheckUsage(h.Alg, "EC", "sig", "verify") == nil {
+			parsed = append(parsed, pub)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("unknown key id")
+	}
+	return parsed, nil
+}
@@ -1,13 +1,19 @@
 package es
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"io"
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/fossoreslp/go-jwt/publickey"
+	"golang.org/x/crypto/ed25519"
 )
 
 var (
@@ -34,8 +40,8 @@ func TestNewSettings(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"EC key", args{ec, "key_id"}, Settings{priv, "key_id", ""}, false},
-		{"PKCS8 key", args{pkcs8, "key_id"}, Settings{priv, "key_id", ""}, false},
+		{"EC key", args{ec, "key_id"}, Settings{priv, "key_id", "", nil}, false},
+		{"PKCS8 key", args{pkcs8, "key_id"}, Settings{priv, "key_id", "", nil}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -63,8 +69,8 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"EC key", args{ec, "key_id", "key_url"}, Settings{priv, "key_id", "key_url"}, false},
-		{"PKCS8 key", args{pkcs8, "key_id", "key_url"}, Settings{priv, "key_id", "key_url"}, false},
+		{"EC key", args{ec, "key_id", "key_url"}, Settings{priv, "key_id", "key_url", nil}, false},
+		{"PKCS8 key", args{pkcs8, "key_id", "key_url"}, Settings{priv, "key_id", "key_url", nil}, false},
 		{"Invalid", args{[]byte("invalid key"), "key_id", "key_url"}, Settings{}, true},
 		{"PKCS8 RSA key", args{pkcs8RSA, "key_id", "key_url"}, Settings{}, true},
 	}
@@ -83,6 +89,14 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 }
 
 func TestProvider_AddPublicKey(t *testing.T) {
+	key384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate P-384 key: %s", err.Error())
+	}
+	pkix384, err := x509.MarshalPKIXPublicKey(&key384.PublicKey)
+	if err != nil {
+		t.Fatalf("Could not encode P-384 key: %s", err.Error())
+	}
 	type args struct {
 		key publickey.PublicKey
 	}
@@ -92,9 +106,13 @@ func TestProvider_AddPublicKey(t *testing.T) {
 		args    args
 		wantErr bool
 	}{
-		{"Normal", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, args{publickey.New(pkix, "key_id")}, false},
+		{"Normal", &Provider{alg: ES256, keys: make(map[string]*ecdsa.PublicKey), ilen: 32}, args{publickey.New(pkix, "key_id")}, false},
+		{"Other curve", &Provider{alg: ES256, keys: make(map[string]*ecdsa.PublicKey), ilen: 32}, args{publickey.New(pkix384, "key_id")}, true},
 		{"Already exists", &Provider{keys: map[string]*ecdsa.PublicKey{"key_id": &pub}}, args{publickey.New(pkix, "key_id")}, true},
 		{"Invalid", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, args{publickey.New([]byte("invalid key"), "key_id")}, true},
+		{"Other algorithm", &Provider{alg: ES256, keys: make(map[string]*ecdsa.PublicKey)}, args{publickey.New(pkix, "key_id").WithAlgorithm("ES384")}, true},
+		{"Other key type", &Provider{alg: ES256, keys: make(map[string]*ecdsa.PublicKey)}, args{publickey.New(pkix, "key_id").WithKeyType("RSA")}, true},
+		{"Expired", &Provider{alg: ES256, keys: make(map[string]*ecdsa.PublicKey)}, args{publickey.New(pkix, "key_id").WithValidity(time.Time{}, time.Now().Add(-time.Hour))}, true},
 		{"PKIX RSA key", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, args{publickey.New(pkixRSA, "key_id")}, true},
 	}
 	for _, tt := range tests {
@@ -138,3 +156,60 @@ func TestProvider_CurrentKey(t *testing.T) {
 		})
 	}
 }
+
+// testSigner stands in for an agent or a KMS client. The private key is only reachable through the sign function so it cannot end up in the provider.
+type testSigner struct {
+	public crypto.PublicKey
+	sign   func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error)
+	calls  int
+}
+
+func newTestSigner(key crypto.Signer) *testSigner {
+	return &testSigner{public: key.Public(), sign: key.Sign}
+}
+
+func (s *testSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *testSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	return s.sign(rand, digest, opts)
+}
+
+func TestNewSettingsFromSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	_, wrong, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		want    Settings
+		wantErr bool
+	}{
+		{"Signer", signer, Settings{nil, "key_id", "key_url", signer}, false},
+		{"Wrong key type", newTestSigner(wrong), Settings{}, true},
+		{"No signer", nil, Settings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettingsFromSignerWithKeyURL(tt.signer, "key_id", "key_url")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got, err := NewSettingsFromSigner(signer, "key_id"); err != nil || got.signer != signer || got.kid != "key_id" || got.jku != "" {
+		t.Errorf("NewSettingsFromSigner() = %v, %v", got, err)
+	}
+}
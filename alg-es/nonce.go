@@ -0,0 +1,43 @@
+package es
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+// NonceGeneration selects how the nonces used for signing are generated
+type NonceGeneration int
+
+const (
+	// NonceRandom derives the nonce of every signature from the random source, the private key and the hash. This is the default.
+	// Signatures stay secure even if the random source is weak.
+	NonceRandom NonceGeneration = 0
+
+	// NonceDeterministic derives the nonce from the private key and the hash as specified in RFC 6979 so signing the same content always results in the same signature
+	NonceDeterministic NonceGeneration = 1
+)
+
+// SetNonceGeneration sets how the nonces used for signing are generated.
+// It fails for providers using an external signer as the signer generates the nonce itself.
+func (p *Provider) SetNonceGeneration(mode NonceGeneration) error {
+	if mode != NonceRandom && mode != NonceDeterministic {
+		return errors.New("nonce generation mode invalid")
+	}
+	if p.settings.signer != nil && mode != NonceRandom {
+		return errors.New("nonces can not be set for external signers")
+	}
+	p.nonce = mode
+	return nil
+}
+
+// signDeterministic signs the hash using a nonce derived from the private key and the hash as specified in RFC 6979.
+// The signature is computed by the standard library in constant time.
+func signDeterministic(priv *ecdsa.PrivateKey, h crypto.Hash, hash []byte) (*big.Int, *big.Int, error) {
+	der, err := priv.Sign(nil, hash, h)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseSignature(der)
+}
@@ -0,0 +1,123 @@
+package es
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func hexInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 16)
+	return i
+}
+
+// Test vectors from RFC 6979 appendix A.2.5, A.2.6 and A.2.7
+func TestSignDeterministic(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve elliptic.Curve
+		hash  crypto.Hash
+		x     string
+		msg   string
+		r     string
+		s     string
+	}{
+		{"P-256 SHA-256 sample", elliptic.P256(), crypto.SHA256, "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", "sample",
+			"EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8"},
+		{"P-256 SHA-256 test", elliptic.P256(), crypto.SHA256, "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", "test",
+			"F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367",
+			"019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083"},
+		{"P-384 SHA-384 sample", elliptic.P384(), crypto.SHA384, "6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5", "sample",
+			"94EDBB92A5ECB8AAD4736E56C691916B3F88140666CE9FA73D64C4EA95AD133C81A648152E44ACF96E36DD1E80FABE46",
+			"99EF4AEB15F178CEA1FE40DB2603138F130E740A19624526203B6351D0A3A94FA329C145786E679E7B82C71A38628AC8"},
+		{"P-384 SHA-384 test", elliptic.P384(), crypto.SHA384, "6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5", "test",
+			"8203B63D3C853E8D77227FB377BCF7B7B772E97892A80F36AB775D509D7A5FEB0542A7F0812998DA8F1DD3CA3CF023DB",
+			"DDD0760448D42D8A43AF45AF836FCE4DE8BE06B485E9B61B827C2F13173923E06A739F040649A667BF3B828246BAA5A5"},
+		{"P-521 SHA-512 sample", elliptic.P521(), crypto.SHA512, "0FAD06DAA62BA3B25D2FB40133DA757205DE67F5BB0018FEE8C86E1B68C7E75CAA896EB32F1F47C70855836A6D16FCC1466F6D8FBEC67DB89EC0C08B0E996B83538", "sample",
+			"0C328FAFCBD79DD77850370C46325D987CB525569FB63C5D3BC53950E6D4C5F174E25A1EE9017B5D450606ADD152B534931D7D4E8455CC91F9B15BF05EC36E377FA",
+			"0617CCE7CF5064806C467F678D3B4080D6F1CC50AF26CA209417308281B68AF282623EAA63E5B5C0723D8B8C37FF0777B1A20F8CCB1DCCC43997F1EE0E44DA4A67A"},
+		{"P-521 SHA-512 test", elliptic.P521(), crypto.SHA512, "0FAD06DAA62BA3B25D2FB40133DA757205DE67F5BB0018FEE8C86E1B68C7E75CAA896EB32F1F47C70855836A6D16FCC1466F6D8FBEC67DB89EC0C08B0E996B83538", "test",
+			"13E99020ABF5CEE7525D16B69B229652AB6BDF2AFFCAEF38773B4B7D08725F10CDB93482FDCC54EDCEE91ECA4166B2A7C6265EF0CE2BD7051B7CEF945BABD47EE6D",
+			"1FBD0013C674AA79CB39849527916CE301C66EA7CE8B80682786AD60F98F7E78A19CA69EFF5C57400E3B3A0AD66CE0978214D13BAF4E9AC60752F7B155E2DE4DCE3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv := &ecdsa.PrivateKey{D: hexInt(tt.x)}
+			priv.Curve = tt.curve
+			priv.X, priv.Y = tt.curve.ScalarBaseMult(priv.D.Bytes())
+			h := tt.hash.New()
+			h.Write([]byte(tt.msg)) // nolint:errcheck
+			hash := h.Sum(nil)
+			r, s, err := signDeterministic(priv, tt.hash, hash)
+			if err != nil {
+				t.Fatalf("signDeterministic() failed: %s", err.Error())
+			}
+			if r.Cmp(hexInt(tt.r)) != 0 || s.Cmp(hexInt(tt.s)) != 0 {
+				t.Errorf("signDeterministic() = (%X, %X), want (%s, %s)", r, s, tt.r, tt.s)
+			}
+			if !ecdsa.Verify(&priv.PublicKey, hash, r, s) {
+				t.Error("Signature could not be verified")
+			}
+		})
+	}
+}
+
+func TestProvider_SetNonceGeneration(t *testing.T) {
+	p, err := NewProvider(ES256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	if err := p.SetNonceGeneration(12); err == nil {
+		t.Error("SetNonceGeneration() should fail for unknown mode")
+	}
+	if err := p.SetNonceGeneration(NonceDeterministic); err != nil {
+		t.Fatalf("SetNonceGeneration() failed: %s", err.Error())
+	}
+	a, err := p.Sign([]byte("test"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	b, err := p.Sign([]byte("test"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	if !bytes.Equal(a, b) {
+		t.Error("Deterministic signatures differ for the same content")
+	}
+	if err := p.SetNonceGeneration(NonceRandom); err != nil {
+		t.Fatalf("SetNonceGeneration() failed: %s", err.Error())
+	}
+	c, err := p.Sign([]byte("test"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	if err := p.Verify([]byte("test"), c, jwt.Header{}); err != nil {
+		t.Errorf("Random signature could not be verified: %s", err.Error())
+	}
+	d, err := p.Sign([]byte("test"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	if bytes.Equal(c, d) {
+		t.Error("Random signatures are equal for the same content")
+	}
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, err := NewSettingsFromSigner(key, "key_id")
+	if err != nil {
+		t.Fatalf("NewSettingsFromSigner() failed: %s", err.Error())
+	}
+	ps, err := LoadProvider(s, ES256)
+	if err != nil {
+		t.Fatalf("LoadProvider() failed: %s", err.Error())
+	}
+	if err := ps.SetNonceGeneration(NonceDeterministic); err == nil {
+		t.Error("SetNonceGeneration() should fail for external signers")
+	}
+}
@@ -0,0 +1,136 @@
+package es256k
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// The standard library does not support secp256k1 so keys are encoded and decoded using the structures from RFC 5480 and RFC 5915 directly
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+	errNotSecp256k1 = errors.New("key does not use the curve secp256k1")
+)
+
+// ecPrivateKey is an EC private key as defined in RFC 5915
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8 is a private key as defined in RFC 5208
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// publicKeyInfo is a PKIX public key as defined in RFC 5280
+type publicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parsePrivateKey decodes a secp256k1 private key encoded as PKCS8 or EC private key
+func parsePrivateKey(der []byte) (*ecdsa.PrivateKey, error) {
+	var p pkcs8
+	if rest, err := asn1.Unmarshal(der, &p); err == nil && len(rest) == 0 {
+		if !p.Algo.Algorithm.Equal(oidPublicKeyECDSA) {
+			return nil, errors.New("PKCS8 does not contain an ECDSA private key")
+		}
+		if err := checkCurve(p.Algo.Parameters); err != nil {
+			return nil, err
+		}
+		// The curve is already specified by PKCS8 and may be omitted from the EC private key
+		return parseECPrivateKey(p.PrivateKey, false)
+	}
+	return parseECPrivateKey(der, true)
+}
+
+func parseECPrivateKey(der []byte, requireCurve bool) (*ecdsa.PrivateKey, error) {
+	var k ecPrivateKey
+	if rest, err := asn1.Unmarshal(der, &k); err != nil || len(rest) > 0 {
+		return nil, errors.New("could not decode private key as either EC or PKCS8")
+	}
+	if k.Version != 1 {
+		return nil, errors.New("unsupported EC private key version")
+	}
+	if (requireCurve || k.NamedCurveOID != nil) && !k.NamedCurveOID.Equal(oidSecp256k1) {
+		return nil, errNotSecp256k1
+	}
+	d := new(big.Int).SetBytes(k.PrivateKey)
+	if len(k.PrivateKey) > ilen || !inRange(d) {
+		return nil, errors.New("private key is out of range")
+	}
+	return secp256k1.PrivKeyFromBytes(k.PrivateKey).ToECDSA(), nil
+}
+
+// marshalPrivateKey encodes a secp256k1 private key as PKCS8
+func marshalPrivateKey(priv *ecdsa.PrivateKey) ([]byte, error) {
+	d := priv.D.Bytes()
+	padded := make([]byte, ilen)
+	copy(padded[ilen-len(d):], d)
+	point := marshalPoint(&priv.PublicKey)
+	// Marshaling cannot fail as the structures only contain supported types
+	key, _ := asn1.Marshal(ecPrivateKey{Version: 1, PrivateKey: padded, PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)}})
+	return asn1.Marshal(pkcs8{0, algorithmIdentifier(), key})
+}
+
+// parsePublicKey decodes a secp256k1 public key encoded as PKIX. The point may be compressed or uncompressed.
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var info publicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		return nil, errors.New("could not decode public key")
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, errors.New("public key is not an ECDSA public key")
+	}
+	if err := checkCurve(info.Algorithm.Parameters); err != nil {
+		return nil, err
+	}
+	key, err := secp256k1.ParsePubKey(info.PublicKey.RightAlign())
+	if err != nil {
+		return nil, err
+	}
+	return key.ToECDSA(), nil
+}
+
+// marshalPublicKey encodes a secp256k1 public key as PKIX using an uncompressed point
+func marshalPublicKey(pub *ecdsa.PublicKey) []byte {
+	point := marshalPoint(pub)
+	// Marshaling cannot fail as the structure only contains supported types
+	der, _ := asn1.Marshal(publicKeyInfo{algorithmIdentifier(), asn1.BitString{Bytes: point, BitLength: 8 * len(point)}})
+	return der
+}
+
+// marshalPoint encodes the public key as an uncompressed point as defined in SEC 1 section 2.3.3
+func marshalPoint(pub *ecdsa.PublicKey) []byte {
+	point := make([]byte, 1+2*ilen)
+	point[0] = 4
+	x, y := pub.X.Bytes(), pub.Y.Bytes()
+	copy(point[1+ilen-len(x):1+ilen], x)
+	copy(point[1+2*ilen-len(y):], y)
+	return point
+}
+
+func algorithmIdentifier() pkix.AlgorithmIdentifier {
+	params, _ := asn1.Marshal(oidSecp256k1) // An object identifier can always be marshaled
+	return pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}}
+}
+
+// checkCurve returns an error unless the algorithm parameters name the curve secp256k1
+func checkCurve(params asn1.RawValue) error {
+	var named asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(params.FullBytes, &named); err != nil || len(rest) > 0 || !named.Equal(oidSecp256k1) {
+		return errNotSecp256k1
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package es256k
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// Keys generated using OpenSSL
+var (
+	d, _           = new(big.Int).SetString("f72b9f842f14666d7dc35fd5848871e7684284d9c3b2990be904e3525f806330", 16)
+	x, _           = new(big.Int).SetString("a31df2b36a3fdfd01e0f99b14825141005fa0d1fe9f4f60ebf8befbb3f4cba49", 16)
+	y, _           = new(big.Int).SetString("5801c8e8864dc79fc30514bc3d287526b35346f8c26f39dddc41a26a20b1fb3d", 16)
+	sec1           = decode("MHQCAQEEIPcrn4QvFGZtfcNf1YSIcedoQoTZw7KZC+kE41JfgGMwoAcGBSuBBAAKoUQDQgAEox3ys2o/39AeD5mxSCUUEAX6DR/p9PYOv4vvuz9MuklYAcjohk3Hn8MFFLw9KHUms1NG+MJvOd3cQaJqILH7PQ==")
+	priv           = &ecdsa.PrivateKey{PublicKey: pub, D: d}
+	pub            = ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	pkcs8Key       = decode("MIGEAgEAMBAGByqGSM49AgEGBSuBBAAKBG0wawIBAQQg9yufhC8UZm19w1/VhIhx52hChNnDspkL6QTjUl+AYzChRANCAASjHfKzaj/f0B4PmbFIJRQQBfoNH+n09g6/i++7P0y6SVgByOiGTcefwwUUvD0odSazU0b4wm853dxBomogsfs9")
+	pkixKey        = decode("MFYwEAYHKoZIzj0CAQYFK4EEAAoDQgAEox3ys2o/39AeD5mxSCUUEAX6DR/p9PYOv4vvuz9MuklYAcjohk3Hn8MFFLw9KHUms1NG+MJvOd3cQaJqILH7PQ==")
+	pkixCompressed = decode("MDYwEAYHKoZIzj0CAQYFK4EEAAoDIgADox3ys2o/39AeD5mxSCUUEAX6DR/p9PYOv4vvuz9Mukk=")
+	sec1P256       = decode("MHcCAQEEIKl3372LuDrBRy17S2g1S6Kc2UKF2I6cSJZfnMzuPN5VoAoGCCqGSM49AwEHoUQDQgAEFlhFhKG1KMpM/GE4wSTn9g1J93LvftpKta8JGMLoVb5BgjnQTiJB7i4jOOjGKtSQlXIBXkwaps65XH1Fh74hBQ==")
+	pkcs8P256      = decode("MIGHAgEAMBMGByqGSM49AgEGCCqGSM49AwEHBG0wawIBAQQgqXffvYu4OsFHLXtLaDVLopzZQoXYjpxIll+czO483lWhRANCAAQWWEWEobUoykz8YTjBJOf2DUn3cu9+2kq1rwkYwuhVvkGCOdBOIkHuLiM46MYq1JCVcgFeTBqmzrlcfUWHviEF")
+	pkixP256       = decode("MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEI9ZZ50NVzwGzuTuXCBrBl43+j76ShM7E3CBfKUXQP654zML+zg2f94NKkZuv79nlMBfdZcPvcNS/tpRgqd9fgQ==")
+	pkcs8RSA       = decode("MIIBVQIBADANBgkqhkiG9w0BAQEFAASCAT8wggE7AgEAAkEApt9wsLyPgbOtr1oqUJrHq+k4ygOvR8oXJjPm41/Tbs9qwUuaEer4MdLxIHnhMN2KC/miLDPkXbl71nb2CDn1HQIDAQABAkAMlLn9SSoUoERO/SBFlXXKWzoB2QdFBVJ7edxFdjsNloyBsjbBnRP4liLQjkK2oz2znKydHjcOWi21acfFxtfNAiEA2rhACk3hQCEq69qaO0VUQbLbJMNHEoNmh0VTHs3VwBMCIQDDUN7F6uezUNhW/tYZ088bVOckxXgUZqs9H8Bab/v8DwIgLc/P4hij6InJ8rLMAUQpPMWYrx+T4GXTcNvtaBNXBS8CIQC0mjrkV+oX7VGeX9Yp26lOZJu3bME0XBtLUejTt2kSEQIhAMxmyCR861p5ElnEoJ0C00UrLQloRPuaLhR/6Hc3kHbX")
+	pkixRSA        = decode("MFwwDQYJKoZIhvcNAQEBBQADSwAwSAJBAKbfcLC8j4Gzra9aKlCax6vpOMoDr0fKFyYz5uNf027PasFLmhHq+DHS8SB54TDdigv5oiwz5F25e9Z29gg59R0CAwEAAQ==")
+)
+
+func decode(s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func Test_parsePrivateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		want    *ecdsa.PrivateKey
+		wantErr bool
+	}{
+		{"EC key", sec1, priv, false},
+		{"PKCS8 key", pkcs8Key, priv, false},
+		{"EC key P-256", sec1P256, nil, true},
+		{"PKCS8 key P-256", pkcs8P256, nil, true},
+		{"PKCS8 RSA key", pkcs8RSA, nil, true},
+		{"Invalid", []byte("invalid key"), nil, true},
+		{"Wrong version", mustMarshal(ecPrivateKey{Version: 2, PrivateKey: d.Bytes(), NamedCurveOID: oidSecp256k1}), nil, true},
+		{"Missing curve", mustMarshal(ecPrivateKey{Version: 1, PrivateKey: d.Bytes()}), nil, true},
+		{"Zero", mustMarshal(ecPrivateKey{Version: 1, PrivateKey: []byte{0}, NamedCurveOID: oidSecp256k1}), nil, true},
+		{"Out of range", mustMarshal(ecPrivateKey{Version: 1, PrivateKey: curve.Params().N.Bytes(), NamedCurveOID: oidSecp256k1}), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrivateKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePrivateKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_marshalPrivateKey(t *testing.T) {
+	got, err := marshalPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("marshalPrivateKey() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(got, pkcs8Key) {
+		t.Errorf("marshalPrivateKey() = %v, want %v", got, pkcs8Key)
+	}
+}
+
+func Test_parsePublicKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		want    *ecdsa.PublicKey
+		wantErr bool
+	}{
+		{"Uncompressed", pkixKey, &pub, false},
+		{"Compressed", pkixCompressed, &pub, false},
+		{"P-256", pkixP256, nil, true},
+		{"RSA", pkixRSA, nil, true},
+		{"Invalid", []byte("invalid key"), nil, true},
+		{"Invalid point", mustMarshal(publicKeyInfo{algorithmIdentifier(), asn1.BitString{Bytes: []byte{4, 1, 2}, BitLength: 24}}), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePublicKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePublicKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePublicKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_marshalPublicKey(t *testing.T) {
+	if got := marshalPublicKey(&pub); !reflect.DeepEqual(got, pkixKey) {
+		t.Errorf("marshalPublicKey() = %v, want %v", got, pkixKey)
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,251 @@
+package es256k
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+const (
+	// algorithm is the name of ECDSA using secp256k1 and SHA-256 as defined in RFC 8812
+	algorithm = "ES256K"
+
+	// ilen is the length of the integers r and s of a signature
+	ilen = 32
+)
+
+// Algorithm identifies the ECDSA signature algorithm using secp256k1
+type Algorithm int
+
+const (
+	// ES256K is ECDSA using secp256k1 and SHA-256
+	ES256K Algorithm = 1
+)
+
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	if a == ES256K {
+		return algorithm
+	}
+	return ""
+}
+
+var curve = secp256k1.S256()
+
+// Provider provides ECDSA using secp256k1 and SHA-256 for JWS signing and verification
+type Provider struct {
+	settings Settings
+	keys     map[string]*ecdsa.PublicKey
+	resolver jwt.KeyResolver
+	random   io.Reader
+	validity map[string]publickey.Validity
+}
+
+var _ jwt.KeyedSignatureProvider = (*Provider)(nil)
+
+// NewProvider creates a new Provider generating the necessary keypair.
+// It fails for any algorithm other than ES256K.
+func NewProvider(t Algorithm) (Provider, error) {
+	return NewProviderWithKeyURL(t, "")
+}
+
+// NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated key
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key and the key ID from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	if t != ES256K {
+		return Provider{}, errors.New("type invalid")
+	}
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	kid, err := keyid.New(source)
+	if err != nil {
+		return Provider{}, err
+	}
+	key, err := generateKey(source)
+	if err != nil {
+		return Provider{}, err
+	}
+	m := map[string]*ecdsa.PublicKey{
+		kid: &key.PublicKey,
+		"":  &key.PublicKey,
+	}
+	return Provider{Settings{key, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
+}
+
+// LoadProvider returns a Provider using the supplied settings.
+// It fails for any algorithm other than ES256K or if the settings contain neither a private key nor a signer.
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if t != ES256K {
+		return Provider{}, errors.New("type invalid")
+	}
+	if s.private == nil && s.signer == nil {
+		return Provider{}, errors.New("settings do not contain a key")
+	}
+	m := map[string]*ecdsa.PublicKey{
+		s.kid: s.publicKey(),
+		"":    s.publicKey(),
+	}
+	return Provider{s, m, nil, nil, make(map[string]publickey.Validity)}, nil
+}
+
+// SetRandom sets the source of randomness passed to external signers. Passing nil uses crypto/rand which is the default.
+// Signatures created using a private key are deterministic and do not use it.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness passed to external signers
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
+// Header sets the necessary JWT header fields
+func (p Provider) Header(h *jwt.Header) {
+	h.Alg = algorithm
+	if p.settings.kid != "" {
+		h.Kid = p.settings.kid
+	}
+	if p.settings.jku != "" {
+		h.Jku = p.settings.jku
+	}
+}
+
+// Sign signs the content of a JWT. Signatures are normalized to use the lower of the two possible values of s.
+// Signatures created using a private key are deterministic as specified in RFC 6979 and computed in constant time.
+func (p Provider) Sign(c []byte) ([]byte, error) {
+	sum := sha256.Sum256(c)
+	if p.settings.signer != nil {
+		return p.signExternal(sum[:])
+	}
+	if p.settings.private == nil {
+		return nil, errors.New("settings do not contain a key")
+	}
+	key := toPrivateKey(p.settings.private)
+	defer key.Zero()
+	// The compact signature consists of a recovery code followed by r and s which are already normalized to low S
+	return secp256k1ecdsa.SignCompact(key, sum[:], false)[1:], nil
+}
+
+// signExternal signs the hash using the signer of the settings and normalizes the signature to low S
+func (p Provider) signExternal(hash []byte) ([]byte, error) {
+	der, err := p.settings.signer.Sign(p.randomSource(), hash, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	var sig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 || !inRange(sig.R) || !inRange(sig.S) {
+		return nil, errors.New("signer returned invalid signature")
+	}
+	var r, s secp256k1.ModNScalar
+	r.SetByteSlice(sig.R.Bytes())
+	s.SetByteSlice(sig.S.Bytes())
+	// Both s and N - s are valid, only the lower one is accepted to prevent signature malleability
+	if s.IsOverHalfOrder() {
+		s.Negate()
+	}
+	out := make([]byte, 2*ilen)
+	r.PutBytesUnchecked(out[:ilen])
+	s.PutBytesUnchecked(out[ilen:])
+	return out, nil
+}
+
+// inRange returns whether the integer is a valid value for r or s which have to be between 1 and N - 1
+func inRange(n *big.Int) bool {
+	return n.Sign() > 0 && n.Cmp(curve.Params().N) < 0
+}
+
+// Verify verifies if the content matches it's signature. Signatures using the higher value of s are rejected.
+func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
+	if len(sig) != 2*ilen {
+		return errors.New("signature invalid")
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:ilen]) || s.SetByteSlice(sig[ilen:]) {
+		return errors.New("signature invalid")
+	}
+	if s.IsOverHalfOrder() {
+		return errors.New("signature is not normalized to low S")
+	}
+	signature := secp256k1ecdsa.NewSignature(&r, &s)
+	sum := sha256.Sum256(data)
+	keysMu.RLock()
+	pub, ok := p.keys[h.Kid]
+	validity := p.validity[h.Kid]
+	keysMu.RUnlock()
+	if !ok {
+		keys, err := p.resolveKeys(data, h)
+		if err != nil {
+			return err
+		}
+		for _, pub := range keys {
+			if verify(signature, sum[:], pub) {
+				return nil
+			}
+		}
+		return errors.New("signature invalid")
+	}
+	if err := validity.Check(time.Now()); err != nil {
+		return err
+	}
+	if verify(signature, sum[:], pub) {
+		return nil
+	}
+	return errors.New("signature invalid")
+}
+
+// verify returns whether the signature of the hash is valid for the public key
+func verify(sig *secp256k1ecdsa.Signature, hash []byte, pub *ecdsa.PublicKey) bool {
+	key, err := toPublicKey(pub)
+	return err == nil && sig.Verify(hash, key)
+}
+
+// generateKey generates a private key reading the scalar from random. Values that are zero or not smaller than the order of the curve are discarded.
+func generateKey(random io.Reader) (*ecdsa.PrivateKey, error) {
+	var d [ilen]byte
+	defer func() { d = [ilen]byte{} }()
+	for {
+		if _, err := io.ReadFull(random, d[:]); err != nil {
+			return nil, err
+		}
+		var k secp256k1.ModNScalar
+		if overflow := k.SetByteSlice(d[:]); !overflow && !k.IsZero() {
+			return secp256k1.NewPrivateKey(&k).ToECDSA(), nil
+		}
+	}
+}
+
+// toPrivateKey converts the private key to the representation used by the secp256k1 package
+func toPrivateKey(priv *ecdsa.PrivateKey) *secp256k1.PrivateKey {
+	var d [ilen]byte
+	defer func() { d = [ilen]byte{} }()
+	return secp256k1.PrivKeyFromBytes(priv.D.FillBytes(d[:]))
+}
+
+// toPublicKey converts the public key to the representation used by the secp256k1 package. It fails if the point is not on the curve.
+func toPublicKey(pub *ecdsa.PublicKey) (*secp256k1.PublicKey, error) {
+	return secp256k1.ParsePubKey(marshalPoint(pub))
+}
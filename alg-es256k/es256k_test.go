@@ -0,0 +1,228 @@
+package es256k
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestNewProviderWithKeyURL(t *testing.T) {
+	// Save default rand.Reader
+	random := rand.Reader
+	// Failure to generate UUID
+	rand.Reader = bytes.NewReader(nil)
+	_, err := NewProviderWithKeyURL(ES256K, "")
+	if err == nil {
+		t.Error("NewProviderWithKeyURL() should fail when no key ID could be generated but did not")
+	}
+
+	// Failure to generate key
+	b16 := [16]byte{0x00}
+	rand.Reader = bytes.NewReader(b16[:])
+	_, err = NewProviderWithKeyURL(ES256K, "")
+	if err == nil {
+		t.Error("NewProviderWithKeyURL() should fail when no key could be generated but did not")
+	}
+	// Restore default rand.Reader
+	rand.Reader = random
+
+	// Invalid type
+	_, err = NewProviderWithKeyURL(12, "")
+	if err == nil {
+		t.Error("NewProviderWithKeyURL() should fail with unknown algorithm type but did not")
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	type args struct {
+		s Settings
+		t Algorithm
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Provider
+		wantErr bool
+	}{
+		{"Normal", args{Settings{priv, "key_id", "", nil}, ES256K}, Provider{Settings{priv, "key_id", "", nil}, map[string]*ecdsa.PublicKey{"key_id": &priv.PublicKey, "": &priv.PublicKey}, nil, nil, make(map[string]publickey.Validity)}, false},
+		{"No key", args{Settings{kid: "key_id"}, ES256K}, Provider{}, true},
+		{"Unknown type", args{Settings{priv, "key_id", "", nil}, 12}, Provider{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadProvider(tt.args.s, tt.args.t)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadProvider() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_Header(t *testing.T) {
+	h := jwt.Header{Typ: "JWT"}
+	Provider{}.Header(&h)
+	if h.Alg != "ES256K" {
+		t.Errorf("Provider.Header() should set Alg to \"ES256K\" but is %q", h.Alg)
+	}
+	Provider{settings: Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
+	if h.Kid != "key_id" || h.Jku != "key_url" {
+		t.Errorf("Provider.Header() should set Kid and Jku but header is %+v", h)
+	}
+}
+
+// halfOrder is the largest value of s accepted for signatures
+var halfOrder = new(big.Int).Rsh(curve.Params().N, 1)
+
+func TestProvider_Sign(t *testing.T) {
+	p := Provider{settings: Settings{private: priv}}
+
+	// Signing with a private key does not need any random data
+	random := rand.Reader
+	rand.Reader = bytes.NewReader(nil)
+	a, err := p.Sign([]byte("test"))
+	rand.Reader = random
+	if err != nil {
+		t.Fatalf("Sign() failed without random data: %s", err.Error())
+	}
+	if len(a) != 64 {
+		t.Fatalf("Sign() should return byte slice of length 64 but was %d", len(a))
+	}
+	if b, _ := p.Sign([]byte("test")); !bytes.Equal(a, b) {
+		t.Error("Sign() returned different signatures for the same content")
+	}
+
+	// Roughly half of all signatures have a high S before normalization
+	for i := 0; i < 32; i++ {
+		c := []byte{byte(i)}
+		sig, err := p.Sign(c)
+		if err != nil {
+			t.Fatalf("Sign() failed: %s", err.Error())
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if s.Cmp(halfOrder) > 0 {
+			t.Fatal("Sign() returned signature with high S")
+		}
+		sum := sha256.Sum256(c)
+		if !ecdsa.Verify(&pub, sum[:], r, s) {
+			t.Fatal("Signature could not be verified using crypto/ecdsa")
+		}
+	}
+
+	if _, err := (Provider{}).Sign([]byte("test")); err == nil {
+		t.Error("Sign() should fail without a key")
+	}
+}
+
+// Test vector for the private key 1 used by several Bitcoin libraries to test RFC 6979
+func TestProvider_SignDeterministic(t *testing.T) {
+	key := secp256k1.PrivKeyFromBytes([]byte{0x01}).ToECDSA()
+	p := Provider{settings: Settings{private: key}}
+	sig, err := p.Sign([]byte("Satoshi Nakamoto"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	want, _ := hex.DecodeString("934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5")
+	if !bytes.Equal(sig, want) {
+		t.Errorf("Sign() = %x, want %x", sig, want)
+	}
+}
+
+func TestProvider_Verify(t *testing.T) {
+	p := Provider{settings: Settings{private: priv}, keys: map[string]*ecdsa.PublicKey{"key_id": &pub}}
+	sig, err := p.Sign([]byte("test"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	if err := p.Verify([]byte("test"), sig, jwt.Header{Kid: "key_id"}); err != nil {
+		t.Errorf("Verify() failed for valid signature: %s", err.Error())
+	}
+	if p.Verify([]byte("test"), sig[:63], jwt.Header{Kid: "key_id"}) == nil {
+		t.Error("Verify() did not return an error when signature has wrong length")
+	}
+	if p.Verify([]byte("test"), sig, jwt.Header{Kid: "unknown"}) == nil {
+		t.Error("Verify() did not return an error when encountering an unknown key ID")
+	}
+	if p.Verify([]byte("other"), sig, jwt.Header{Kid: "key_id"}) == nil {
+		t.Error("Verify() did not return an error when encountering a wrong signature")
+	}
+	high := append(append([]byte(nil), sig[:32]...), make([]byte, 32)...)
+	hs := new(big.Int).Sub(curve.Params().N, new(big.Int).SetBytes(sig[32:])).Bytes()
+	copy(high[64-len(hs):], hs)
+	if p.Verify([]byte("test"), high, jwt.Header{Kid: "key_id"}) == nil {
+		t.Error("Verify() did not return an error for signature with high S")
+	}
+}
+
+func Test_inRange(t *testing.T) {
+	n := curve.Params().N
+	tests := []struct {
+		name string
+		n    *big.Int
+		want bool
+	}{
+		{"Zero", big.NewInt(0), false},
+		{"One", big.NewInt(1), true},
+		{"Order", n, false},
+		{"Order minus one", new(big.Int).Sub(n, big.NewInt(1)), true},
+		{"Negative", big.NewInt(-1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inRange(tt.n); got != tt.want {
+				t.Errorf("inRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_SetRandom(t *testing.T) {
+	if _, err := NewProviderWithRandom(ES256K, "key_url", bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	// Scalars that are not valid private keys are discarded
+	random := append(make([]byte, 16), bytes.Repeat([]byte{0xff}, 32)...)
+	random = append(random, make([]byte, 32)...)
+	random = append(random, bytes.Repeat([]byte{0x01}, 32)...)
+	p, err := NewProviderWithRandom(ES256K, "key_url", bytes.NewReader(random))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	if p.settings.private.D.Cmp(new(big.Int).SetBytes(bytes.Repeat([]byte{0x01}, 32))) != 0 {
+		t.Errorf("NewProviderWithRandom() generated key %x, want the first valid scalar", p.settings.private.D)
+	}
+
+	key, err := generateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generateKey() failed: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	s, err := NewSettingsFromSigner(signer, "key_id")
+	if err != nil {
+		t.Fatalf("NewSettingsFromSigner() failed: %s", err.Error())
+	}
+	ps, err := LoadProvider(s, ES256K)
+	if err != nil {
+		t.Fatalf("LoadProvider() failed: %s", err.Error())
+	}
+	ps.SetRandom(bytes.NewReader(nil))
+	if _, err := ps.Sign([]byte("test")); err == nil {
+		t.Error("Sign() did not pass the random source set by SetRandom() to the signer")
+	}
+	ps.SetRandom(nil)
+	if _, err := ps.Sign([]byte("test")); err != nil {
+		t.Errorf("Sign() failed after resetting the random source: %s", err.Error())
+	}
+}
@@ -0,0 +1,164 @@
+package es256k
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestNewSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		want    Settings
+		wantErr bool
+	}{
+		{"EC key", sec1, Settings{priv, "key_id", "", nil}, false},
+		{"PKCS8 key", pkcs8Key, Settings{priv, "key_id", "", nil}, false},
+		{"EC key P-256", sec1P256, Settings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettings(tt.key, "key_id")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSettings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSettingsWithKeyURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		want    Settings
+		wantErr bool
+	}{
+		{"EC key", sec1, Settings{priv, "key_id", "key_url", nil}, false},
+		{"PKCS8 key", pkcs8Key, Settings{priv, "key_id", "key_url", nil}, false},
+		{"Invalid", []byte("invalid key"), Settings{}, true},
+		{"PKCS8 RSA key", pkcs8RSA, Settings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettingsWithKeyURL(tt.key, "key_id", "key_url")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettingsWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSettingsWithKeyURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_AddPublicKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       *Provider
+		key     publickey.PublicKey
+		wantErr bool
+	}{
+		{"Normal", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, publickey.New(pkixKey, "key_id"), false},
+		{"Compressed", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, publickey.New(pkixCompressed, "key_id"), false},
+		{"Already exists", &Provider{keys: map[string]*ecdsa.PublicKey{"key_id": &pub}}, publickey.New(pkixKey, "key_id"), true},
+		{"Invalid", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, publickey.New([]byte("invalid key"), "key_id"), true},
+		{"P-256 key", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, publickey.New(pkixP256, "key_id"), true},
+		{"Matching metadata", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, publickey.New(pkixKey, "key_id").WithAlgorithm("ES256K").WithKeyType("EC"), false},
+		{"Other algorithm", &Provider{keys: make(map[string]*ecdsa.PublicKey)}, publickey.New(pkixKey, "key_id").WithAlgorithm("ES256"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.AddPublicKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("Provider.AddPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProvider_RemovePublicKey(t *testing.T) {
+	p := &Provider{settings: Settings{kid: "signing_key"}, keys: map[string]*ecdsa.PublicKey{"signing_key": &pub, "key_id": &pub}}
+	p.RemovePublicKey("key_id")
+	p.RemovePublicKey("signing_key")
+	if _, ok := p.keys["key_id"]; ok {
+		t.Error("Provider.RemovePublicKey() did not remove the key")
+	}
+	if _, ok := p.keys["signing_key"]; !ok {
+		t.Error("Provider.RemovePublicKey() should not remove the signing key")
+	}
+}
+
+func TestProvider_CurrentKey(t *testing.T) {
+	p := Provider{settings: Settings{private: priv, kid: "key_id"}}
+	if got, want := p.CurrentKey(), publickey.New(pkixKey, "key_id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Provider.CurrentKey() = %v, want %v", got, want)
+	}
+}
+
+// testSigner stands in for an agent or a KMS client. The private key is only reachable through the sign function so it cannot end up in the provider.
+type testSigner struct {
+	public crypto.PublicKey
+	sign   func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error)
+	calls  int
+}
+
+func newTestSigner(key crypto.Signer) *testSigner {
+	return &testSigner{public: key.Public(), sign: key.Sign}
+}
+
+func (s *testSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *testSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	return s.sign(rand, digest, opts)
+}
+
+func TestNewSettingsFromSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	wrong, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		want    Settings
+		wantErr bool
+	}{
+		{"Signer", signer, Settings{nil, "key_id", "key_url", signer}, false},
+		{"Wrong curve", newTestSigner(wrong), Settings{}, true},
+		{"No signer", nil, Settings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettingsFromSignerWithKeyURL(tt.signer, "key_id", "key_url")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got, err := NewSettingsFromSigner(signer, "key_id"); err != nil || got.signer != signer || got.kid != "key_id" || got.jku != "" {
+		t.Errorf("NewSettingsFromSigner() = %v, %v", got, err)
+	}
+}
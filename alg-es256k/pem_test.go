@@ -0,0 +1,111 @@
+package es256k
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestSettings_ExportPEM(t *testing.T) {
+	p, err := LoadProvider(Settings{priv, "key_id", "key_url", nil}, ES256K)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	enc, err := p.Settings().ExportPEM()
+	if err != nil {
+		t.Fatalf("Settings.ExportPEM() failed: %s", err.Error())
+	}
+	s, err := NewSettingsFromPEM(enc)
+	if err != nil {
+		t.Fatalf("NewSettingsFromPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettingsFromPEM() = %+v, want %+v", s, p.Settings())
+	}
+	if _, err := (Settings{}).ExportPEM(); err == nil {
+		t.Error("Settings.ExportPEM() should fail without private key")
+	}
+}
+
+func TestNewSettingsFromPEM(t *testing.T) {
+	s, err := NewSettingsFromPEM(pemutil.Encode("EC PRIVATE KEY", sec1, "key_id", ""))
+	if err != nil || !reflect.DeepEqual(s, Settings{priv, "key_id", "", nil}) {
+		t.Errorf("NewSettingsFromPEM() = %+v, %v, want settings for EC private key", s, err)
+	}
+	if _, err := NewSettingsFromPEM([]byte("no PEM block")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail when there is no PEM block")
+	}
+	if _, err := NewSettingsFromPEM(pemutil.Encode("CERTIFICATE", []byte("test"), "", "")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail for unsupported block type")
+	}
+	if _, err := NewSettingsFromPEM(pemutil.Encode("PRIVATE KEY", []byte("test"), "", "")); err == nil {
+		t.Error("NewSettingsFromPEM() should fail for invalid key")
+	}
+}
+
+func TestEncodePublicKeyPEM(t *testing.T) {
+	key := publickey.New(pkixKey, "key_id")
+	enc, err := EncodePublicKeyPEM(key)
+	if err != nil {
+		t.Fatalf("EncodePublicKeyPEM() failed: %s", err.Error())
+	}
+	got, err := DecodePublicKeyPEM(enc)
+	if err != nil {
+		t.Fatalf("DecodePublicKeyPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(got, key) {
+		t.Errorf("DecodePublicKeyPEM() = %v, want %v", got, key)
+	}
+	if _, err := EncodePublicKeyPEM(publickey.New(pkixP256, "")); err == nil {
+		t.Error("EncodePublicKeyPEM() should fail for key using another curve")
+	}
+	if _, err := DecodePublicKeyPEM([]byte("no PEM block")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail when there is no PEM block")
+	}
+	if _, err := DecodePublicKeyPEM(pemutil.Encode("CERTIFICATE", []byte("test"), "", "")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail for unsupported block type")
+	}
+	if _, err := DecodePublicKeyPEM(pemutil.Encode("PUBLIC KEY", []byte("test"), "", "")); err == nil {
+		t.Error("DecodePublicKeyPEM() should fail for invalid key")
+	}
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+}
+
+// The handling of the directory itself is tested in internal/pemutil
+func TestLoadProviderFromDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		t.Fatalf("Could not create directory: %s", err.Error())
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	writeFile(t, dir, "signing.pem", pemutil.Encode("EC PRIVATE KEY", sec1, "signing", ""))
+	writeFile(t, dir, "signing_public.pem", pemutil.Encode("PUBLIC KEY", pkixKey, "signing", ""))
+	writeFile(t, dir, "other.pem", pemutil.Encode("PUBLIC KEY", pkixCompressed, "other", ""))
+
+	p, err := LoadProviderFromDir(dir, ES256K)
+	if err != nil {
+		t.Fatalf("LoadProviderFromDir() failed: %s", err.Error())
+	}
+	if want := publickey.New(pkixKey, "signing"); !reflect.DeepEqual(p.CurrentKey(), want) {
+		t.Errorf("LoadProviderFromDir() loaded signing key %v, want %v", p.CurrentKey(), want)
+	}
+	if _, ok := p.keys["other"]; !ok {
+		t.Error("LoadProviderFromDir() did not add public key")
+	}
+
+	writeFile(t, dir, "signing.pem", pemutil.Encode("PRIVATE KEY", []byte("test"), "", ""))
+	if _, err := LoadProviderFromDir(dir, ES256K); err == nil {
+		t.Error("LoadProviderFromDir() should fail for invalid private key")
+	}
+}
@@ -0,0 +1,62 @@
+package hs
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// derivedKeyIDSize is the number of bytes derived for the key ID before encoding it as base64url
+const derivedKeyIDSize = 16
+
+// NewSettingsFromMasterSecret derives the key for the algorithm from a master secret using HKDF (RFC 5869) with the hash function of the algorithm and no salt.
+// info distinguishes the keys derived from the same master secret, e.g. by containing the audience. The key ID is derived as well
+// so every party knowing the master secret and info computes the same key and key ID. The master secret has to satisfy the key size policy of the algorithm.
+func NewSettingsFromMasterSecret(master, info []byte, t Algorithm) (Settings, error) {
+	return NewSettingsFromMasterSecretWithKeyURL(master, info, "", t)
+}
+
+// NewSettingsFromMasterSecretWithKeyURL works just like NewSettingsFromMasterSecret but also sets the key URL
+func NewSettingsFromMasterSecretWithKeyURL(master, info []byte, keyURL string, t Algorithm) (Settings, error) {
+	h := hashFunc(t)
+	if h == nil {
+		return Settings{}, errors.New("invalid algorithm ID")
+	}
+	if len(master) == 0 {
+		return Settings{}, errors.New("empty master secrets are not allowed")
+	}
+	if err := checkKeySize(master, t); err != nil {
+		return Settings{}, err
+	}
+	key := make([]byte, h().Size())
+	if _, err := io.ReadFull(hkdf.New(h, master, nil, derivationInfo(t, "key", info)), key); err != nil {
+		return Settings{}, err
+	}
+	kid := make([]byte, derivedKeyIDSize)
+	if _, err := io.ReadFull(hkdf.New(h, master, nil, derivationInfo(t, "kid", info)), kid); err != nil {
+		return Settings{}, err
+	}
+	return Settings{key, base64.RawURLEncoding.EncodeToString(kid), keyURL}, nil
+}
+
+// NewProviderFromMasterSecret returns a Provider using a key derived from the master secret as described for NewSettingsFromMasterSecret
+func NewProviderFromMasterSecret(t Algorithm, master, info []byte) (Provider, error) {
+	s, err := NewSettingsFromMasterSecret(master, info, t)
+	if err != nil {
+		return Provider{}, err
+	}
+	return LoadProvider(s, t)
+}
+
+// derivationInfo returns the HKDF info for the purpose which is the algorithm name, the purpose and the supplied info separated by zero bytes
+func derivationInfo(t Algorithm, purpose string, info []byte) []byte {
+	alg := algToString(t)
+	b := make([]byte, 0, len(alg)+len(purpose)+len(info)+2)
+	b = append(b, alg...)
+	b = append(b, 0x00)
+	b = append(b, purpose...)
+	b = append(b, 0x00)
+	return append(b, info...)
+}
@@ -0,0 +1,94 @@
+package hs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func TestNewSettingsFromMasterSecret(t *testing.T) {
+	master := make([]byte, 64)
+	for i := range master {
+		master[i] = byte(i)
+	}
+	tests := []struct {
+		name string
+		alg  Algorithm
+		key  string
+		kid  string
+	}{
+		{"HS256", HS256, "74f84a7fca2118dcf0ce04fec290f33f25c43ef7f96e0a295d7b90f5526326c2", "HyfwktzfHdhcZbs86q33XQ"},
+		{"HS512", HS512, "ea9c7ed70231408495f13c0b32038f61d53148f5ee87a86b7cbf0d7c42d7514bf031a2a45685140a74e118c69f1e1f8597156a737c9abead9481b7ee64fb343b", "k6_dT-zGqRC3VacUHGf0Yg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSettingsFromMasterSecretWithKeyURL(master, []byte("api.example.com"), "key_url", tt.alg)
+			if err != nil {
+				t.Fatalf("NewSettingsFromMasterSecretWithKeyURL() failed: %s", err.Error())
+			}
+			if hex.EncodeToString(s.key) != tt.key {
+				t.Errorf("NewSettingsFromMasterSecretWithKeyURL() derived key %x, want %s", s.key, tt.key)
+			}
+			if s.kid != tt.kid {
+				t.Errorf("NewSettingsFromMasterSecretWithKeyURL() derived key ID %q, want %q", s.kid, tt.kid)
+			}
+			if s.jku != "key_url" {
+				t.Errorf("NewSettingsFromMasterSecretWithKeyURL() did not set the key URL")
+			}
+		})
+	}
+
+	a, _ := NewSettingsFromMasterSecret(master, []byte("a.example.com"), HS256)
+	b, _ := NewSettingsFromMasterSecret(master, []byte("b.example.com"), HS256)
+	if bytes.Equal(a.key, b.key) || a.kid == b.kid {
+		t.Error("NewSettingsFromMasterSecret() derived the same key for different info")
+	}
+	c, _ := NewSettingsFromMasterSecret(master, []byte("a.example.com"), HS384)
+	if bytes.Equal(a.key, c.key[:len(a.key)]) || a.kid == c.kid {
+		t.Error("NewSettingsFromMasterSecret() derived the same key for different algorithms")
+	}
+
+	if _, err := NewSettingsFromMasterSecret(nil, nil, HS256); err == nil {
+		t.Error("NewSettingsFromMasterSecret() should fail for empty master secrets")
+	}
+	if _, err := NewSettingsFromMasterSecret(master[:32], nil, HS512); err == nil {
+		t.Error("NewSettingsFromMasterSecret() should fail for master secrets that are too short for the algorithm")
+	}
+	if _, err := NewSettingsFromMasterSecret(master, nil, 12); err == nil {
+		t.Error("NewSettingsFromMasterSecret() should fail for unknown algorithms")
+	}
+}
+
+func TestNewProviderFromMasterSecret(t *testing.T) {
+	master := bytes.Repeat([]byte("master"), 8)
+	signer, err := NewProviderFromMasterSecret(HS256, master, []byte("audience"))
+	if err != nil {
+		t.Fatalf("NewProviderFromMasterSecret() failed: %s", err.Error())
+	}
+	verifier, err := NewProviderFromMasterSecret(HS256, master, []byte("audience"))
+	if err != nil {
+		t.Fatalf("NewProviderFromMasterSecret() failed: %s", err.Error())
+	}
+	other, err := NewProviderFromMasterSecret(HS256, master, []byte("other audience"))
+	if err != nil {
+		t.Fatalf("NewProviderFromMasterSecret() failed: %s", err.Error())
+	}
+
+	var h jwt.Header
+	signer.Header(&h)
+	sig, err := signer.Sign([]byte("content"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	if err := verifier.Verify([]byte("content"), sig, h); err != nil {
+		t.Errorf("Verify() failed for a provider derived from the same master secret and info: %s", err.Error())
+	}
+	if err := other.Verify([]byte("content"), sig, h); err == nil {
+		t.Error("Verify() succeeded for a provider derived using different info")
+	}
+	if _, err := NewProviderFromMasterSecret(HS256, nil, nil); err == nil {
+		t.Error("NewProviderFromMasterSecret() should fail for empty master secrets")
+	}
+}
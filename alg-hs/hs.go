@@ -7,23 +7,28 @@ import (
 	"crypto/sha512"
 	"errors"
 	"hash"
+	"io"
 
 	"github.com/fossoreslp/go-jwt"
-	"github.com/fossoreslp/go-uuid-v4"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
+// Algorithm identifies one of the HMAC-SHA2 signature algorithms
+type Algorithm int
+
 const (
 	// HS256 is HMAC-SHA256
-	HS256 = 1
+	HS256 Algorithm = 1
 
 	// HS384 is HMAC-SHA384
-	HS384 = 2
+	HS384 Algorithm = 2
 
 	// HS512 is HMAC-SHA512
-	HS512 = 3
+	HS512 Algorithm = 3
 )
 
-func algToString(alg int) string {
+func algToString(alg Algorithm) string {
 	switch alg {
 	case HS256:
 		return "HS256"
@@ -36,71 +41,85 @@ func algToString(alg int) string {
 	}
 }
 
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
 // Provider provides HMAC-SHA2 JWS signing and verification
 type Provider struct {
-	alg      int
-	hmac     hash.Hash
-	settings Settings
+	alg      Algorithm
+	settings *Settings // Signing secret shared by all copies of the provider and guarded by keysMu
 	keys     map[string][]byte
+	resolver jwt.KeyResolver
+	validity map[string]publickey.Validity
 }
 
+var _ jwt.KeyedSignatureProvider = (*Provider)(nil)
+
 // NewProvider creates a new Provider generating the necessary keypairs
-func NewProvider(t int) (Provider, error) {
+func NewProvider(t Algorithm) (Provider, error) {
 	return NewProviderWithKeyURL(t, "")
 }
 
 // NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
-func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
-	kid, err := uuid.NewString()
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key and the key ID from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	kid, err := keyid.New(source)
 	if err != nil {
 		return Provider{}, err
 	}
 	var c int
-	var alg int
-	var h func() hash.Hash
+	var alg Algorithm
 	switch t {
 	case HS256:
 		c = 32
 		alg = HS256
-		h = sha256.New
 	case HS384:
 		c = 48
 		alg = HS384
-		h = sha512.New384
 	case HS512:
 		c = 64
 		alg = HS512
-		h = sha512.New
 	default:
 		return Provider{}, errors.New("invalid algorithm ID")
 	}
+	if min := requiredKeySize(alg); min > c {
+		c = min
+	}
 	k := make([]byte, c)
-	_, err = rand.Read(k)
-	if err != nil {
+	if _, err := io.ReadFull(source, k); err != nil {
 		return Provider{}, err
 	}
 	m := map[string][]byte{
 		kid: k,
 		"":  k,
 	}
-	return Provider{alg, hmac.New(h, k), Settings{k, kid, keyURL}, m}, nil
+	return Provider{alg, &Settings{k, kid, keyURL}, m, nil, make(map[string]publickey.Validity)}, nil
 }
 
 // LoadProvider returns a Provider using the supplied keypairs
-func LoadProvider(s Settings, t int) (Provider, error) {
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if algToString(t) == "" {
+		return Provider{}, errors.New("invalid algorithm ID")
+	}
+	if err := checkKeySize(s.key, t); err != nil {
+		return Provider{}, err
+	}
 	m := map[string][]byte{
 		s.kid: s.key,
 		"":    s.key,
 	}
-	switch t {
-	case HS256:
-		return Provider{HS256, hmac.New(sha256.New, s.key), s, m}, nil
-	case HS384:
-		return Provider{HS384, hmac.New(sha512.New384, s.key), s, m}, nil
-	case HS512:
-		return Provider{HS512, hmac.New(sha512.New, s.key), s, m}, nil
-	}
-	return Provider{}, errors.New("invalid algorithm ID")
+	return Provider{t, &s, m, nil, make(map[string]publickey.Validity)}, nil
 }
 
 func getMAC(mac hash.Hash, in []byte) []byte {
@@ -110,20 +129,39 @@ func getMAC(mac hash.Hash, in []byte) []byte {
 	return mac.Sum(nil)
 }
 
+// signing returns the settings currently used for signing
+func (p Provider) signing() Settings {
+	if p.settings == nil {
+		return Settings{}
+	}
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return *p.settings
+}
+
 // Header sets the necessary JWT header fields
 func (p Provider) Header(h *jwt.Header) {
+	s := p.signing()
 	h.Alg = algToString(p.alg)
-	if p.settings.kid != "" {
-		h.Kid = p.settings.kid
+	if s.kid != "" {
+		h.Kid = s.kid
 	}
-	if p.settings.jku != "" {
-		h.Jku = p.settings.jku
+	if s.jku != "" {
+		h.Jku = s.jku
 	}
 }
 
 // Sign signs the content of a JWT
 func (p Provider) Sign(c []byte) ([]byte, error) {
-	return getMAC(p.hmac, c), nil
+	h := hashFunc(p.alg)
+	if h == nil {
+		return nil, errors.New("invalid algorithm ID")
+	}
+	s := p.signing()
+	if err := p.checkValidity(s.kid); err != nil {
+		return nil, err
+	}
+	return getMAC(hmac.New(h, s.key), c), nil
 }
 
 // Verify verifies if the content matches it's signature.
@@ -139,9 +177,23 @@ func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
 	default:
 		return errors.New("invalid algorithm")
 	}
+	keysMu.RLock()
 	pub, ok := p.keys[h.Kid]
+	keysMu.RUnlock()
 	if !ok {
-		return errors.New("unknown key id")
+		keys, err := p.resolveKeys(data, h)
+		if err != nil {
+			return err
+		}
+		for _, pub := range keys {
+			if hmac.Equal(sig, getMAC(hmac.New(hashFunc, pub), data)) {
+				return nil
+			}
+		}
+		return errors.New("signature invalid")
+	}
+	if err := p.checkValidity(h.Kid); err != nil {
+		return err
 	}
 	expectedMAC := getMAC(hmac.New(hashFunc, pub), data)
 	if hmac.Equal(sig, expectedMAC) {
@@ -1,6 +1,8 @@
 package hs
 
 import (
+	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
@@ -9,7 +11,7 @@ import (
 func Test_algToString(t *testing.T) {
 	tests := []struct {
 		name string
-		alg  int
+		alg  Algorithm
 		want string
 	}{
 		{"HS256", HS256, "HS256"},
@@ -53,3 +55,21 @@ func TestProvider_Verify(t *testing.T) {
 		})
 	}
 }
+
+func TestNewProviderWithRandom(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 16+64)
+	a, err := NewProviderWithRandom(HS512, "key_url", bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	b, err := NewProviderWithRandom(HS512, "key_url", bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(a.settings, b.settings) {
+		t.Errorf("NewProviderWithRandom() generated different keys from the same random data: %v and %v", a.settings, b.settings)
+	}
+	if _, err := NewProviderWithRandom(HS512, "key_url", bytes.NewReader(seed[:16])); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/keyresolver"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
 func TestHS256(t *testing.T) {
@@ -20,7 +24,9 @@ func TestHS256(t *testing.T) {
 	bk := make([]byte, base64.StdEncoding.EncodedLen(len(rk.GetPublicKey())))
 	base64.StdEncoding.Encode(bk, rk.GetPublicKey())
 	t.Logf("Created provider with key: %s", string(bk))
-	jwt.SetSignatureProvider(algToString(HS256), p)
+	if err := jwt.SetSignatureProvider(algToString(HS256), p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm(algToString(HS256)) // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -46,7 +52,9 @@ func TestHS384(t *testing.T) {
 	bk := make([]byte, base64.StdEncoding.EncodedLen(len(rk.GetPublicKey())))
 	base64.StdEncoding.Encode(bk, rk.GetPublicKey())
 	t.Logf("Created provider with key: %s", string(bk))
-	jwt.SetSignatureProvider(algToString(HS384), p)
+	if err := jwt.SetSignatureProvider(algToString(HS384), p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm(algToString(HS384)) // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -72,7 +80,9 @@ func TestHS512(t *testing.T) {
 	bk := make([]byte, base64.StdEncoding.EncodedLen(len(rk.GetPublicKey())))
 	base64.StdEncoding.Encode(bk, rk.GetPublicKey())
 	t.Logf("Created provider with key: %s", string(bk))
-	jwt.SetSignatureProvider(algToString(HS512), p)
+	if err := jwt.SetSignatureProvider(algToString(HS512), p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm(algToString(HS512)) // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -91,7 +101,7 @@ func TestHS512(t *testing.T) {
 
 func TestHeader(t *testing.T) {
 	h := jwt.Header{Typ: "JWT"}
-	Provider{alg: HS256, settings: Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
+	Provider{alg: HS256, settings: &Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
 	if h.Alg != "HS256" {
 		t.Errorf("HS256Provider.Header() should set Alg to \"HS256\" but instead it is %q", h.Alg)
 	}
@@ -103,7 +113,7 @@ func TestHeader(t *testing.T) {
 	}
 
 	h = jwt.Header{Typ: "JWT"}
-	Provider{alg: HS384, settings: Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
+	Provider{alg: HS384, settings: &Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
 	if h.Alg != "HS384" {
 		t.Errorf("HS384Provider.Header() should set Alg to \"HS384\" but instead it is %q", h.Alg)
 	}
@@ -115,7 +125,7 @@ func TestHeader(t *testing.T) {
 	}
 
 	h = jwt.Header{Typ: "JWT"}
-	Provider{alg: HS512, settings: Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
+	Provider{alg: HS512, settings: &Settings{kid: "key_id", jku: "key_url"}}.Header(&h)
 	if h.Alg != "HS512" {
 		t.Errorf("HS512Provider.Header() should set Alg to \"HS512\" but instead it is %q", h.Alg)
 	}
@@ -129,7 +139,7 @@ func TestHeader(t *testing.T) {
 }
 
 func TestLoadProvider(t *testing.T) {
-	k, _ := LoadProvider(Settings{kid: "key_id"}, HS256)
+	k, _ := LoadProvider(Settings{key: make([]byte, 32), kid: "key_id"}, HS256)
 	if k.alg != HS256 {
 		t.Errorf("LoadProvider() did not return a HS256 provider but %d", k.alg)
 	}
@@ -137,7 +147,7 @@ func TestLoadProvider(t *testing.T) {
 		t.Errorf("LoadProvider() did not pass the data from the input keyset onto the provider")
 	}
 
-	k, _ = LoadProvider(Settings{kid: "key_id"}, HS384)
+	k, _ = LoadProvider(Settings{key: make([]byte, 48), kid: "key_id"}, HS384)
 	if k.alg != HS384 {
 		t.Errorf("LoadProvider() did not return a HS384 provider but %d", k.alg)
 	}
@@ -145,7 +155,7 @@ func TestLoadProvider(t *testing.T) {
 		t.Errorf("LoadProvider() did not pass the data from the input keyset onto the provider")
 	}
 
-	k, _ = LoadProvider(Settings{kid: "key_id"}, HS512)
+	k, _ = LoadProvider(Settings{key: make([]byte, 64), kid: "key_id"}, HS512)
 	if k.alg != HS512 {
 		t.Errorf("LoadProvider() did not return a HS512 provider but %d", k.alg)
 	}
@@ -179,8 +189,119 @@ func TestInvalidRandomGenerator(t *testing.T) {
 }
 
 func TestInvalidSignature(t *testing.T) {
-	p := Provider{hmac: hmac.New(sha256.New, []byte("key"))}
+	p := Provider{}
 	if p.Verify([]byte("test"), []byte("signature"), jwt.Header{}) == nil {
 		t.Error("Provider.Verify() should fail with invalid signature")
 	}
 }
+
+func TestKeyResolver(t *testing.T) {
+	signer, err := NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	data := []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJIUzI1NiJ9.eyJpc3MiOiJ0ZXN0In0")
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatalf("Could not sign data: %s", err.Error())
+	}
+	h := jwt.Header{Typ: "JWT", Alg: "HS256", Kid: signer.CurrentKey().GetKeyID()}
+	if verifier.Verify(data, sig, h) == nil {
+		t.Error("Provider.Verify() should fail for unknown key ID without a key resolver")
+	}
+	verifier.SetKeyResolver(keyresolver.NewStaticResolver(verifier.CurrentKey()))
+	if verifier.Verify(data, sig, h) == nil {
+		t.Error("Provider.Verify() should fail when the resolver does not know the key")
+	}
+	verifier.SetKeyResolver(keyresolver.NewStaticResolver(signer.CurrentKey()))
+	if verifier.Verify(data, sig, h) == nil {
+		t.Error("Provider.Verify() should fail when the resolved key is not marked as symmetric key")
+	}
+	verifier.SetKeyResolver(keyresolver.NewStaticResolver(signer.CurrentKey().WithKeyType("RSA")))
+	if verifier.Verify(data, sig, h) == nil {
+		t.Error("Provider.Verify() should fail when the resolved key is an asymmetric key")
+	}
+	verifier.SetKeyResolver(keyresolver.NewStaticResolver(signer.CurrentKey().WithKeyType("oct")))
+	if err := verifier.Verify(data, sig, h); err != nil {
+		t.Errorf("Provider.Verify() failed using key from resolver: %s", err.Error())
+	}
+	if verifier.Verify(data, []byte("invalid signature"), h) == nil {
+		t.Error("Provider.Verify() should fail for invalid signature using key from resolver")
+	}
+}
+
+func TestUnencodedPayload(t *testing.T) {
+	// Example from RFC 7797 section 4.2
+	key, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	p, err := LoadProvider(Settings{key, "", ""}, HS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString("A5dxf2s96_n5FLueVuW1Z_vh161FwXZC4YLPff6dmDY")
+	data := []byte("eyJhbGciOiJIUzI1NiIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19.$.02")
+	if err := p.Verify(data, sig, jwt.Header{Alg: "HS256"}); err != nil {
+		t.Errorf("Provider.Verify() failed for unencoded payload: %s", err.Error())
+	}
+}
+
+// validityToken creates a token signed using HS256 and the key with the key ID
+func validityToken(key []byte, keyID string) []byte {
+	header, _ := json.Marshal(jwt.Header{Typ: "JWT", Alg: "HS256", Kid: keyID})
+	data := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"test"}`))
+	return []byte(data + "." + base64.RawURLEncoding.EncodeToString(getMAC(hmac.New(sha256.New, key), []byte(data))))
+}
+
+func TestKeyValidity(t *testing.T) {
+	p, err := NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	// The registry stores a copy of the provider which has to see keys and validity periods added to p later on
+	if err := jwt.SetSignatureProvider("HS256", p); err != nil {
+		t.Fatalf("Could not register provider: %s", err.Error())
+	}
+	defer jwt.RemoveSignatureProvider("HS256")
+	now := time.Now()
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		expires   time.Time
+		wantErr   bool
+	}{
+		{"Unrestricted", time.Time{}, time.Time{}, false},
+		{"Valid", now.Add(-time.Hour), now.Add(time.Hour), false},
+		{"Not valid yet", now.Add(time.Hour), time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Keys that are not valid yet are accepted when added but rejected when verifying
+			if err := p.AddPublicKey(publickey.New(testKey, tt.name).WithValidity(tt.notBefore, tt.expires)); err != nil {
+				t.Fatalf("Provider.AddPublicKey() error = %v", err)
+			}
+			token, err := jwt.Decode(validityToken(testKey, tt.name))
+			if err != nil {
+				t.Fatalf("jwt.Decode() failed: %s", err.Error())
+			}
+			if (token.ValidationError() != nil) != tt.wantErr {
+				t.Errorf("jwt.Decode() validation error = %v, wantErr %v", token.ValidationError(), tt.wantErr)
+			}
+		})
+	}
+
+	// Secrets expiring after they were added are rejected by the registered copy as well
+	s, _ := NewSecretWithExpiry(testKey, "expiring", time.Now().Add(50*time.Millisecond))
+	if err := p.AddSecret(s); err != nil {
+		t.Fatalf("Provider.AddSecret() failed: %s", err.Error())
+	}
+	if token, _ := jwt.Decode(validityToken(testKey, "expiring")); !token.Valid() {
+		t.Errorf("Registered provider rejected a secret that did not expire yet: %v", token.ValidationError())
+	}
+	time.Sleep(100 * time.Millisecond)
+	if token, _ := jwt.Decode(validityToken(testKey, "expiring")); token.Valid() {
+		t.Error("Registered provider accepted an expired secret")
+	}
+}
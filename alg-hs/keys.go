@@ -2,7 +2,10 @@ package hs
 
 import (
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/fossoreslp/go-jwt"
 	"github.com/fossoreslp/go-jwt/publickey"
 )
 
@@ -23,29 +26,85 @@ func NewSettingsWithKeyURL(key []byte, keyID, keyURL string) (Settings, error) {
 	if len(key) == 0 {
 		return Settings{}, errors.New("empty keys are not allowed")
 	}
+	if err := checkKeySize(key, 0); err != nil {
+		return Settings{}, err
+	}
 	return Settings{key, keyID, keyURL}, nil
 }
 
-// AddPublicKey adds a public key for verification
+// keysMu guards the key maps of all providers so keys can be managed while tokens are being verified
+var keysMu sync.RWMutex
+
+// AddPublicKey adds a key for verification. It works like AddSecret using the validity period of the key if it has one.
 func (p *Provider) AddPublicKey(key publickey.PublicKey) error {
+	if err := key.CheckMetadata(algToString(p.alg), "oct", "sig", "verify"); err != nil {
+		return err
+	}
+	validity := key.GetValidity()
+	if validity.Expired(time.Now()) {
+		return errors.New("key expired")
+	}
 	id := key.GetKeyID()
+	keysMu.Lock()
+	defer keysMu.Unlock()
 	if _, ok := p.keys[id]; ok {
 		return errors.New("key ID already exists")
 	}
+	if err := checkKeySize(key.GetPublicKey(), p.alg); err != nil {
+		return err
+	}
 	p.keys[id] = key.GetPublicKey()
+	p.setValidity(id, validity)
 	return nil
 }
 
+// SetKeyResolver sets a resolver that is consulted for keys when the key ID of a token is unknown to the provider
+func (p *Provider) SetKeyResolver(r jwt.KeyResolver) {
+	p.resolver = r
+}
+
 // RemovePublicKey removes a public key by it's key ID from the verification set
 func (p *Provider) RemovePublicKey(keyid string) {
-	if keyid == p.settings.kid {
+	if keyid == p.signing().kid {
 		return
 	}
+	keysMu.Lock()
 	delete(p.keys, keyid)
+	delete(p.validity, keyid)
+	keysMu.Unlock()
 }
 
 // CurrentKey returns the public key belonging to the private key used for signing.
-// CAUTION: The public and private key are the same for this algorithm. Do not share the key you obtain using this function
+// CAUTION: The public and private key are the same for this algorithm. Do not share the key you obtain using this function.
 func (p Provider) CurrentKey() publickey.PublicKey {
-	return publickey.New(p.settings.key, p.settings.kid)
+	s := p.signing()
+	return publickey.New(s.key, s.kid)
+}
+
+// resolveKeys retrieves the keys that may have been used to sign a token from the key resolver
+func (p Provider) resolveKeys(data []byte, h jwt.Header) ([][]byte, error) {
+	if p.resolver == nil {
+		return nil, errors.New("unknown key id")
+	}
+	c, err := jwt.ContentFromSigningInput(data)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := p.resolver.Resolve(h, c)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		// Only keys explicitly marked as symmetric are used as otherwise a public key of another algorithm could be used as the secret
+		// Keys that do not satisfy the key size policy are skipped
+		// Keys whose metadata does not permit verifying the token are skipped as well
+		if k.GetKeyType() == "oct" && checkKeySize(k.GetPublicKey(), p.alg) == nil && k.CheckUsage(h.Alg, "oct", "sig", "verify") == nil {
+			parsed = append(parsed, k.GetPublicKey())
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("unknown key id")
+	}
+	return parsed, nil
 }
@@ -1,12 +1,16 @@
 package hs
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/fossoreslp/go-jwt/publickey"
 )
 
+var testKey = bytes.Repeat([]byte("test"), 8)
+
 func TestNewSettings(t *testing.T) {
 	type args struct {
 		key   []byte
@@ -18,7 +22,8 @@ func TestNewSettings(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"Normal", args{[]byte("test"), "key_id"}, Settings{[]byte("test"), "key_id", ""}, false},
+		{"Normal", args{testKey, "key_id"}, Settings{testKey, "key_id", ""}, false},
+		{"Short key", args{[]byte("test"), "key_id"}, Settings{}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -46,7 +51,8 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"Normal", args{[]byte("test"), "key_id", "key_url"}, Settings{[]byte("test"), "key_id", "key_url"}, false},
+		{"Normal", args{testKey, "key_id", "key_url"}, Settings{testKey, "key_id", "key_url"}, false},
+		{"Short key", args{[]byte("test"), "key_id", "key_url"}, Settings{}, true},
 		{"Empty key", args{[]byte(""), "key_id", "key_url"}, Settings{}, true},
 		{"Nil key", args{nil, "key_id", "key_url"}, Settings{}, true},
 	}
@@ -71,8 +77,13 @@ func TestProvider_AddPublicKey(t *testing.T) {
 		key     publickey.PublicKey
 		wantErr bool
 	}{
-		{"Normal", &Provider{keys: map[string][]byte{}}, publickey.New([]byte("test"), "key_id"), false},
-		{"Key already exists", &Provider{keys: map[string][]byte{"key_id": []byte("test")}}, publickey.New([]byte("test"), "key_id"), true},
+		{"Normal", &Provider{alg: HS256, keys: map[string][]byte{}}, publickey.New(testKey, "key_id"), false},
+		{"Key already exists", &Provider{alg: HS256, keys: map[string][]byte{"key_id": testKey}}, publickey.New(testKey, "key_id"), true},
+		{"Key too short", &Provider{alg: HS256, keys: map[string][]byte{}}, publickey.New([]byte("test"), "key_id"), true},
+		{"Key too short for algorithm", &Provider{alg: HS512, keys: map[string][]byte{}}, publickey.New(testKey, "key_id"), true},
+		{"Other algorithm", &Provider{alg: HS256, keys: map[string][]byte{}}, publickey.New(testKey, "key_id").WithAlgorithm("HS512"), true},
+		{"Not valid yet", &Provider{alg: HS256, keys: map[string][]byte{}, validity: make(map[string]publickey.Validity)}, publickey.New(testKey, "key_id").WithValidity(time.Now().Add(time.Hour), time.Time{}), false},
+		{"Expired", &Provider{alg: HS256, keys: map[string][]byte{}, validity: make(map[string]publickey.Validity)}, publickey.New(testKey, "key_id").WithValidity(time.Time{}, time.Now().Add(-time.Hour)), true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -89,8 +100,8 @@ func TestProvider_RemovePublicKey(t *testing.T) {
 		p     *Provider
 		keyid string
 	}{
-		{"Normal", &Provider{settings: Settings{kid: "signing_key"}, keys: map[string][]byte{}}, "key_id"},
-		{"Try deleting signing key", &Provider{settings: Settings{kid: "signing_key"}, keys: map[string][]byte{}}, "signing_key"},
+		{"Normal", &Provider{settings: &Settings{kid: "signing_key"}, keys: map[string][]byte{}}, "key_id"},
+		{"Try deleting signing key", &Provider{settings: &Settings{kid: "signing_key"}, keys: map[string][]byte{}}, "signing_key"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -105,7 +116,7 @@ func TestProvider_CurrentKey(t *testing.T) {
 		p    Provider
 		want publickey.PublicKey
 	}{
-		{"Normal", Provider{settings: Settings{key: []byte("test"), kid: "key_id"}}, publickey.New([]byte("test"), "key_id")},
+		{"Normal", Provider{settings: &Settings{key: []byte("test"), kid: "key_id"}}, publickey.New([]byte("test"), "key_id")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,72 @@
+package hs
+
+import (
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+)
+
+// keyDir lists the PEM block types used for keys by this package
+var keyDir = pemutil.KeyDir{Private: []string{"HMAC KEY"}, Public: []string{"HMAC SECRET KEY"}, Name: "signing key"}
+
+// NewSettingsFromPEM creates new signature settings from a PEM-encoded HMAC key.
+// Key ID and key URL are read from the Key-ID and Key-URL headers of the PEM block.
+func NewSettingsFromPEM(key []byte) (Settings, error) {
+	b, err := pemutil.DecodeType(key, keyDir.Private...)
+	if err != nil {
+		return Settings{}, err
+	}
+	return NewSettingsWithKeyURL(b.Bytes, b.KeyID, b.KeyURL)
+}
+
+// Export returns a copy of the key so it can be loaded using NewSettings
+func (s Settings) Export() ([]byte, error) {
+	return append([]byte(nil), s.key...), nil
+}
+
+// ExportPEM returns the PEM-encoded key including key ID and key URL so it can be loaded using NewSettingsFromPEM
+func (s Settings) ExportPEM() ([]byte, error) {
+	return pemutil.Encode("HMAC KEY", s.key, s.kid, s.jku), nil
+}
+
+// Settings returns the signature settings of the provider which can be used to export the key
+func (p Provider) Settings() Settings {
+	return p.signing()
+}
+
+// EncodeSecretPEM returns the PEM-encoded secret including the key ID. The expiry of the secret is not stored.
+// CAUTION: The secret is used for both signing and verification. Do not publish it.
+func EncodeSecretPEM(s Secret) ([]byte, error) {
+	if len(s.key) == 0 {
+		return nil, errors.New("empty keys are not allowed")
+	}
+	return pemutil.Encode("HMAC SECRET KEY", s.key, s.kid, ""), nil
+}
+
+// DecodeSecretPEM decodes a PEM-encoded secret reading the key ID from the Key-ID header
+func DecodeSecretPEM(key []byte) (Secret, error) {
+	b, err := pemutil.DecodeType(key, keyDir.Public...)
+	if err != nil {
+		return Secret{}, err
+	}
+	return NewSecret(b.Bytes, b.KeyID)
+}
+
+// LoadProviderFromDir returns a Provider using the PEM-encoded keys in the files with the extension .pem in dir.
+// The directory has to contain exactly one HMAC KEY block which is used for signing. All HMAC SECRET KEY blocks are added as secrets for verification.
+// Key IDs are read from the Key-ID header and default to the file name without extension.
+func LoadProviderFromDir(dir string, t Algorithm) (Provider, error) {
+	var p Provider
+	err := keyDir.Load(dir, func(b pemutil.Block) (pemutil.KeyAdder, error) {
+		s, err := NewSettingsWithKeyURL(b.Bytes, b.KeyID, b.KeyURL)
+		if err != nil {
+			return nil, err
+		}
+		p, err = LoadProvider(s, t)
+		return &p, err
+	})
+	if err != nil {
+		return Provider{}, err
+	}
+	return p, nil
+}
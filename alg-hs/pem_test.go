@@ -0,0 +1,167 @@
+package hs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+)
+
+func TestSettings_ExportPEM(t *testing.T) {
+	p, err := NewProviderWithKeyURL(HS256, "key_url")
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	enc, err := p.Settings().ExportPEM()
+	if err != nil {
+		t.Fatalf("Settings.ExportPEM() failed: %s", err.Error())
+	}
+	s, err := NewSettingsFromPEM(enc)
+	if err != nil {
+		t.Fatalf("NewSettingsFromPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettingsFromPEM() = %+v, want %+v", s, p.Settings())
+	}
+	der, err := p.Settings().Export()
+	if err != nil {
+		t.Fatalf("Settings.Export() failed: %s", err.Error())
+	}
+	s, err = NewSettingsWithKeyURL(der, p.Settings().kid, "key_url")
+	if err != nil {
+		t.Fatalf("NewSettings() failed for exported key: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettings() = %+v, want %+v", s, p.Settings())
+	}
+	der[0] ^= 0xff
+	if reflect.DeepEqual(der, p.Settings().key) {
+		t.Error("Settings.Export() returned the key instead of a copy")
+	}
+}
+
+func TestNewSettingsFromPEM(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"HMAC key", pemutil.Encode("HMAC KEY", testKey, "key_id", ""), false},
+		{"Secret", pemutil.Encode("HMAC SECRET KEY", testKey, "key_id", ""), true},
+		{"Private key", pemutil.Encode("PRIVATE KEY", testKey, "key_id", ""), true},
+		{"Empty key", pemutil.Encode("HMAC KEY", nil, "key_id", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSettingsFromPEM(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSettingsFromPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s.kid != "key_id" {
+				t.Errorf("NewSettingsFromPEM() read key ID %q, want %q", s.kid, "key_id")
+			}
+		})
+	}
+}
+
+func TestEncodeSecretPEM(t *testing.T) {
+	secret, _ := NewSecret(testKey, "key_id")
+	tests := []struct {
+		name    string
+		secret  Secret
+		wantErr bool
+	}{
+		{"HMAC key", secret, false},
+		{"Empty key", Secret{kid: "key_id"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := EncodeSecretPEM(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeSecretPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := DecodeSecretPEM(enc)
+			if err != nil {
+				t.Fatalf("DecodeSecretPEM() failed: %s", err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.secret) {
+				t.Errorf("DecodeSecretPEM() = %v, want %v", got, tt.secret)
+			}
+		})
+	}
+}
+
+func TestDecodeSecretPEM(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"Secret", pemutil.Encode("HMAC SECRET KEY", testKey, "key_id", ""), false},
+		{"HMAC key", pemutil.Encode("HMAC KEY", testKey, "key_id", ""), true},
+		{"Public key", pemutil.Encode("PUBLIC KEY", testKey, "key_id", ""), true},
+		{"Empty key", pemutil.Encode("HMAC SECRET KEY", nil, "key_id", ""), true},
+		{"No key ID", pemutil.Encode("HMAC SECRET KEY", testKey, "", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeSecretPEM(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeSecretPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// The handling of the directory itself is tested in internal/pemutil
+func TestLoadProviderFromDir(t *testing.T) {
+	keys := map[string][]byte{
+		"signing.pem":        pemutil.Encode("HMAC KEY", testKey, "signing", ""),
+		"signing_public.pem": pemutil.Encode("HMAC SECRET KEY", testKey, "signing", ""),
+		"other.pem":          pemutil.Encode("HMAC SECRET KEY", testKey, "other", ""),
+	}
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		alg     Algorithm
+		wantErr bool
+	}{
+		{"HS256", keys, HS256, false},
+		{"Key too short for algorithm", keys, HS512, true},
+		{"Unknown algorithm", keys, 12, true},
+		{"Public key", map[string][]byte{"signing.pem": keys["signing.pem"], "other.pem": pemutil.Encode("PUBLIC KEY", testKey, "other", "")}, HS256, true},
+		{"Empty signing key", map[string][]byte{"signing.pem": pemutil.Encode("HMAC KEY", nil, "signing", "")}, HS256, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "jwt")
+			if err != nil {
+				t.Fatalf("Could not create directory: %s", err.Error())
+			}
+			defer os.RemoveAll(dir) // nolint:errcheck
+			for name, data := range tt.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+					t.Fatalf("Could not write file: %s", err.Error())
+				}
+			}
+			p, err := LoadProviderFromDir(dir, tt.alg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadProviderFromDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.CurrentKey().GetKeyID() != "signing" {
+				t.Errorf("LoadProviderFromDir() loaded signing key %q, want %q", p.CurrentKey().GetKeyID(), "signing")
+			}
+			if _, ok := tt.files["other.pem"]; ok && !reflect.DeepEqual(p.keys["other"], testKey) {
+				t.Errorf("LoadProviderFromDir() added public key %v, want %v", p.keys["other"], testKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package hs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// minKeySize is the minimum size of all keys in bytes. Zero requires keys to be as long as the output of the hash function.
+	minKeySize   int
+	minKeySizeMu sync.RWMutex
+)
+
+// SetMinKeySize sets the minimum size in bytes that keys used for signing and verification must have.
+// By default keys have to be at least as long as the output of the hash function as required by RFC 7518 section 3.2. Passing 0 restores the default.
+// The limit is enforced by NewSettings, LoadProvider and AddPublicKey. Keys returned by a key resolver that are too short are ignored.
+// As NewSettings does not know the algorithm the key will be used with, it requires keys to be at least 32 bytes long by default which is the minimum for HS256.
+func SetMinKeySize(size int) error {
+	if size < 0 {
+		return errors.New("minimum key size must not be negative")
+	}
+	minKeySizeMu.Lock()
+	defer minKeySizeMu.Unlock()
+	minKeySize = size
+	return nil
+}
+
+// requiredKeySize returns the minimum key size in bytes for the algorithm. Unknown algorithms use the requirements of HS256.
+func requiredKeySize(alg Algorithm) int {
+	minKeySizeMu.RLock()
+	defer minKeySizeMu.RUnlock()
+	if minKeySize != 0 {
+		return minKeySize
+	}
+	switch alg {
+	case HS384:
+		return 48
+	case HS512:
+		return 64
+	default:
+		return 32
+	}
+}
+
+// checkKeySize returns an error if the key is shorter than the minimum key size for the algorithm
+func checkKeySize(key []byte, alg Algorithm) error {
+	if min := requiredKeySize(alg); len(key) < min {
+		return fmt.Errorf("key has %d bytes but at least %d bytes are required", len(key), min)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package hs
+
+import (
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestSetMinKeySize(t *testing.T) {
+	if err := SetMinKeySize(-1); err == nil {
+		t.Error("SetMinKeySize() should fail for negative sizes")
+	}
+
+	tests := []struct {
+		alg  Algorithm
+		size int
+	}{
+		{HS256, 32},
+		{HS384, 48},
+		{HS512, 64},
+	}
+	for _, tt := range tests {
+		if _, err := LoadProvider(Settings{key: make([]byte, tt.size-1)}, tt.alg); err == nil {
+			t.Errorf("LoadProvider() should reject %d byte keys for %s", tt.size-1, algToString(tt.alg))
+		}
+		if _, err := LoadProvider(Settings{key: make([]byte, tt.size)}, tt.alg); err != nil {
+			t.Errorf("LoadProvider() failed for %d byte key for %s: %s", tt.size, algToString(tt.alg), err.Error())
+		}
+	}
+
+	defer SetMinKeySize(0) // nolint:errcheck
+	if err := SetMinKeySize(16); err != nil {
+		t.Fatalf("SetMinKeySize() failed: %s", err.Error())
+	}
+	s, err := NewSettings(make([]byte, 16), "key_id")
+	if err != nil {
+		t.Fatalf("NewSettings() failed after lowering the minimum key size: %s", err.Error())
+	}
+	p, err := LoadProvider(s, HS512)
+	if err != nil {
+		t.Fatalf("LoadProvider() failed after lowering the minimum key size: %s", err.Error())
+	}
+	if err := p.AddPublicKey(publickey.New(make([]byte, 15), "short")); err == nil {
+		t.Error("AddPublicKey() should reject keys shorter than the minimum key size")
+	}
+
+	if err := SetMinKeySize(128); err != nil {
+		t.Fatalf("SetMinKeySize() failed: %s", err.Error())
+	}
+	p, err = NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %s", err.Error())
+	}
+	if len(p.settings.key) != 128 {
+		t.Errorf("NewProvider() generated a key of %d bytes but the minimum key size is 128", len(p.settings.key))
+	}
+}
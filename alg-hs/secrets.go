@@ -0,0 +1,186 @@
+package hs
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"sort"
+	"time"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Secret is a named HMAC key that may expire. It is used instead of publickey.PublicKey to make clear that the key must never be published.
+type Secret struct {
+	key     []byte
+	kid     string
+	expires time.Time
+}
+
+// NewSecret creates a secret that does not expire
+func NewSecret(key []byte, keyID string) (Secret, error) {
+	return NewSecretWithExpiry(key, keyID, time.Time{})
+}
+
+// NewSecretWithExpiry creates a secret that is neither used for signing nor accepted for verification after it expired.
+// A zero expiry time means the secret does not expire.
+func NewSecretWithExpiry(key []byte, keyID string, expires time.Time) (Secret, error) {
+	if len(key) == 0 {
+		return Secret{}, errors.New("empty keys are not allowed")
+	}
+	if keyID == "" {
+		return Secret{}, errors.New("secrets require a key ID")
+	}
+	return Secret{key, keyID, expires}, nil
+}
+
+// Key returns the HMAC key of the secret.
+// CAUTION: Do not share the key as it is used for both signing and verification.
+func (s Secret) Key() []byte {
+	return s.key
+}
+
+// KeyID returns the key ID of the secret
+func (s Secret) KeyID() string {
+	return s.kid
+}
+
+// Expires returns the time after which the secret is no longer used. It is zero if the secret does not expire.
+func (s Secret) Expires() time.Time {
+	return s.expires
+}
+
+// Expired checks if the secret has expired
+func (s Secret) Expired() bool {
+	return !s.expires.IsZero() && time.Now().After(s.expires)
+}
+
+// hashFunc returns the hash function used by the algorithm or nil if the algorithm is unknown
+func hashFunc(alg Algorithm) func() hash.Hash {
+	switch alg {
+	case HS256:
+		return sha256.New
+	case HS384:
+		return sha512.New384
+	case HS512:
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+// AddSecret adds a secret that is only used for verification until it is selected for signing using SetSigningSecret
+func (p *Provider) AddSecret(s Secret) error {
+	if s.kid == "" {
+		return errors.New("secrets require a key ID")
+	}
+	if s.Expired() {
+		return errors.New("secret already expired")
+	}
+	if err := checkKeySize(s.key, p.alg); err != nil {
+		return err
+	}
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if _, ok := p.keys[s.kid]; ok {
+		return errors.New("key ID already exists")
+	}
+	p.keys[s.kid] = s.key
+	p.setValidity(s.kid, publickey.Validity{Expires: s.expires})
+	return nil
+}
+
+// SetSigningSecret selects the secret with the key ID for signing. The previous signing secret stays available for verification.
+// The change applies to all copies of the provider including those already registered using jwt.SetSignatureProvider.
+func (p *Provider) SetSigningSecret(keyID string) error {
+	if hashFunc(p.alg) == nil {
+		return errors.New("invalid algorithm ID")
+	}
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	key, ok := p.keys[keyID]
+	if !ok || keyID == "" {
+		return errors.New("unknown key id")
+	}
+	if err := p.validity[keyID].Check(time.Now()); err != nil {
+		return err
+	}
+	p.settings.key = key
+	p.settings.kid = keyID
+	p.keys[""] = key
+	return nil
+}
+
+// RotateSecret adds the secret and selects it for signing. The previous signing secret stays available for verification until it is removed or expires.
+func (p *Provider) RotateSecret(s Secret) error {
+	if err := p.AddSecret(s); err != nil {
+		return err
+	}
+	return p.SetSigningSecret(s.kid)
+}
+
+// RemoveSecret removes a secret by it's key ID. The secret used for signing can not be removed.
+func (p *Provider) RemoveSecret(keyID string) {
+	if keyID == "" || keyID == p.signing().kid {
+		return
+	}
+	keysMu.Lock()
+	delete(p.keys, keyID)
+	delete(p.validity, keyID)
+	keysMu.Unlock()
+}
+
+// RemoveExpiredSecrets removes all expired secrets except the one used for signing
+func (p *Provider) RemoveExpiredSecrets() {
+	now := time.Now()
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	for kid, v := range p.validity {
+		if kid != p.settings.kid && v.Expired(now) {
+			delete(p.keys, kid)
+			delete(p.validity, kid)
+		}
+	}
+}
+
+// CurrentSecret returns the secret used for signing
+func (p Provider) CurrentSecret() Secret {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return Secret{p.settings.key, p.settings.kid, p.validity[p.settings.kid].Expires}
+}
+
+// Secrets returns all secrets of the provider sorted by key ID including the one used for signing
+func (p Provider) Secrets() []Secret {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	secrets := make([]Secret, 0, len(p.keys))
+	for kid, key := range p.keys {
+		if kid == "" {
+			continue
+		}
+		secrets = append(secrets, Secret{key, kid, p.validity[kid].Expires})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].kid < secrets[j].kid })
+	return secrets
+}
+
+// setValidity stores the validity period of a key unless it is unrestricted. The caller must hold keysMu.
+func (p *Provider) setValidity(keyID string, v publickey.Validity) {
+	if v.IsZero() {
+		return
+	}
+	p.validity[keyID] = v
+}
+
+// checkValidity returns an error if the key with the key ID is not valid at the current time. An empty key ID refers to the secret used for signing.
+func (p Provider) checkValidity(keyID string) error {
+	if keyID == "" {
+		keyID = p.signing().kid
+	}
+	keysMu.RLock()
+	v := p.validity[keyID]
+	keysMu.RUnlock()
+	return v.Check(time.Now())
+}
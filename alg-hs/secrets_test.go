@@ -0,0 +1,191 @@
+package hs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestNewSecretWithExpiry(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		key     []byte
+		keyID   string
+		want    Secret
+		wantErr bool
+	}{
+		{"Normal", testKey, "key_id", Secret{testKey, "key_id", exp}, false},
+		{"Empty key", nil, "key_id", Secret{}, true},
+		{"Empty key ID", testKey, "", Secret{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSecretWithExpiry(tt.key, tt.keyID, exp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSecretWithExpiry() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got.Key(), tt.want.key) || got.KeyID() != tt.want.kid || !got.Expires().Equal(tt.want.expires) {
+				t.Errorf("NewSecretWithExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if s, _ := NewSecret(testKey, "key_id"); s.Expired() || !s.Expires().IsZero() {
+		t.Error("NewSecret() should create a secret that does not expire")
+	}
+	if s, _ := NewSecretWithExpiry(testKey, "key_id", time.Now().Add(-time.Hour)); !s.Expired() {
+		t.Error("Secret.Expired() should be true after the expiry time")
+	}
+}
+
+func TestProvider_RotateSecret(t *testing.T) {
+	p, err := NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %s", err.Error())
+	}
+	old := p.CurrentSecret()
+
+	var h jwt.Header
+	p.Header(&h)
+	sig, err := p.Sign([]byte("content"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+
+	next, _ := NewSecret(bytes.Repeat([]byte{0x01}, 32), "next")
+	if err := p.RotateSecret(next); err != nil {
+		t.Fatalf("RotateSecret() failed: %s", err.Error())
+	}
+	if p.CurrentSecret().KeyID() != "next" {
+		t.Errorf("RotateSecret() did not select the secret for signing, current secret is %q", p.CurrentSecret().KeyID())
+	}
+	if err := p.Verify([]byte("content"), sig, h); err != nil {
+		t.Errorf("Verify() failed for a token signed using the previous secret: %s", err.Error())
+	}
+
+	var nh jwt.Header
+	p.Header(&nh)
+	if nh.Kid != "next" {
+		t.Errorf("Header() should use the key ID of the new secret but is %q", nh.Kid)
+	}
+	nsig, err := p.Sign([]byte("content"))
+	if err != nil {
+		t.Fatalf("Sign() failed: %s", err.Error())
+	}
+	if err := p.Verify([]byte("content"), nsig, nh); err != nil {
+		t.Errorf("Verify() failed for a token signed using the new secret: %s", err.Error())
+	}
+	if err := p.Verify([]byte("content"), nsig, jwt.Header{Alg: "HS256"}); err != nil {
+		t.Errorf("Verify() failed for a token without key ID signed using the new secret: %s", err.Error())
+	}
+
+	p.RemoveSecret("next")
+	if len(p.Secrets()) != 2 {
+		t.Errorf("RemoveSecret() should not remove the secret used for signing")
+	}
+	p.RemoveSecret(old.KeyID())
+	if err := p.Verify([]byte("content"), sig, h); err == nil {
+		t.Error("Verify() succeeded for a token signed using a removed secret")
+	}
+	if secrets := p.Secrets(); len(secrets) != 1 || secrets[0].KeyID() != "next" {
+		t.Errorf("Secrets() = %v, want only the secret used for signing", secrets)
+	}
+
+	if err := p.RotateSecret(next); err == nil {
+		t.Error("RotateSecret() should fail for existing key IDs")
+	}
+	short, _ := NewSecret([]byte("short"), "short")
+	if err := p.RotateSecret(short); err == nil {
+		t.Error("RotateSecret() should fail for secrets that are too short")
+	}
+	if err := p.SetSigningSecret("unknown"); err == nil {
+		t.Error("SetSigningSecret() should fail for unknown key IDs")
+	}
+}
+
+func TestProvider_SecretExpiry(t *testing.T) {
+	p, err := NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %s", err.Error())
+	}
+	expired, _ := NewSecretWithExpiry(testKey, "expired", time.Now().Add(-time.Hour))
+	if err := p.AddSecret(expired); err == nil {
+		t.Error("AddSecret() should fail for expired secrets")
+	}
+
+	s, _ := NewSecretWithExpiry(testKey, "expiring", time.Now().Add(time.Hour))
+	if err := p.AddSecret(s); err != nil {
+		t.Fatalf("AddSecret() failed: %s", err.Error())
+	}
+	sig := getMAC(hmac.New(sha256.New, testKey), []byte("content"))
+	h := jwt.Header{Alg: "HS256", Kid: "expiring"}
+	if err := p.Verify([]byte("content"), sig, h); err != nil {
+		t.Errorf("Verify() failed for a secret that did not expire yet: %s", err.Error())
+	}
+
+	// Move the expiry into the past instead of waiting
+	p.validity["expiring"] = publickey.Validity{Expires: time.Now().Add(-time.Second)}
+	if err := p.Verify([]byte("content"), sig, h); err == nil {
+		t.Error("Verify() succeeded for an expired secret")
+	}
+	if err := p.SetSigningSecret("expiring"); err == nil {
+		t.Error("SetSigningSecret() should fail for expired secrets")
+	}
+	p.RemoveExpiredSecrets()
+	if len(p.Secrets()) != 1 {
+		t.Errorf("RemoveExpiredSecrets() did not remove the expired secret: %v", p.Secrets())
+	}
+
+	s, _ = NewSecretWithExpiry(testKey, "signing", time.Now().Add(time.Hour))
+	if err := p.RotateSecret(s); err != nil {
+		t.Fatalf("RotateSecret() failed: %s", err.Error())
+	}
+	if !p.CurrentSecret().Expires().Equal(s.Expires()) {
+		t.Errorf("CurrentSecret() did not return the expiry of the secret")
+	}
+	p.validity["signing"] = publickey.Validity{Expires: time.Now().Add(-time.Second)}
+	if _, err := p.Sign([]byte("content")); err == nil {
+		t.Error("Sign() succeeded using an expired secret")
+	}
+	p.RemoveExpiredSecrets()
+	if p.CurrentSecret().KeyID() != "signing" {
+		t.Error("RemoveExpiredSecrets() removed the secret used for signing")
+	}
+}
+
+func TestProvider_SetSigningSecretConcurrent(t *testing.T) {
+	p, err := NewProvider(HS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	registered := p
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			h := jwt.Header{}
+			registered.Header(&h)
+			if _, err := registered.Sign([]byte("content")); err != nil {
+				t.Errorf("Sign() failed while rotating secrets: %s", err.Error())
+				return
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		s, _ := NewSecret(testKey, "rotated"+string(rune('0'+i)))
+		if err := p.RotateSecret(s); err != nil {
+			t.Fatalf("RotateSecret() failed: %s", err.Error())
+		}
+	}
+	<-done
+	// Copies of the provider share the signing secret
+	if registered.CurrentKey().GetKeyID() != "rotated9" {
+		t.Errorf("Copy of the provider signs using %q instead of the rotated secret", registered.CurrentKey().GetKeyID())
+	}
+}
@@ -0,0 +1,19 @@
+package pbes2
+
+import (
+	"errors"
+)
+
+// Settings stores the password for an algorithm
+type Settings struct {
+	password []byte
+	kid      string
+}
+
+// NewSettings creates new settings for the parameters
+func NewSettings(password []byte, keyID string) (Settings, error) {
+	if len(password) == 0 {
+		return Settings{}, errors.New("empty passwords are not allowed")
+	}
+	return Settings{password, keyID}, nil
+}
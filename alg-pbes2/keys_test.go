@@ -0,0 +1,18 @@
+package pbes2
+
+import (
+	"testing"
+)
+
+func TestNewSettings(t *testing.T) {
+	if _, err := NewSettings(nil, "key_id"); err == nil {
+		t.Error("NewSettings() should fail for empty password")
+	}
+	s, err := NewSettings([]byte("password"), "key_id")
+	if err != nil {
+		t.Fatalf("NewSettings() failed: %s", err.Error())
+	}
+	if string(s.password) != "password" || s.kid != "key_id" {
+		t.Errorf("NewSettings() = %v, want password \"password\" with key ID \"key_id\"", s)
+	}
+}
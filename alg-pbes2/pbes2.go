@@ -0,0 +1,205 @@
+package pbes2
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/alg-aeskw"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Algorithm identifies one of the PBES2 key encryption algorithms
+type Algorithm int
+
+const (
+	// PBES2HS256A128KW is PBES2 using HMAC SHA-256 and A128KW
+	PBES2HS256A128KW Algorithm = 1
+
+	// PBES2HS384A192KW is PBES2 using HMAC SHA-384 and A192KW
+	PBES2HS384A192KW Algorithm = 2
+
+	// PBES2HS512A256KW is PBES2 using HMAC SHA-512 and A256KW
+	PBES2HS512A256KW Algorithm = 3
+)
+
+const (
+	// DefaultIterations is the iteration count used for encryption unless configured otherwise
+	DefaultIterations = 100000
+
+	// DefaultSaltSize is the size of the salt in bytes used for encryption unless configured otherwise
+	DefaultSaltSize = 16
+
+	// MinIterations is the lowest iteration count accepted for decryption unless configured otherwise. It is the minimum recommended by RFC 7518 section 4.8.1.2.
+	MinIterations = 1000
+
+	// MaxIterations is the highest iteration count accepted for decryption unless configured otherwise
+	MaxIterations = 1000000
+
+	// minSaltSize is the minimum size of the salt as required by RFC 7518 section 4.8.1.1
+	minSaltSize = 8
+)
+
+func algToString(alg Algorithm) string {
+	switch alg {
+	case PBES2HS256A128KW:
+		return "PBES2-HS256+A128KW"
+	case PBES2HS384A192KW:
+		return "PBES2-HS384+A192KW"
+	case PBES2HS512A256KW:
+		return "PBES2-HS512+A256KW"
+	default:
+		return ""
+	}
+}
+
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
+// Provider provides PBES2 as JWE key management algorithm.
+// Content encryption keys are wrapped using a key derived from a password.
+type Provider struct {
+	alg        Algorithm
+	hash       func() hash.Hash
+	keySize    int
+	settings   Settings
+	iterations int
+	saltSize   int
+	min        int
+	max        int
+	random     io.Reader
+}
+
+var _ jwt.EncryptionProvider = Provider{}
+
+// NewProvider creates a new Provider using the password with the default iteration count and salt size
+func NewProvider(t Algorithm, password []byte) (Provider, error) {
+	s, err := NewSettings(password, "")
+	if err != nil {
+		return Provider{}, err
+	}
+	return LoadProvider(s, t)
+}
+
+// LoadProvider returns a Provider using the supplied settings with the default iteration count and salt size
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if len(s.password) == 0 {
+		return Provider{}, errors.New("empty passwords are not allowed")
+	}
+	switch t {
+	case PBES2HS256A128KW:
+		return Provider{t, sha256.New, 16, s, DefaultIterations, DefaultSaltSize, MinIterations, MaxIterations, nil}, nil
+	case PBES2HS384A192KW:
+		return Provider{t, sha512.New384, 24, s, DefaultIterations, DefaultSaltSize, MinIterations, MaxIterations, nil}, nil
+	case PBES2HS512A256KW:
+		return Provider{t, sha512.New, 32, s, DefaultIterations, DefaultSaltSize, MinIterations, MaxIterations, nil}, nil
+	}
+	return Provider{}, errors.New("type invalid")
+}
+
+// SetIterations sets the iteration count used for encryption. It has to be within the limits accepted for decryption.
+func (p *Provider) SetIterations(iterations int) error {
+	if iterations < p.min || iterations > p.max {
+		return errors.New("iteration count is outside of the accepted limits")
+	}
+	p.iterations = iterations
+	return nil
+}
+
+// SetSaltSize sets the size of the random salt in bytes used for encryption. It has to be at least 8 bytes.
+func (p *Provider) SetSaltSize(size int) error {
+	if size < minSaltSize {
+		return errors.New("salt has to be at least 8 bytes long")
+	}
+	p.saltSize = size
+	return nil
+}
+
+// SetIterationLimits sets the lowest and highest iteration count accepted for decryption.
+// Limiting the iteration count prevents attackers from using tokens with huge iteration counts to exhaust resources.
+func (p *Provider) SetIterationLimits(min, max int) error {
+	if min < 1 || max < min {
+		return errors.New("iteration limits are invalid")
+	}
+	if p.iterations < min || p.iterations > max {
+		return errors.New("iteration count used for encryption is outside of the limits")
+	}
+	p.min = min
+	p.max = max
+	return nil
+}
+
+// SetRandom sets the source of randomness used for salts and content encryption keys. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for salts and content encryption keys
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
+// Header sets the necessary JWT header fields
+func (p Provider) Header(h *jwt.Header) {
+	h.Alg = algToString(p.alg)
+	if p.settings.kid != "" {
+		h.Kid = p.settings.kid
+	}
+}
+
+// WrapKey generates a random content encryption key of the requested size and wraps it using a key derived from the password.
+// The salt and iteration count are added to the header.
+func (p Provider) WrapKey(size int, h *jwt.Header) ([]byte, []byte, error) {
+	salt := make([]byte, p.saltSize)
+	if _, err := io.ReadFull(p.randomSource(), salt); err != nil {
+		return nil, nil, err
+	}
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(p.randomSource(), cek); err != nil {
+		return nil, nil, err
+	}
+	h.P2s = base64.RawURLEncoding.EncodeToString(salt)
+	h.P2c = p.iterations
+	wrapped, err := aeskw.Wrap(p.deriveKey(salt, p.iterations), cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, wrapped, nil
+}
+
+// UnwrapKey decrypts the content encryption key using a key derived from the password and the parameters in the header
+func (p Provider) UnwrapKey(encryptedKey []byte, size int, h jwt.Header) ([]byte, error) {
+	if h.Kid != "" && h.Kid != p.settings.kid {
+		return nil, errors.New("unknown key id")
+	}
+	if h.P2c < p.min || h.P2c > p.max {
+		return nil, errors.New("iteration count is outside of the accepted limits")
+	}
+	salt, err := base64.RawURLEncoding.DecodeString(h.P2s)
+	if err != nil || len(salt) < minSaltSize {
+		return nil, errors.New("salt is invalid")
+	}
+	cek, err := aeskw.Unwrap(p.deriveKey(salt, h.P2c), encryptedKey)
+	if err != nil || len(cek) != size {
+		return nil, errors.New("decryption failed")
+	}
+	return cek, nil
+}
+
+// deriveKey derives the key used to wrap the content encryption key as specified in RFC 7518 section 4.8.1.1.
+// The salt input consists of the algorithm name, a zero byte and the salt.
+func (p Provider) deriveKey(salt []byte, iterations int) []byte {
+	input := append([]byte(algToString(p.alg)), 0x00)
+	input = append(input, salt...)
+	return pbkdf2.Key(p.settings.password, input, iterations, p.keySize, p.hash)
+}
@@ -0,0 +1,227 @@
+package pbes2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func Test_algToString(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  Algorithm
+		want string
+	}{
+		{"PBES2HS256A128KW", PBES2HS256A128KW, "PBES2-HS256+A128KW"},
+		{"PBES2HS384A192KW", PBES2HS384A192KW, "PBES2-HS384+A192KW"},
+		{"PBES2HS512A256KW", PBES2HS512A256KW, "PBES2-HS512+A256KW"},
+		{"Invalid", 12, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := algToString(tt.alg); got != tt.want {
+				t.Errorf("algToString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := jwt.Header{Typ: "JWT"}
+	Provider{alg: PBES2HS384A192KW, settings: Settings{nil, "key_id"}}.Header(&h)
+	if h.Alg != "PBES2-HS384+A192KW" {
+		t.Errorf("Provider.Header() should set Alg to \"PBES2-HS384+A192KW\" but instead it is %q", h.Alg)
+	}
+	if h.Kid != "key_id" {
+		t.Errorf("Provider.Header() should set Kid to \"key_id\" but instead it is %q", h.Kid)
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Settings
+		alg     Algorithm
+		keySize int
+		wantErr bool
+	}{
+		{"PBES2HS256A128KW", Settings{[]byte("password"), "key_id"}, PBES2HS256A128KW, 16, false},
+		{"PBES2HS384A192KW", Settings{[]byte("password"), "key_id"}, PBES2HS384A192KW, 24, false},
+		{"PBES2HS512A256KW", Settings{[]byte("password"), "key_id"}, PBES2HS512A256KW, 32, false},
+		{"UnknownAlgorithm", Settings{[]byte("password"), "key_id"}, 12, 0, true},
+		{"EmptyPassword", Settings{nil, "key_id"}, PBES2HS256A128KW, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadProvider(tt.s, tt.alg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.alg != tt.alg || got.keySize != tt.keySize || got.settings.kid != "key_id" {
+				t.Errorf("LoadProvider() did not pass the data from the input settings onto the provider")
+			}
+			if got.iterations != DefaultIterations || got.saltSize != DefaultSaltSize || got.min != MinIterations || got.max != MaxIterations {
+				t.Errorf("LoadProvider() did not set the default parameters")
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(PBES2HS256A128KW, nil); err == nil {
+		t.Error("NewProvider() should fail for empty password")
+	}
+	if _, err := NewProvider(12, []byte("password")); err == nil {
+		t.Error("NewProvider() with an unknown algorithm type should fail but returned no error.")
+	}
+	if _, err := NewProvider(PBES2HS256A128KW, []byte("password")); err != nil {
+		t.Errorf("NewProvider() failed: %s", err.Error())
+	}
+}
+
+func TestProvider_SetIterations(t *testing.T) {
+	p, _ := NewProvider(PBES2HS256A128KW, []byte("password"))
+	tests := []struct {
+		name       string
+		iterations int
+		wantErr    bool
+	}{
+		{"Minimum", MinIterations, false},
+		{"Maximum", MaxIterations, false},
+		{"TooLow", MinIterations - 1, true},
+		{"TooHigh", MaxIterations + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.SetIterations(tt.iterations)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Provider.SetIterations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && p.iterations != tt.iterations {
+				t.Errorf("Provider.SetIterations() did not set iteration count")
+			}
+		})
+	}
+}
+
+func TestProvider_SetSaltSize(t *testing.T) {
+	p, _ := NewProvider(PBES2HS256A128KW, []byte("password"))
+	if p.SetSaltSize(7) == nil {
+		t.Error("Provider.SetSaltSize() should fail for salt shorter than 8 bytes")
+	}
+	if err := p.SetSaltSize(32); err != nil || p.saltSize != 32 {
+		t.Errorf("Provider.SetSaltSize() did not set salt size: %v", err)
+	}
+}
+
+func TestProvider_SetIterationLimits(t *testing.T) {
+	p, _ := NewProvider(PBES2HS256A128KW, []byte("password"))
+	tests := []struct {
+		name    string
+		min     int
+		max     int
+		wantErr bool
+	}{
+		{"Normal", 10000, 200000, false},
+		{"ZeroMinimum", 0, 200000, true},
+		{"MaximumBelowMinimum", 200000, 10000, true},
+		{"ExcludesIterationCount", 200000, 300000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.SetIterationLimits(tt.min, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Provider.SetIterationLimits() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (p.min != tt.min || p.max != tt.max) {
+				t.Errorf("Provider.SetIterationLimits() did not set limits")
+			}
+		})
+	}
+}
+
+func TestProvider_UnwrapKey(t *testing.T) {
+	// Example from RFC 7517 appendix C
+	p, _ := LoadProvider(Settings{[]byte("Thus from my lips, by yours, my sin is purged."), ""}, PBES2HS256A128KW)
+	encryptedKey, _ := base64.RawURLEncoding.DecodeString("TrqXOwuNUfDV9VPTNbyGvEJ9JMjefAVn-TR1uIxR9p6hsRQh9Tk7BA")
+	cek := []byte{111, 27, 25, 52, 66, 29, 20, 78, 92, 176, 56, 240, 65, 208, 82, 112, 161, 131, 36, 55, 202, 236, 185, 172, 129, 23, 153, 194, 195, 48, 253, 182}
+	h := jwt.Header{Alg: "PBES2-HS256+A128KW", P2s: "2WCTcJZ1Rvd_CJuJripQ1w", P2c: 4096}
+	withHeader := func(f func(h *jwt.Header)) jwt.Header {
+		c := h
+		f(&c)
+		return c
+	}
+	tests := []struct {
+		name         string
+		encryptedKey []byte
+		size         int
+		h            jwt.Header
+		wantErr      bool
+	}{
+		{"Normal", encryptedKey, 32, h, false},
+		{"UnknownKeyID", encryptedKey, 32, withHeader(func(h *jwt.Header) { h.Kid = "unknown" }), true},
+		{"TooFewIterations", encryptedKey, 32, withHeader(func(h *jwt.Header) { h.P2c = 999 }), true},
+		{"TooManyIterations", encryptedKey, 32, withHeader(func(h *jwt.Header) { h.P2c = MaxIterations + 1 }), true},
+		{"WrongIterations", encryptedKey, 32, withHeader(func(h *jwt.Header) { h.P2c = 4097 }), true},
+		{"InvalidSalt", encryptedKey, 32, withHeader(func(h *jwt.Header) { h.P2s = "invalid base64!" }), true},
+		{"ShortSalt", encryptedKey, 32, withHeader(func(h *jwt.Header) { h.P2s = "dGVzdA" }), true},
+		{"WrongSize", encryptedKey, 16, h, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.UnwrapKey(tt.encryptedKey, tt.size, tt.h)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Provider.UnwrapKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, cek) {
+				t.Errorf("Provider.UnwrapKey() = %v, want %v", got, cek)
+			}
+		})
+	}
+}
+
+func TestProvider_WrapKey(t *testing.T) {
+	p, _ := NewProvider(PBES2HS512A256KW, []byte("password"))
+	p.SetIterations(MinIterations) // nolint:errcheck
+	p.SetSaltSize(24)              // nolint:errcheck
+	h := jwt.Header{}
+	cek, encryptedKey, err := p.WrapKey(32, &h)
+	if err != nil {
+		t.Fatalf("Provider.WrapKey() failed: %s", err.Error())
+	}
+	salt, _ := base64.RawURLEncoding.DecodeString(h.P2s)
+	if len(salt) != 24 || h.P2c != MinIterations {
+		t.Errorf("Provider.WrapKey() set p2s %q and p2c %d, want 24 byte salt and %d iterations", h.P2s, h.P2c, MinIterations)
+	}
+	got, err := p.UnwrapKey(encryptedKey, 32, h)
+	if err != nil || !bytes.Equal(got, cek) {
+		t.Errorf("Provider.UnwrapKey() = %x, %v, want %x", got, err, cek)
+	}
+	if _, _, err := p.WrapKey(12, &h); err == nil {
+		t.Error("Provider.WrapKey() should fail for content encryption key that cannot be wrapped")
+	}
+}
+
+func TestProvider_SetRandom(t *testing.T) {
+	p, err := NewProvider(PBES2HS256A128KW, []byte("password"))
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %s", err.Error())
+	}
+	p.SetRandom(bytes.NewReader(nil))
+	if _, _, err := p.WrapKey(16, &jwt.Header{}); err == nil {
+		t.Error("WrapKey() did not use the random source set by SetRandom()")
+	}
+	p.SetRandom(nil)
+	if _, _, err := p.WrapKey(16, &jwt.Header{}); err != nil {
+		t.Errorf("WrapKey() failed after resetting the random source: %s", err.Error())
+	}
+}
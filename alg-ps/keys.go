@@ -1,10 +1,14 @@
 package ps
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/fossoreslp/go-jwt"
 	"github.com/fossoreslp/go-jwt/publickey"
 )
 
@@ -13,6 +17,7 @@ type Settings struct {
 	private *rsa.PrivateKey
 	kid     string
 	jku     string
+	signer  crypto.Signer
 }
 
 // NewSettings creates new signature settings for the parameters
@@ -34,37 +39,130 @@ func NewSettingsWithKeyURL(key []byte, keyID, keyURL string) (Settings, error) {
 		}
 		rsaKey = castKey
 	}
-	return Settings{rsaKey, keyID, keyURL}, nil
+	if err := checkKeySize(&rsaKey.PublicKey); err != nil {
+		return Settings{}, err
+	}
+	return Settings{rsaKey, keyID, keyURL, nil}, nil
+}
+
+// NewSettingsFromSigner creates new signature settings that sign using the supplied signer instead of a private key.
+// This allows keeping the private key in an agent, a KMS or a separate signing process.
+func NewSettingsFromSigner(signer crypto.Signer, keyID string) (Settings, error) {
+	return NewSettingsFromSignerWithKeyURL(signer, keyID, "")
+}
+
+// NewSettingsFromSignerWithKeyURL works just like NewSettingsFromSigner but also sets the key URL
+func NewSettingsFromSignerWithKeyURL(signer crypto.Signer, keyID, keyURL string) (Settings, error) {
+	if signer == nil {
+		return Settings{}, errors.New("signer must not be nil")
+	}
+	pub, ok := signer.Public().(*rsa.PublicKey)
+	if !ok {
+		return Settings{}, errors.New("signer does not use a RSA key")
+	}
+	if err := checkKeySize(pub); err != nil {
+		return Settings{}, err
+	}
+	return Settings{nil, keyID, keyURL, signer}, nil
+}
+
+// publicKey returns the public key belonging to the key used for signing
+func (s Settings) publicKey() *rsa.PublicKey {
+	if s.signer != nil {
+		return s.signer.Public().(*rsa.PublicKey)
+	}
+	return &s.private.PublicKey
 }
 
+// keysMu guards the key maps of all providers so keys can be managed while tokens are being verified
+var keysMu sync.RWMutex
+
 // AddPublicKey adds a public key for verification
 func (p *Provider) AddPublicKey(key publickey.PublicKey) error {
+	if err := key.CheckMetadata(algToString(p.alg), "RSA", "sig", "verify"); err != nil {
+		return err
+	}
+	validity := key.GetValidity()
+	if validity.Expired(time.Now()) {
+		return errors.New("key expired")
+	}
 	id := key.GetKeyID()
+	keysMu.Lock()
+	defer keysMu.Unlock()
 	if _, ok := p.keys[id]; ok {
 		return errors.New("key ID already exists")
 	}
-	pub, err := x509.ParsePKIXPublicKey(key.GetPublicKey())
+	rsaKey, err := parsePublicKey(key)
 	if err != nil {
-		return errors.New("could not decode public key")
+		return err
 	}
-	rsaKey, ok := pub.(*rsa.PublicKey)
-	if !ok {
-		return errors.New("public key is not a RSA public key")
+	if err := checkKeySize(rsaKey); err != nil {
+		return err
 	}
 	p.keys[id] = rsaKey
+	if !validity.IsZero() {
+		p.validity[id] = validity
+	}
 	return nil
 }
 
+// SetKeyResolver sets a resolver that is consulted for keys when the key ID of a token is unknown to the provider
+func (p *Provider) SetKeyResolver(r jwt.KeyResolver) {
+	p.resolver = r
+}
+
 // RemovePublicKey removes a public key by it's key ID from the verification set
 func (p *Provider) RemovePublicKey(keyid string) {
 	if keyid == p.settings.kid {
 		return
 	}
+	keysMu.Lock()
 	delete(p.keys, keyid)
+	delete(p.validity, keyid)
+	keysMu.Unlock()
 }
 
 // CurrentKey returns the public key belonging to the private key used for signing.
 func (p Provider) CurrentKey() publickey.PublicKey {
-	b, _ := x509.MarshalPKIXPublicKey(&p.settings.private.PublicKey) // Marshaling an RSA public key should never fail
+	b, _ := x509.MarshalPKIXPublicKey(p.settings.publicKey()) // Marshaling an RSA public key should never fail
 	return publickey.New(b, p.settings.kid)
 }
+
+func parsePublicKey(key publickey.PublicKey) (*rsa.PublicKey, error) {
+	pub, err := x509.ParsePKIXPublicKey(key.GetPublicKey())
+	if err != nil {
+		return nil, errors.New("could not decode public key")
+	}
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not a RSA public key")
+	}
+	return rsaKey, nil
+}
+
+// resolveKeys retrieves the keys that may have been used to sign a token from the key resolver
+func (p Provider) resolveKeys(data []byte, h jwt.Header) ([]*rsa.PublicKey, error) {
+	if p.resolver == nil {
+		return nil, errors.New("unknown key id")
+	}
+	c, err := jwt.ContentFromSigningInput(data)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := p.resolver.Resolve(h, c)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([]*rsa.PublicKey, 0, len(keys))
+	for _, k := range keys {
+		// A resolver may return keys for other algorithms or keys that do not satisfy the key size policy which are skipped
+		// Keys whose metadata does not permit verifying the token are skipped as well
+		if pub, err := parsePublicKey(k); err == nil && checkKeySize(pub) == nil && k.CheckUsage(h.Alg, "RSA", "sig", "verify") == nil {
+			parsed = append(parsed, pub)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("unknown key id")
+	}
+	return parsed, nil
+}
@@ -1,12 +1,16 @@
 package ps
 
 import (
+	"crypto"
+	"crypto/rand"
 	"crypto/rsa"
+	"io"
 	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/fossoreslp/go-jwt/publickey"
+	"golang.org/x/crypto/ed25519"
 )
 
 var (
@@ -19,7 +23,24 @@ var (
 	pkixEC  = []byte{0x30, 0x4e, 0x30, 0x10, 0x6, 0x7, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x2, 0x1, 0x6, 0x5, 0x2b, 0x81, 0x4, 0x0, 0x21, 0x3, 0x3a, 0x0, 0x4, 0x92, 0x6a, 0x93, 0xf0, 0x42, 0xec, 0x1b, 0x46, 0x95, 0x47, 0xd4, 0x0, 0xfa, 0x45, 0x83, 0x39, 0x8, 0x1e, 0xe8, 0x2b, 0xb7, 0xbc, 0x6c, 0xd7, 0x96, 0x80, 0xc, 0x2d, 0xb6, 0xc1, 0x5c, 0x33, 0x14, 0x44, 0xb7, 0xc, 0xc5, 0x98, 0x4b, 0x7a, 0xf2, 0x55, 0x9, 0x22, 0xac, 0x5c, 0x67, 0xd8, 0x46, 0xb2, 0x42, 0xc9, 0x9f, 0xfc, 0x6f, 0x4d}
 )
 
+// allowTestKeys lowers the minimum key size so the small keys above are accepted and returns a function restoring the default
+func allowTestKeys() func() {
+	SetMinKeySize(32) // nolint:errcheck
+	return func() {
+		SetMinKeySize(2048) // nolint:errcheck
+	}
+}
+
+// equalSettings compares settings using rsa.PrivateKey.Equal as reflect.DeepEqual also compares the precomputed values
+func equalSettings(a, b Settings) bool {
+	if (a.private == nil) != (b.private == nil) || a.private != nil && !a.private.Equal(b.private) {
+		return false
+	}
+	return a.kid == b.kid && a.jku == b.jku && a.signer == b.signer
+}
+
 func TestNewSettings(t *testing.T) {
+	defer allowTestKeys()()
 	type args struct {
 		key   []byte
 		keyid string
@@ -30,8 +51,8 @@ func TestNewSettings(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"PKCS1 key", args{pkcs1, "key_id"}, Settings{priv, "key_id", ""}, false},
-		{"PKCS8 key", args{pkcs8, "key_id"}, Settings{priv, "key_id", ""}, false},
+		{"PKCS1 key", args{pkcs1, "key_id"}, Settings{priv, "key_id", "", nil}, false},
+		{"PKCS8 key", args{pkcs8, "key_id"}, Settings{priv, "key_id", "", nil}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -40,7 +61,7 @@ func TestNewSettings(t *testing.T) {
 				t.Errorf("NewSettings() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !equalSettings(got, tt.want) {
 				t.Errorf("NewSettings() = %v, want %v", got, tt.want)
 			}
 		})
@@ -48,6 +69,7 @@ func TestNewSettings(t *testing.T) {
 }
 
 func TestNewSettingsWithKeyURL(t *testing.T) {
+	defer allowTestKeys()()
 	type args struct {
 		key    []byte
 		keyid  string
@@ -59,8 +81,8 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 		want    Settings
 		wantErr bool
 	}{
-		{"PKCS1 key", args{pkcs1, "key_id", "key_url"}, Settings{priv, "key_id", "key_url"}, false},
-		{"PKCS8 key", args{pkcs8, "key_id", "key_url"}, Settings{priv, "key_id", "key_url"}, false},
+		{"PKCS1 key", args{pkcs1, "key_id", "key_url"}, Settings{priv, "key_id", "key_url", nil}, false},
+		{"PKCS8 key", args{pkcs8, "key_id", "key_url"}, Settings{priv, "key_id", "key_url", nil}, false},
 		{"Invalid", args{[]byte("invalid key"), "key_id", "key_url"}, Settings{}, true},
 		{"PKCS8 EC key", args{pkcs8EC, "key_id", "key_url"}, Settings{}, true},
 	}
@@ -71,7 +93,7 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 				t.Errorf("NewSettingsWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !equalSettings(got, tt.want) {
 				t.Errorf("NewSettingsWithKeyURL() = %v, want %v", got, tt.want)
 			}
 		})
@@ -79,6 +101,7 @@ func TestNewSettingsWithKeyURL(t *testing.T) {
 }
 
 func TestProvider_AddPublicKey(t *testing.T) {
+	defer allowTestKeys()()
 	type args struct {
 		key publickey.PublicKey
 	}
@@ -90,6 +113,9 @@ func TestProvider_AddPublicKey(t *testing.T) {
 	}{
 		{"Normal", &Provider{keys: make(map[string]*rsa.PublicKey)}, args{publickey.New(pkix, "key_id")}, false},
 		{"Already exists", &Provider{keys: map[string]*rsa.PublicKey{"key_id": &pub}}, args{publickey.New(pkix, "key_id")}, true},
+		{"Matching metadata", &Provider{alg: PS256, keys: make(map[string]*rsa.PublicKey)}, args{publickey.New(pkix, "key_id").WithAlgorithm("PS256").WithKeyType("RSA").WithUse("sig").WithKeyOps("sign", "verify")}, false},
+		{"Other algorithm", &Provider{alg: PS256, keys: make(map[string]*rsa.PublicKey)}, args{publickey.New(pkix, "key_id").WithAlgorithm("RS256")}, true},
+		{"Encryption key", &Provider{alg: PS256, keys: make(map[string]*rsa.PublicKey)}, args{publickey.New(pkix, "key_id").WithUse("enc")}, true},
 		{"Invalid", &Provider{keys: make(map[string]*rsa.PublicKey)}, args{publickey.New([]byte("invalid key"), "key_id")}, true},
 		{"PKIX RSA key", &Provider{keys: make(map[string]*rsa.PublicKey)}, args{publickey.New(pkixEC, "key_id")}, true},
 	}
@@ -134,3 +160,60 @@ func TestProvider_CurrentKey(t *testing.T) {
 		})
 	}
 }
+
+// testSigner stands in for an agent or a KMS client. The private key is only reachable through the sign function so it cannot end up in the provider.
+type testSigner struct {
+	public crypto.PublicKey
+	sign   func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error)
+	calls  int
+}
+
+func newTestSigner(key crypto.Signer) *testSigner {
+	return &testSigner{public: key.Public(), sign: key.Sign}
+}
+
+func (s *testSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *testSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	return s.sign(rand, digest, opts)
+}
+
+func TestNewSettingsFromSigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	_, wrong, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		want    Settings
+		wantErr bool
+	}{
+		{"Signer", signer, Settings{nil, "key_id", "key_url", signer}, false},
+		{"Wrong key type", newTestSigner(wrong), Settings{}, true},
+		{"No signer", nil, Settings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettingsFromSignerWithKeyURL(tt.signer, "key_id", "key_url")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSettingsFromSignerWithKeyURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got, err := NewSettingsFromSigner(signer, "key_id"); err != nil || got.signer != signer || got.kid != "key_id" || got.jku != "" {
+		t.Errorf("NewSettingsFromSigner() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,169 @@
+package ps
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestSettings_ExportPEM(t *testing.T) {
+	p, err := NewProviderWithKeyURL(PS256, "key_url")
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	enc, err := p.Settings().ExportPEM()
+	if err != nil {
+		t.Fatalf("Settings.ExportPEM() failed: %s", err.Error())
+	}
+	s, err := NewSettingsFromPEM(enc)
+	if err != nil {
+		t.Fatalf("NewSettingsFromPEM() failed: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettingsFromPEM() = %+v, want %+v", s, p.Settings())
+	}
+	der, err := p.Settings().Export()
+	if err != nil {
+		t.Fatalf("Settings.Export() failed: %s", err.Error())
+	}
+	s, err = NewSettingsWithKeyURL(der, p.Settings().kid, "key_url")
+	if err != nil {
+		t.Fatalf("NewSettings() failed for exported key: %s", err.Error())
+	}
+	if !reflect.DeepEqual(s, p.Settings()) {
+		t.Errorf("NewSettings() = %+v, want %+v", s, p.Settings())
+	}
+}
+
+func TestNewSettingsFromPEM(t *testing.T) {
+	defer allowTestKeys()()
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"PKCS8", pemutil.Encode("PRIVATE KEY", pkcs8, "key_id", ""), false},
+		{"PKCS1", pemutil.Encode("RSA PRIVATE KEY", pkcs1, "key_id", ""), false},
+		{"Public key", pemutil.Encode("PUBLIC KEY", pkix, "key_id", ""), true},
+		{"EC key", pemutil.Encode("PRIVATE KEY", pkcs8EC, "key_id", ""), true},
+		{"Invalid key", pemutil.Encode("PRIVATE KEY", []byte("test"), "key_id", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSettingsFromPEM(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSettingsFromPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s.kid != "key_id" {
+				t.Errorf("NewSettingsFromPEM() read key ID %q, want %q", s.kid, "key_id")
+			}
+		})
+	}
+}
+
+func TestEncodePublicKeyPEM(t *testing.T) {
+	defer allowTestKeys()()
+	tests := []struct {
+		name    string
+		key     publickey.PublicKey
+		wantErr bool
+	}{
+		{"RSA key", publickey.New(pkix, "key_id"), false},
+		{"EC key", publickey.New(pkixEC, "key_id"), true},
+		{"Invalid key", publickey.New([]byte("test"), "key_id"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := EncodePublicKeyPEM(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodePublicKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			key, err := DecodePublicKeyPEM(enc)
+			if err != nil {
+				t.Fatalf("DecodePublicKeyPEM() failed: %s", err.Error())
+			}
+			if !reflect.DeepEqual(key, tt.key) {
+				t.Errorf("DecodePublicKeyPEM() = %v, want %v", key, tt.key)
+			}
+		})
+	}
+}
+
+func TestDecodePublicKeyPEM(t *testing.T) {
+	defer allowTestKeys()()
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"RSA key", pemutil.Encode("PUBLIC KEY", pkix, "key_id", ""), false},
+		{"Private key", pemutil.Encode("PRIVATE KEY", pkcs8, "key_id", ""), true},
+		{"EC key", pemutil.Encode("PUBLIC KEY", pkixEC, "key_id", ""), true},
+		{"Invalid key", pemutil.Encode("PUBLIC KEY", []byte("test"), "key_id", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodePublicKeyPEM(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("DecodePublicKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// The handling of the directory itself is tested in internal/pemutil
+func TestLoadProviderFromDir(t *testing.T) {
+	defer allowTestKeys()()
+	keys := map[string][]byte{
+		"signing.pem":        pemutil.Encode("PRIVATE KEY", pkcs8, "signing", ""),
+		"signing_public.pem": pemutil.Encode("PUBLIC KEY", pkix, "signing", ""),
+		"other.pem":          pemutil.Encode("PUBLIC KEY", pkix, "other", ""),
+	}
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		alg     Algorithm
+		wantErr bool
+	}{
+		{"PS256", keys, PS256, false},
+		{"PS512", keys, PS512, false},
+		{"PKCS1", map[string][]byte{"signing.pem": pemutil.Encode("RSA PRIVATE KEY", pkcs1, "signing", "")}, PS256, false},
+		{"Unknown algorithm", keys, 12, true},
+		{"EC public key", map[string][]byte{"signing.pem": keys["signing.pem"], "other.pem": pemutil.Encode("PUBLIC KEY", pkixEC, "other", "")}, PS256, true},
+		{"EC private key", map[string][]byte{"signing.pem": pemutil.Encode("PRIVATE KEY", pkcs8EC, "signing", "")}, PS256, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "jwt")
+			if err != nil {
+				t.Fatalf("Could not create directory: %s", err.Error())
+			}
+			defer os.RemoveAll(dir) // nolint:errcheck
+			for name, data := range tt.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+					t.Fatalf("Could not write file: %s", err.Error())
+				}
+			}
+			p, err := LoadProviderFromDir(dir, tt.alg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadProviderFromDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.CurrentKey().GetKeyID() != "signing" {
+				t.Errorf("LoadProviderFromDir() loaded signing key %q, want %q", p.CurrentKey().GetKeyID(), "signing")
+			}
+			if _, ok := tt.files["other.pem"]; ok && !reflect.DeepEqual(p.keys["other"], &pub) {
+				t.Errorf("LoadProviderFromDir() added public key %v, want %v", p.keys["other"], &pub)
+			}
+		})
+	}
+}
@@ -7,23 +7,29 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"errors"
+	"io"
+	"time"
 
 	"github.com/fossoreslp/go-jwt"
-	"github.com/fossoreslp/go-uuid-v4"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
+// Algorithm identifies one of the RSA-PSS signature algorithms
+type Algorithm int
+
 const (
 	// PS256 is RSASSA-PSS using SHA-256 and MGF1 with SHA-256
-	PS256 = 1
+	PS256 Algorithm = 1
 
 	// PS384 is RSASSA-PSS using SHA-384 and MGF1 with SHA-384
-	PS384 = 2
+	PS384 Algorithm = 2
 
 	// PS512 is RSASSA-PSS using SHA-512 and MGF1 with SHA-512
-	PS512 = 3
+	PS512 Algorithm = 3
 )
 
-func algToString(alg int) string {
+func algToString(alg Algorithm) string {
 	switch alg {
 	case PS256:
 		return "PS256"
@@ -36,6 +42,11 @@ func algToString(alg int) string {
 	}
 }
 
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
 // init is only here to make sure the imports for SHA256, SHA384 and SHA512 are not removed automatically and the are therefore available to hash.Hash
 func init() {
 	_ = sha256.New()
@@ -50,24 +61,39 @@ var (
 
 // Provider provides RSASSA-PSS using SHA2 and MGF1 with SHA2 JWS signing and verification
 type Provider struct {
-	alg      int
+	alg      Algorithm
 	pssopts  *rsa.PSSOptions
 	settings Settings
 	keys     map[string]*rsa.PublicKey
+	resolver jwt.KeyResolver
+	random   io.Reader
+	validity map[string]publickey.Validity
 }
 
+var _ jwt.KeyedSignatureProvider = (*Provider)(nil)
+
 // NewProvider creates a new Provider generating the necessary keypairs
-func NewProvider(t int) (Provider, error) {
+func NewProvider(t Algorithm) (Provider, error) {
 	return NewProviderWithKeyURL(t, "")
 }
 
 // NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
-func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
-	kid, err := uuid.NewString()
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key, the key ID and signing from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	kid, err := keyid.New(source)
 	if err != nil {
 		return Provider{}, err
 	}
-	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	k, err := rsa.GenerateKey(source, generatedKeySize())
 	if err != nil {
 		return Provider{}, err
 	}
@@ -77,33 +103,49 @@ func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
 	}
 	switch t {
 	case PS256:
-		return Provider{PS256, ps256opts, Settings{k, kid, keyURL}, m}, nil
+		return Provider{PS256, ps256opts, Settings{k, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
 	case PS384:
-		return Provider{PS384, ps384opts, Settings{k, kid, keyURL}, m}, nil
+		return Provider{PS384, ps384opts, Settings{k, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
 	case PS512:
-		return Provider{PS512, ps512opts, Settings{k, kid, keyURL}, m}, nil
+		return Provider{PS512, ps512opts, Settings{k, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
 	default:
 		return Provider{}, errors.New("type string invalid")
 	}
 }
 
 // LoadProvider returns a Provider using the supplied keypairs
-func LoadProvider(s Settings, t int) (Provider, error) {
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if err := checkKeySize(s.publicKey()); err != nil {
+		return Provider{}, err
+	}
 	m := map[string]*rsa.PublicKey{
-		s.kid: &s.private.PublicKey,
-		"":    &s.private.PublicKey,
+		s.kid: s.publicKey(),
+		"":    s.publicKey(),
 	}
 	switch t {
 	case PS256:
-		return Provider{PS256, ps256opts, s, m}, nil
+		return Provider{PS256, ps256opts, s, m, nil, nil, make(map[string]publickey.Validity)}, nil
 	case PS384:
-		return Provider{PS384, ps384opts, s, m}, nil
+		return Provider{PS384, ps384opts, s, m, nil, nil, make(map[string]publickey.Validity)}, nil
 	case PS512:
-		return Provider{PS512, ps512opts, s, m}, nil
+		return Provider{PS512, ps512opts, s, m, nil, nil, make(map[string]publickey.Validity)}, nil
 	}
 	return Provider{}, errors.New("type string invalid")
 }
 
+// SetRandom sets the source of randomness used for signing. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for signing
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
 // Header sets the necessary JWT header fields
 func (p Provider) Header(h *jwt.Header) {
 	h.Alg = algToString(p.alg)
@@ -120,7 +162,10 @@ func (p Provider) Sign(c []byte) ([]byte, error) {
 	hash := p.pssopts.Hash.New()
 	// SHA2 does not return errors
 	hash.Write(c) // nolint:errcheck
-	sum, err := rsa.SignPSS(rand.Reader, p.settings.private, p.pssopts.Hash, hash.Sum(nil), p.pssopts)
+	if p.settings.signer != nil {
+		return p.settings.signer.Sign(p.randomSource(), hash.Sum(nil), p.pssopts)
+	}
+	sum, err := rsa.SignPSS(p.randomSource(), p.settings.private, p.pssopts.Hash, hash.Sum(nil), p.pssopts)
 	if err != nil {
 		return nil, err
 	}
@@ -132,11 +177,27 @@ func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
 	hash := p.pssopts.Hash.New()
 	// SHA2 does not return errors
 	hash.Write(data) // nolint:errcheck
+	sum := hash.Sum(nil)
+	keysMu.RLock()
 	pub, ok := p.keys[h.Kid]
+	validity := p.validity[h.Kid]
+	keysMu.RUnlock()
 	if !ok {
-		return errors.New("unknown key id")
+		keys, err := p.resolveKeys(data, h)
+		if err != nil {
+			return err
+		}
+		for _, pub := range keys {
+			if rsa.VerifyPSS(pub, p.pssopts.Hash, sum, sig, p.pssopts) == nil {
+				return nil
+			}
+		}
+		return errors.New("signature invalid")
+	}
+	if err := validity.Check(time.Now()); err != nil {
+		return err
 	}
-	if rsa.VerifyPSS(pub, p.pssopts.Hash, hash.Sum(nil), sig, p.pssopts) == nil {
+	if rsa.VerifyPSS(pub, p.pssopts.Hash, sum, sig, p.pssopts) == nil {
 		return nil
 	}
 	return errors.New("signature invalid")
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
 func TestNewProviderWithKeyURL(t *testing.T) {
@@ -39,12 +40,13 @@ func TestNewProviderWithKeyURL(t *testing.T) {
 }
 
 func TestLoadProvider(t *testing.T) {
+	defer allowTestKeys()()
 	m := map[string]*rsa.PublicKey{
 		"": &pub,
 	}
 	type args struct {
 		s Settings
-		t int
+		t Algorithm
 	}
 	tests := []struct {
 		name    string
@@ -52,9 +54,9 @@ func TestLoadProvider(t *testing.T) {
 		want    Provider
 		wantErr bool
 	}{
-		{"PS256", args{Settings{private: priv}, PS256}, Provider{PS256, ps256opts, Settings{private: priv}, m}, false},
-		{"PS384", args{Settings{private: priv}, PS384}, Provider{PS384, ps384opts, Settings{private: priv}, m}, false},
-		{"PS512", args{Settings{private: priv}, PS512}, Provider{PS512, ps512opts, Settings{private: priv}, m}, false},
+		{"PS256", args{Settings{private: priv}, PS256}, Provider{PS256, ps256opts, Settings{private: priv}, m, nil, nil, make(map[string]publickey.Validity)}, false},
+		{"PS384", args{Settings{private: priv}, PS384}, Provider{PS384, ps384opts, Settings{private: priv}, m, nil, nil, make(map[string]publickey.Validity)}, false},
+		{"PS512", args{Settings{private: priv}, PS512}, Provider{PS512, ps512opts, Settings{private: priv}, m, nil, nil, make(map[string]publickey.Validity)}, false},
 		{"Unknown type", args{Settings{private: priv}, 12}, Provider{}, true},
 	}
 	for _, tt := range tests {
@@ -115,3 +117,21 @@ func TestProvider_Verify(t *testing.T) {
 		t.Error("Verify() did not return an error when encountering an invalid signature")
 	}
 }
+
+func TestProvider_SetRandom(t *testing.T) {
+	if _, err := NewProviderWithRandom(PS256, "key_url", bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	p, err := NewProviderWithRandom(PS256, "key_url", rand.Reader)
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	p.SetRandom(bytes.NewReader(nil))
+	if _, err := p.Sign([]byte("test")); err == nil {
+		t.Error("Sign() did not use the random source set by SetRandom()")
+	}
+	p.SetRandom(nil)
+	if _, err := p.Sign([]byte("test")); err != nil {
+		t.Errorf("Sign() failed after resetting the random source: %s", err.Error())
+	}
+}
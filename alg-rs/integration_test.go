@@ -1,10 +1,16 @@
 package rs
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/pem"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/providertest"
 )
 
 func TestRS256(t *testing.T) {
@@ -14,7 +20,9 @@ func TestRS256(t *testing.T) {
 	}
 	b := pem.Block{Type: "PUBLIC KEY", Headers: nil, Bytes: p.CurrentKey().GetPublicKey()}
 	t.Logf("Created provider with key: %s", string(pem.EncodeToMemory(&b)))
-	jwt.SetSignatureProvider("RS256", p)
+	if err := jwt.SetSignatureProvider("RS256", p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm("RS256") // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -38,7 +46,9 @@ func TestRS384(t *testing.T) {
 	}
 	b := pem.Block{Type: "PUBLIC KEY", Headers: nil, Bytes: p.CurrentKey().GetPublicKey()}
 	t.Logf("Created provider with key: %s", string(pem.EncodeToMemory(&b)))
-	jwt.SetSignatureProvider("RS384", p)
+	if err := jwt.SetSignatureProvider("RS384", p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm("RS384") // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -62,7 +72,9 @@ func TestRS512(t *testing.T) {
 	}
 	b := pem.Block{Type: "PUBLIC KEY", Headers: nil, Bytes: p.CurrentKey().GetPublicKey()}
 	t.Logf("Created provider with key: %s", string(pem.EncodeToMemory(&b)))
-	jwt.SetSignatureProvider("RS512", p)
+	if err := jwt.SetSignatureProvider("RS512", p); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	jwt.SetSigningAlgorithm("RS512") // nolint:errcheck
 	token := jwt.New([]byte(`{"test": 1}`))
 	res, err := token.Encode()
@@ -78,3 +90,64 @@ func TestRS512(t *testing.T) {
 		t.Errorf("Decoded JWT could not be validated: %s", dec.ValidationError().Error())
 	}
 }
+
+func TestKeyResolver(t *testing.T) {
+	signer, err := NewProvider(RS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(RS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.KeyResolver(t, &signer, &verifier, jwt.Header{Alg: "RS256"})
+}
+
+func TestSigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	signer := newTestSigner(key)
+	s, err := NewSettingsFromSigner(signer, "key_id")
+	if err != nil {
+		t.Fatalf("NewSettingsFromSigner() failed: %s", err.Error())
+	}
+	p, err := LoadProvider(s, RS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	if _, err := p.Settings().Export(); err == nil {
+		t.Error("Settings.Export() should fail when using a signer")
+	}
+	if _, err := p.Settings().ExportPEM(); err == nil {
+		t.Error("Settings.ExportPEM() should fail when using a signer")
+	}
+	local, err := LoadProvider(Settings{key, "key_id", "", nil}, RS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.Signer(t, &p, &local, jwt.Header{Alg: "RS256"})
+	if signer.calls != 1 {
+		t.Errorf("Signer should have been called once but was called %d times", signer.calls)
+	}
+
+	signer.sign = func(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+		return nil, errors.New("signer unavailable")
+	}
+	if _, err := p.Sign([]byte("test")); err == nil {
+		t.Error("Provider.Sign() should fail when the signer fails")
+	}
+}
+
+func TestKeyValidity(t *testing.T) {
+	signer, err := NewProvider(RS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	verifier, err := NewProvider(RS256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	providertest.KeyValidity(t, &signer, &verifier, jwt.Header{Alg: "RS256"})
+}
@@ -0,0 +1,85 @@
+package rs
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/internal/pemutil"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// keyDir lists the PEM block types used for keys by this package
+var keyDir = pemutil.KeyDir{Private: []string{"PRIVATE KEY", "RSA PRIVATE KEY"}, Public: []string{"PUBLIC KEY"}}
+
+// NewSettingsFromPEM creates new signature settings from a PEM-encoded PKCS1 or PKCS8 private key.
+// Key ID and key URL are read from the Key-ID and Key-URL headers of the PEM block.
+func NewSettingsFromPEM(key []byte) (Settings, error) {
+	b, err := pemutil.DecodeType(key, keyDir.Private...)
+	if err != nil {
+		return Settings{}, err
+	}
+	return NewSettingsWithKeyURL(b.Bytes, b.KeyID, b.KeyURL)
+}
+
+// Export returns the private key encoded as PKCS8 so it can be loaded using NewSettings.
+// It fails for settings using a signer as the private key is not available.
+func (s Settings) Export() ([]byte, error) {
+	if s.private == nil {
+		return nil, errors.New("settings do not contain a private key")
+	}
+	return x509.MarshalPKCS8PrivateKey(s.private)
+}
+
+// ExportPEM returns the PEM-encoded private key including key ID and key URL so it can be loaded using NewSettingsFromPEM
+func (s Settings) ExportPEM() ([]byte, error) {
+	key, err := s.Export()
+	if err != nil {
+		return nil, err
+	}
+	return pemutil.Encode("PRIVATE KEY", key, s.kid, s.jku), nil
+}
+
+// Settings returns the signature settings of the provider which can be used to export the private key
+func (p Provider) Settings() Settings {
+	return p.settings
+}
+
+// EncodePublicKeyPEM returns the PEM-encoded public key including the key ID
+func EncodePublicKeyPEM(key publickey.PublicKey) ([]byte, error) {
+	if _, err := parsePublicKey(key); err != nil {
+		return nil, err
+	}
+	return pemutil.Encode("PUBLIC KEY", key.GetPublicKey(), key.GetKeyID(), ""), nil
+}
+
+// DecodePublicKeyPEM decodes a PEM-encoded PKIX public key reading the key ID from the Key-ID header
+func DecodePublicKeyPEM(key []byte) (publickey.PublicKey, error) {
+	b, err := pemutil.DecodeType(key, keyDir.Public...)
+	if err != nil {
+		return publickey.PublicKey{}, err
+	}
+	pub := publickey.New(b.Bytes, b.KeyID)
+	if _, err := parsePublicKey(pub); err != nil {
+		return publickey.PublicKey{}, err
+	}
+	return pub, nil
+}
+
+// LoadProviderFromDir returns a Provider using the PEM-encoded keys in the files with the extension .pem in dir.
+// The directory has to contain exactly one private key which is used for signing. All public keys are added for verification.
+// Key IDs are read from the Key-ID header and default to the file name without extension.
+func LoadProviderFromDir(dir string, t Algorithm) (Provider, error) {
+	var p Provider
+	err := keyDir.Load(dir, func(b pemutil.Block) (pemutil.KeyAdder, error) {
+		s, err := NewSettingsWithKeyURL(b.Bytes, b.KeyID, b.KeyURL)
+		if err != nil {
+			return nil, err
+		}
+		p, err = LoadProvider(s, t)
+		return &p, err
+	})
+	if err != nil {
+		return Provider{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,47 @@
+package rs
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// minKeySize is the minimum size of the modulus of all keys in bits
+	minKeySize   = 2048
+	minKeySizeMu sync.RWMutex
+)
+
+// SetMinKeySize sets the minimum size of the modulus in bits that keys used for signing and verification must have. It defaults to 2048 as required by RFC 7518 section 3.3.
+// The limit is enforced by NewSettings, LoadProvider and AddPublicKey. Keys returned by a key resolver that are too short are ignored.
+func SetMinKeySize(bits int) error {
+	if bits <= 0 {
+		return errors.New("minimum key size must be positive")
+	}
+	minKeySizeMu.Lock()
+	defer minKeySizeMu.Unlock()
+	minKeySize = bits
+	return nil
+}
+
+// generatedKeySize returns the modulus size used for generating keys which is 2048 bits unless the minimum key size is larger
+func generatedKeySize() int {
+	minKeySizeMu.RLock()
+	defer minKeySizeMu.RUnlock()
+	if minKeySize > 2048 {
+		return minKeySize
+	}
+	return 2048
+}
+
+// checkKeySize returns an error if the modulus of the key is shorter than the minimum key size
+func checkKeySize(key *rsa.PublicKey) error {
+	minKeySizeMu.RLock()
+	min := minKeySize
+	minKeySizeMu.RUnlock()
+	if size := key.N.BitLen(); size < min {
+		return fmt.Errorf("RSA key has %d bits but at least %d bits are required", size, min)
+	}
+	return nil
+}
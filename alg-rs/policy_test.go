@@ -0,0 +1,64 @@
+package rs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestSetMinKeySize(t *testing.T) {
+	if err := SetMinKeySize(0); err == nil {
+		t.Error("SetMinKeySize() should fail for a size of 0")
+	}
+	if err := SetMinKeySize(-1); err == nil {
+		t.Error("SetMinKeySize() should fail for negative sizes")
+	}
+	if minKeySize != 2048 {
+		t.Errorf("SetMinKeySize() changed the minimum key size to %d for invalid input", minKeySize)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err.Error())
+	}
+	pkcs1 := x509.MarshalPKCS1PrivateKey(key)
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Could not encode public key: %s", err.Error())
+	}
+
+	if _, err := NewSettings(pkcs1, "key_id"); err == nil {
+		t.Error("NewSettings() should reject keys shorter than 2048 bits by default")
+	}
+	if _, err := NewSettingsFromSigner(key, "key_id"); err == nil {
+		t.Error("NewSettingsFromSigner() should reject keys shorter than 2048 bits by default")
+	}
+	if _, err := LoadProvider(Settings{key, "key_id", "", nil}, RS256); err == nil {
+		t.Error("LoadProvider() should reject keys shorter than 2048 bits by default")
+	}
+	p, err := NewProvider(RS256)
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %s", err.Error())
+	}
+	if err := p.AddPublicKey(publickey.New(pkix, "weak")); err == nil {
+		t.Error("AddPublicKey() should reject keys shorter than 2048 bits by default")
+	}
+
+	defer SetMinKeySize(2048) // nolint:errcheck
+	if err := SetMinKeySize(1024); err != nil {
+		t.Fatalf("SetMinKeySize() failed: %s", err.Error())
+	}
+	s, err := NewSettings(pkcs1, "key_id")
+	if err != nil {
+		t.Errorf("NewSettings() failed after lowering the minimum key size: %s", err.Error())
+	}
+	if _, err := LoadProvider(s, RS256); err != nil {
+		t.Errorf("LoadProvider() failed after lowering the minimum key size: %s", err.Error())
+	}
+	if err := p.AddPublicKey(publickey.New(pkix, "weak")); err != nil {
+		t.Errorf("AddPublicKey() failed after lowering the minimum key size: %s", err.Error())
+	}
+}
@@ -7,23 +7,29 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"errors"
+	"io"
+	"time"
 
 	"github.com/fossoreslp/go-jwt"
-	"github.com/fossoreslp/go-uuid-v4"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
+// Algorithm identifies one of the RSA PKCS#1 v1.5 signature algorithms
+type Algorithm int
+
 const (
 	// RS256 is RSA PKCS#1 v1.5 using SHA256
-	RS256 = 1
+	RS256 Algorithm = 1
 
 	// RS384 is RSA PKCS#1 v1.5 using SHA384
-	RS384 = 2
+	RS384 Algorithm = 2
 
 	// RS512 is RSA PKCS#1 v1.5 using SHA512
-	RS512 = 3
+	RS512 Algorithm = 3
 )
 
-func algToString(alg int) string {
+func algToString(alg Algorithm) string {
 	switch alg {
 	case RS256:
 		return "RS256"
@@ -36,6 +42,11 @@ func algToString(alg int) string {
 	}
 }
 
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
 // init is only here to make sure the imports for SHA256, SHA384 and SHA512 are not removed automatically and the are therefore available to hash.Hash
 func init() {
 	_ = sha256.New()
@@ -44,24 +55,39 @@ func init() {
 
 // Provider provides RSA PKCS#1 v1.5 using the selected hashing algorithm JWS signing and verification
 type Provider struct {
-	alg      int
+	alg      Algorithm
 	hash     crypto.Hash
 	settings Settings
 	keys     map[string]*rsa.PublicKey
+	resolver jwt.KeyResolver
+	random   io.Reader
+	validity map[string]publickey.Validity
 }
 
+var _ jwt.KeyedSignatureProvider = (*Provider)(nil)
+
 // NewProvider creates a new Provider generating the necessary keypairs
-func NewProvider(t int) (Provider, error) {
+func NewProvider(t Algorithm) (Provider, error) {
 	return NewProviderWithKeyURL(t, "")
 }
 
 // NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
-func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
-	kid, err := uuid.NewString()
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key, the key ID and signing from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	kid, err := keyid.New(source)
 	if err != nil {
 		return Provider{}, err
 	}
-	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	k, err := rsa.GenerateKey(source, generatedKeySize())
 	if err != nil {
 		return Provider{}, err
 	}
@@ -71,33 +97,49 @@ func NewProviderWithKeyURL(t int, keyURL string) (Provider, error) {
 	}
 	switch t {
 	case RS256:
-		return Provider{RS256, crypto.SHA256, Settings{k, kid, keyURL}, m}, nil
+		return Provider{RS256, crypto.SHA256, Settings{k, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
 	case RS384:
-		return Provider{RS384, crypto.SHA384, Settings{k, kid, keyURL}, m}, nil
+		return Provider{RS384, crypto.SHA384, Settings{k, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
 	case RS512:
-		return Provider{RS512, crypto.SHA512, Settings{k, kid, keyURL}, m}, nil
+		return Provider{RS512, crypto.SHA512, Settings{k, kid, keyURL, nil}, m, nil, random, make(map[string]publickey.Validity)}, nil
 	default:
 		return Provider{}, errors.New("type string invalid")
 	}
 }
 
 // LoadProvider returns a Provider using the supplied keypairs
-func LoadProvider(s Settings, t int) (Provider, error) {
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if err := checkKeySize(s.publicKey()); err != nil {
+		return Provider{}, err
+	}
 	m := map[string]*rsa.PublicKey{
-		s.kid: &s.private.PublicKey,
-		"":    &s.private.PublicKey,
+		s.kid: s.publicKey(),
+		"":    s.publicKey(),
 	}
 	switch t {
 	case RS256:
-		return Provider{RS256, crypto.SHA256, s, m}, nil
+		return Provider{RS256, crypto.SHA256, s, m, nil, nil, make(map[string]publickey.Validity)}, nil
 	case RS384:
-		return Provider{RS384, crypto.SHA384, s, m}, nil
+		return Provider{RS384, crypto.SHA384, s, m, nil, nil, make(map[string]publickey.Validity)}, nil
 	case RS512:
-		return Provider{RS512, crypto.SHA512, s, m}, nil
+		return Provider{RS512, crypto.SHA512, s, m, nil, nil, make(map[string]publickey.Validity)}, nil
 	}
 	return Provider{}, errors.New("type string invalid")
 }
 
+// SetRandom sets the source of randomness used for signing. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for signing
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
 // Header sets the necessary JWT header fields
 func (p Provider) Header(h *jwt.Header) {
 	h.Alg = algToString(p.alg)
@@ -114,7 +156,10 @@ func (p Provider) Sign(c []byte) ([]byte, error) {
 	hash := p.hash.New()
 	// SHA2 does not return errors
 	hash.Write(c) // nolint:errcheck
-	sum, err := rsa.SignPKCS1v15(rand.Reader, p.settings.private, p.hash, hash.Sum(nil))
+	if p.settings.signer != nil {
+		return p.settings.signer.Sign(p.randomSource(), hash.Sum(nil), p.hash)
+	}
+	sum, err := rsa.SignPKCS1v15(p.randomSource(), p.settings.private, p.hash, hash.Sum(nil))
 	if err != nil {
 		return nil, err
 	}
@@ -126,11 +171,27 @@ func (p Provider) Verify(data, sig []byte, h jwt.Header) error {
 	hash := p.hash.New()
 	// SHA2 does not return errors
 	hash.Write(data) // nolint:errcheck
+	sum := hash.Sum(nil)
+	keysMu.RLock()
 	pub, ok := p.keys[h.Kid]
+	validity := p.validity[h.Kid]
+	keysMu.RUnlock()
 	if !ok {
-		return errors.New("unknown key id")
+		keys, err := p.resolveKeys(data, h)
+		if err != nil {
+			return err
+		}
+		for _, pub := range keys {
+			if rsa.VerifyPKCS1v15(pub, p.hash, sum, sig) == nil {
+				return nil
+			}
+		}
+		return errors.New("signature invalid")
+	}
+	if err := validity.Check(time.Now()); err != nil {
+		return err
 	}
-	if rsa.VerifyPKCS1v15(pub, p.hash, hash.Sum(nil), sig) == nil {
+	if rsa.VerifyPKCS1v15(pub, p.hash, sum, sig) == nil {
 		return nil
 	}
 	return errors.New("signature invalid")
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
 )
 
 func TestNewProviderWithKeyURL(t *testing.T) {
@@ -40,12 +41,13 @@ func TestNewProviderWithKeyURL(t *testing.T) {
 }
 
 func TestLoadProvider(t *testing.T) {
+	defer allowTestKeys()()
 	m := map[string]*rsa.PublicKey{
 		"": &pub,
 	}
 	type args struct {
 		s Settings
-		t int
+		t Algorithm
 	}
 	tests := []struct {
 		name    string
@@ -53,9 +55,9 @@ func TestLoadProvider(t *testing.T) {
 		want    Provider
 		wantErr bool
 	}{
-		{"RS256", args{Settings{private: priv}, RS256}, Provider{RS256, crypto.SHA256, Settings{private: priv}, m}, false},
-		{"RS384", args{Settings{private: priv}, RS384}, Provider{RS384, crypto.SHA384, Settings{private: priv}, m}, false},
-		{"RS512", args{Settings{private: priv}, RS512}, Provider{RS512, crypto.SHA512, Settings{private: priv}, m}, false},
+		{"RS256", args{Settings{private: priv}, RS256}, Provider{RS256, crypto.SHA256, Settings{private: priv}, m, nil, nil, make(map[string]publickey.Validity)}, false},
+		{"RS384", args{Settings{private: priv}, RS384}, Provider{RS384, crypto.SHA384, Settings{private: priv}, m, nil, nil, make(map[string]publickey.Validity)}, false},
+		{"RS512", args{Settings{private: priv}, RS512}, Provider{RS512, crypto.SHA512, Settings{private: priv}, m, nil, nil, make(map[string]publickey.Validity)}, false},
 		{"Unknown type", args{Settings{private: priv}, 12}, Provider{}, true},
 	}
 	for _, tt := range tests {
@@ -117,3 +119,19 @@ func TestProvider_Verify(t *testing.T) {
 		t.Error("Verify() did not return an error when encountering an invalid signature")
 	}
 }
+
+func TestNewProviderWithRandom(t *testing.T) {
+	if _, err := NewProviderWithRandom(RS256, "key_url", bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	p, err := NewProviderWithRandom(RS256, "key_url", rand.Reader)
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	if p.settings.jku != "key_url" {
+		t.Errorf("NewProviderWithRandom() failed to set key url: should be \"key_url\" but is %q", p.settings.jku)
+	}
+	if p.random != rand.Reader {
+		t.Error("NewProviderWithRandom() did not store the random source for signing")
+	}
+}
@@ -0,0 +1,50 @@
+package rsaoaep
+
+import (
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+func TestEncryption(t *testing.T) {
+	for _, alg := range []Algorithm{RSAOAEP, RSAOAEP256} {
+		t.Run(algToString(alg), func(t *testing.T) {
+			recipient, err := NewProvider(alg)
+			if err != nil {
+				t.Fatalf("Could not initialize provider: %s", err.Error())
+			}
+			sender, err := NewProviderWithKeyURL(alg, "key_url")
+			if err != nil {
+				t.Fatalf("Could not initialize provider: %s", err.Error())
+			}
+			if err := sender.SetRecipient(recipient.CurrentKey()); err != nil {
+				t.Fatalf("Could not set recipient: %s", err.Error())
+			}
+			jwt.SetEncryptionProvider(algToString(alg), sender)
+			jwt.SetEncryptionAlgorithm(algToString(alg)) // nolint:errcheck
+			token, err := jwt.New([]byte(`{"test": 1}`)).EncodeEncrypted()
+			if err != nil {
+				t.Fatalf("Could not encrypt JWT: %s", err.Error())
+			}
+			t.Logf("JWT encrypted to: %s", string(token))
+			dec, err := jwt.DecodeEncrypted(token)
+			if err != nil {
+				t.Fatalf("Could not decode JWT: %s", err.Error())
+			}
+			if dec.Valid() {
+				t.Error("Sender should not be able to decrypt JWT encrypted for recipient")
+			}
+			jwt.SetEncryptionProvider(algToString(alg), recipient)
+			dec, err = jwt.DecodeEncrypted(token)
+			if err != nil {
+				t.Fatalf("Could not decode JWT: %s", err.Error())
+			}
+			if !dec.Valid() {
+				t.Errorf("Decoded JWT could not be decrypted: %s", dec.ValidationError().Error())
+			}
+			if string(dec.Content) != `{"test": 1}` {
+				t.Errorf("Decrypted content %s does not match original content", dec.Content)
+			}
+		})
+	}
+}
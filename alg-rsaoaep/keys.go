@@ -0,0 +1,63 @@
+package rsaoaep
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Settings stores the key for an algorithm
+type Settings struct {
+	private *rsa.PrivateKey
+	kid     string
+	jku     string
+}
+
+// NewSettings creates new settings for the parameters
+func NewSettings(key []byte, keyID string) (Settings, error) {
+	return NewSettingsWithKeyURL(key, keyID, "")
+}
+
+// NewSettingsWithKeyURL creates new settings for the parameters
+func NewSettingsWithKeyURL(key []byte, keyID, keyURL string) (Settings, error) {
+	rsaKey, err := x509.ParsePKCS1PrivateKey(key)
+	if err != nil {
+		k, err := x509.ParsePKCS8PrivateKey(key)
+		if err != nil {
+			return Settings{}, errors.New("could not decode private key as either PKCS1 or PKCS8")
+		}
+		castKey, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return Settings{}, errors.New("PKCS8 does not contain a RSA private key")
+		}
+		rsaKey = castKey
+	}
+	return Settings{rsaKey, keyID, keyURL}, nil
+}
+
+// SetRecipient sets the public key tokens will be encrypted for. The key has to be encoded as PKIX.
+func (p *Provider) SetRecipient(key publickey.PublicKey) error {
+	if err := key.CheckUsage(algToString(p.alg), "RSA", "enc", "wrapKey"); err != nil {
+		return err
+	}
+	pub, err := x509.ParsePKIXPublicKey(key.GetPublicKey())
+	if err != nil {
+		return errors.New("could not decode public key")
+	}
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("public key is not a RSA public key")
+	}
+	p.recipient = rsaKey
+	p.kid = key.GetKeyID()
+	p.jku = ""
+	return nil
+}
+
+// CurrentKey returns the public key belonging to the private key used for decryption. Other parties can use it to encrypt tokens for this provider.
+func (p Provider) CurrentKey() publickey.PublicKey {
+	b, _ := x509.MarshalPKIXPublicKey(&p.settings.private.PublicKey) // Marshaling an RSA public key should never fail
+	return publickey.New(b, p.settings.kid)
+}
@@ -0,0 +1,76 @@
+package rsaoaep
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestNewSettings(t *testing.T) {
+	pkcs1 := x509.MarshalPKCS1PrivateKey(testKey)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(testKey)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"PKCS1", pkcs1, false},
+		{"PKCS8", pkcs8, false},
+		{"PKCS8NotRSA", ec, true},
+		{"Invalid", []byte("key"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettings(tt.key, "key_id")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (got.private.D.Cmp(testKey.D) != 0 || got.kid != "key_id") {
+				t.Errorf("NewSettings() did not return the expected settings")
+			}
+		})
+	}
+}
+
+func TestProvider_SetRecipient(t *testing.T) {
+	p, err := LoadProvider(Settings{testKey, "key_id", "key_url"}, RSAOAEP)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if p.SetRecipient(publickey.New([]byte("key"), "invalid")) == nil {
+		t.Error("Provider.SetRecipient() should fail for invalid public key")
+	}
+	if p.SetRecipient(publickey.New(ec, "ec")) == nil {
+		t.Error("Provider.SetRecipient() should fail for non RSA public key")
+	}
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	b, _ := x509.MarshalPKIXPublicKey(&other.PublicKey)
+	if err := p.SetRecipient(publickey.New(b, "recipient")); err != nil {
+		t.Fatalf("Provider.SetRecipient() failed: %s", err.Error())
+	}
+	if p.recipient.N.Cmp(other.N) != 0 || p.kid != "recipient" || p.jku != "" {
+		t.Error("Provider.SetRecipient() did not set the recipient")
+	}
+}
+
+func TestProvider_CurrentKey(t *testing.T) {
+	p, _ := LoadProvider(Settings{testKey, "key_id", ""}, RSAOAEP)
+	k := p.CurrentKey()
+	pub, err := x509.ParsePKIXPublicKey(k.GetPublicKey())
+	if err != nil {
+		t.Fatalf("Provider.CurrentKey() returned invalid key: %s", err.Error())
+	}
+	if pub.(*rsa.PublicKey).N.Cmp(testKey.N) != 0 || k.GetKeyID() != "key_id" {
+		t.Errorf("Provider.CurrentKey() returned unexpected key")
+	}
+}
@@ -0,0 +1,161 @@
+package rsaoaep
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/internal/keyid"
+)
+
+// Algorithm identifies one of the RSA-OAEP key encryption algorithms
+type Algorithm int
+
+const (
+	// RSAOAEP is RSAES OAEP using SHA-1 and MGF1 with SHA-1
+	RSAOAEP Algorithm = 1
+
+	// RSAOAEP256 is RSAES OAEP using SHA-256 and MGF1 with SHA-256
+	RSAOAEP256 Algorithm = 2
+)
+
+func algToString(alg Algorithm) string {
+	switch alg {
+	case RSAOAEP:
+		return "RSA-OAEP"
+	case RSAOAEP256:
+		return "RSA-OAEP-256"
+	default:
+		return ""
+	}
+}
+
+// String returns the name of the algorithm as used in the alg header parameter
+func (a Algorithm) String() string {
+	return algToString(a)
+}
+
+// init is only here to make sure the imports for SHA1 and SHA256 are not removed automatically and are therefore available to hash.Hash
+func init() {
+	_ = sha1.New() // nolint:gosec
+	_ = sha256.New()
+}
+
+// Provider provides RSAES OAEP as JWE key management algorithm.
+// Content encryption keys are encrypted for the recipient and decrypted using the private key of the settings.
+type Provider struct {
+	alg       Algorithm
+	hash      crypto.Hash
+	settings  Settings
+	recipient *rsa.PublicKey
+	kid       string
+	jku       string
+	random    io.Reader
+}
+
+var _ jwt.EncryptionProvider = Provider{}
+
+// NewProvider creates a new Provider generating the necessary keypairs
+func NewProvider(t Algorithm) (Provider, error) {
+	return NewProviderWithKeyURL(t, "")
+}
+
+// NewProviderWithKeyURL works just like NewProvider but also sets the key URL of the generated keys
+func NewProviderWithKeyURL(t Algorithm, keyURL string) (Provider, error) {
+	return NewProviderWithRandom(t, keyURL, nil)
+}
+
+// NewProviderWithRandom works just like NewProviderWithKeyURL but reads the randomness needed for the key, the key ID, content encryption keys and RSA blinding from random instead of crypto/rand.
+// Passing nil uses crypto/rand.
+func NewProviderWithRandom(t Algorithm, keyURL string, random io.Reader) (Provider, error) {
+	source := random
+	if source == nil {
+		source = rand.Reader
+	}
+	if algToString(t) == "" {
+		return Provider{}, errors.New("type invalid")
+	}
+	kid, err := keyid.New(source)
+	if err != nil {
+		return Provider{}, err
+	}
+	k, err := rsa.GenerateKey(source, 2048)
+	if err != nil {
+		return Provider{}, err
+	}
+	p, err := LoadProvider(Settings{k, kid, keyURL}, t)
+	if err != nil {
+		return Provider{}, err
+	}
+	p.random = random
+	return p, nil
+}
+
+// LoadProvider returns a Provider using the supplied settings.
+// Tokens will be encrypted for the public key belonging to the private key of the settings until a different recipient is set.
+func LoadProvider(s Settings, t Algorithm) (Provider, error) {
+	if s.private == nil {
+		return Provider{}, errors.New("settings do not contain a private key")
+	}
+	switch t {
+	case RSAOAEP:
+		return Provider{RSAOAEP, crypto.SHA1, s, &s.private.PublicKey, s.kid, s.jku, nil}, nil
+	case RSAOAEP256:
+		return Provider{RSAOAEP256, crypto.SHA256, s, &s.private.PublicKey, s.kid, s.jku, nil}, nil
+	}
+	return Provider{}, errors.New("type invalid")
+}
+
+// SetRandom sets the source of randomness used for content encryption keys and RSA blinding. Passing nil uses crypto/rand which is the default.
+func (p *Provider) SetRandom(random io.Reader) {
+	p.random = random
+}
+
+// randomSource returns the source of randomness used for content encryption keys and RSA blinding
+func (p Provider) randomSource() io.Reader {
+	if p.random == nil {
+		return rand.Reader
+	}
+	return p.random
+}
+
+// Header sets the necessary JWT header fields
+func (p Provider) Header(h *jwt.Header) {
+	h.Alg = algToString(p.alg)
+	if p.kid != "" {
+		h.Kid = p.kid
+	}
+	if p.jku != "" {
+		h.Jku = p.jku
+	}
+}
+
+// WrapKey generates a random content encryption key of the requested size and encrypts it for the recipient
+func (p Provider) WrapKey(size int, h *jwt.Header) ([]byte, []byte, error) {
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(p.randomSource(), cek); err != nil {
+		return nil, nil, err
+	}
+	enc, err := rsa.EncryptOAEP(p.hash.New(), p.randomSource(), p.recipient, cek, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, enc, nil
+}
+
+// UnwrapKey decrypts the content encryption key using the private key
+func (p Provider) UnwrapKey(encryptedKey []byte, size int, h jwt.Header) ([]byte, error) {
+	if h.Kid != "" && h.Kid != p.settings.kid {
+		return nil, errors.New("unknown key id")
+	}
+	cek, err := rsa.DecryptOAEP(p.hash.New(), p.randomSource(), p.settings.private, encryptedKey, nil)
+	if err != nil || len(cek) != size {
+		return nil, errors.New("decryption failed")
+	}
+	return cek, nil
+}
@@ -0,0 +1,139 @@
+package rsaoaep
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+var testKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+func Test_algToString(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  Algorithm
+		want string
+	}{
+		{"RSAOAEP", RSAOAEP, "RSA-OAEP"},
+		{"RSAOAEP256", RSAOAEP256, "RSA-OAEP-256"},
+		{"Invalid", 12, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := algToString(tt.alg); got != tt.want {
+				t.Errorf("algToString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := jwt.Header{Typ: "JWT"}
+	Provider{alg: RSAOAEP256, kid: "key_id", jku: "key_url"}.Header(&h)
+	if h.Alg != "RSA-OAEP-256" {
+		t.Errorf("Provider.Header() should set Alg to \"RSA-OAEP-256\" but instead it is %q", h.Alg)
+	}
+	if h.Kid != "key_id" {
+		t.Errorf("Provider.Header() should set Kid to \"key_id\" but instead it is %q", h.Kid)
+	}
+	if h.Jku != "key_url" {
+		t.Errorf("Provider.Header() should set Jku to \"key_url\" but instead it is %q", h.Jku)
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Settings
+		alg     Algorithm
+		wantErr bool
+	}{
+		{"RSAOAEP", Settings{testKey, "key_id", "key_url"}, RSAOAEP, false},
+		{"RSAOAEP256", Settings{testKey, "key_id", "key_url"}, RSAOAEP256, false},
+		{"UnknownAlgorithm", Settings{testKey, "key_id", "key_url"}, 12, true},
+		{"NoPrivateKey", Settings{nil, "key_id", "key_url"}, RSAOAEP, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadProvider(tt.s, tt.alg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.alg != tt.alg || got.kid != "key_id" || got.jku != "key_url" || got.recipient != &testKey.PublicKey {
+				t.Errorf("LoadProvider() did not pass the data from the input settings onto the provider")
+			}
+		})
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	if _, err := NewProvider(12); err == nil {
+		t.Error("NewProvider() with an unknown algorithm type should fail but returned no error.")
+	}
+}
+
+func TestProvider_UnwrapKey(t *testing.T) {
+	p, err := LoadProvider(Settings{testKey, "key_id", ""}, RSAOAEP256)
+	if err != nil {
+		t.Fatalf("Could not initialize provider: %s", err.Error())
+	}
+	h := jwt.Header{}
+	p.Header(&h)
+	cek, encryptedKey, err := p.WrapKey(32, &h)
+	if err != nil {
+		t.Fatalf("Provider.WrapKey() failed: %s", err.Error())
+	}
+	other, _ := LoadProvider(Settings{testKey, "key_id", ""}, RSAOAEP)
+	tests := []struct {
+		name         string
+		p            Provider
+		encryptedKey []byte
+		size         int
+		kid          string
+		wantErr      bool
+	}{
+		{"Normal", p, encryptedKey, 32, "key_id", false},
+		{"NoKeyID", p, encryptedKey, 32, "", false},
+		{"UnknownKeyID", p, encryptedKey, 32, "unknown", true},
+		{"WrongSize", p, encryptedKey, 16, "key_id", true},
+		{"WrongHash", other, encryptedKey, 32, "key_id", true},
+		{"Modified", p, append([]byte{0x00}, encryptedKey[1:]...), 32, "key_id", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.UnwrapKey(tt.encryptedKey, tt.size, jwt.Header{Kid: tt.kid})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Provider.UnwrapKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, cek) {
+				t.Errorf("Provider.UnwrapKey() = %x, want %x", got, cek)
+			}
+		})
+	}
+}
+
+func TestProvider_SetRandom(t *testing.T) {
+	if _, err := NewProviderWithRandom(RSAOAEP256, "key_url", bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Error("NewProviderWithRandom() should fail when random data is exhausted")
+	}
+	p, err := NewProviderWithRandom(RSAOAEP256, "key_url", rand.Reader)
+	if err != nil {
+		t.Fatalf("NewProviderWithRandom() failed: %s", err.Error())
+	}
+	p.SetRandom(bytes.NewReader(nil))
+	if _, _, err := p.WrapKey(16, &jwt.Header{Alg: algToString(RSAOAEP256), Enc: "A128GCM"}); err == nil {
+		t.Error("WrapKey() did not use the random source set by SetRandom()")
+	}
+	p.SetRandom(nil)
+	if _, _, err := p.WrapKey(16, &jwt.Header{Alg: algToString(RSAOAEP256), Enc: "A128GCM"}); err != nil {
+		t.Errorf("WrapKey() failed after resetting the random source: %s", err.Error())
+	}
+}
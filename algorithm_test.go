@@ -65,7 +65,9 @@ func TestSetAlgorithm(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			SetSignatureProvider(tt.args.name, tt.args.alg)
+			if err := SetSignatureProvider(tt.args.name, tt.args.alg); err != nil {
+				t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+			}
 			if a, ok := signatureProviders[tt.args.name]; !ok || a != tt.args.alg {
 				t.Errorf("SetAlgorithm() failed - want %v but got %v", tt.args.alg, a)
 			}
@@ -74,7 +76,9 @@ func TestSetAlgorithm(t *testing.T) {
 }
 
 func TestDefaultAlgorithm(t *testing.T) {
-	SetSignatureProvider("test", TestAlgorithm("test"))
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	type args struct {
 		name string
 	}
@@ -0,0 +1,33 @@
+package jwt
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// compress compresses the data using DEFLATE as required for the zip header parameter value DEF in RFC 7516 section 4.1.3
+func compress(data []byte) []byte {
+	var buf bytes.Buffer
+	// Creating a writer only fails for invalid compression levels and writing to a buffer does not return errors
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	w.Write(data) // nolint:errcheck
+	w.Close()     // nolint:errcheck
+	return buf.Bytes()
+}
+
+// decompress decompresses data compressed using DEFLATE and fails when the result would be larger than limit
+func decompress(data []byte, limit int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close() // nolint:errcheck
+	out, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, errors.New("content could not be decompressed")
+	}
+	if len(out) > limit {
+		return nil, errors.New("decompressed content exceeds size limit")
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_decompress(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"permission":"read"}`), 100)
+	compressed := compress(data)
+	if len(compressed) >= len(data) {
+		t.Errorf("compress() returned %d bytes for %d bytes of repetitive input", len(compressed), len(data))
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		limit   int
+		want    []byte
+		wantErr bool
+	}{
+		{"Normal", compressed, len(data), data, false},
+		{"AboveLimit", compressed, len(data) - 1, nil, true},
+		{"Invalid", []byte("not compressed"), len(data), nil, true},
+		{"Truncated", compressed[:len(compressed)/2], len(data), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decompress(tt.data, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decompress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("decompress() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
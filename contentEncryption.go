@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// contentEncryption stores the parameters and functions of a content encryption algorithm as defined in RFC 7518 section 5
+type contentEncryption struct {
+	keySize int
+	ivSize  int
+	encrypt func(key, iv, plaintext, aad []byte) ([]byte, []byte, error)
+	decrypt func(key, iv, ciphertext, tag, aad []byte) ([]byte, error)
+}
+
+var contentEncryptions = map[string]contentEncryption{
+	"A128CBC-HS256": cbcHMAC(32, sha256.New),
+	"A192CBC-HS384": cbcHMAC(48, sha512.New384),
+	"A256CBC-HS512": cbcHMAC(64, sha512.New),
+	"A128GCM":       gcm(16),
+	"A192GCM":       gcm(24),
+	"A256GCM":       gcm(32),
+}
+
+// errDecryption is returned for all errors during decryption of the content to avoid revealing details about the failure
+var errDecryption = errors.New("decryption failed")
+
+// cbcHMAC returns AES in CBC mode with HMAC using the hash function as specified in RFC 7518 section 5.2.
+// The first half of the key is used for the MAC while the second half is used for encryption.
+func cbcHMAC(keySize int, h func() hash.Hash) contentEncryption {
+	mac := func(key, iv, ciphertext, aad []byte) []byte {
+		m := hmac.New(h, key[:keySize/2])
+		// Hash functions do not return errors
+		m.Write(aad)                                          // nolint:errcheck
+		m.Write(iv)                                           // nolint:errcheck
+		m.Write(ciphertext)                                   // nolint:errcheck
+		binary.Write(m, binary.BigEndian, uint64(len(aad))*8) // nolint:errcheck
+		return m.Sum(nil)[:keySize/2]
+	}
+	encrypt := func(key, iv, plaintext, aad []byte) ([]byte, []byte, error) {
+		if len(key) != keySize {
+			return nil, nil, errors.New("content encryption key has invalid size")
+		}
+		block, err := aes.NewCipher(key[keySize/2:])
+		if err != nil {
+			return nil, nil, err
+		}
+		// PKCS#7 padding always adds at least one byte
+		pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+		ciphertext := make([]byte, len(plaintext)+pad)
+		copy(ciphertext, plaintext)
+		for i := len(plaintext); i < len(ciphertext); i++ {
+			ciphertext[i] = byte(pad)
+		}
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+		return ciphertext, mac(key, iv, ciphertext, aad), nil
+	}
+	decrypt := func(key, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+		if len(key) != keySize {
+			return nil, errDecryption
+		}
+		if !hmac.Equal(tag, mac(key, iv, ciphertext, aad)) {
+			return nil, errDecryption
+		}
+		if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, errDecryption
+		}
+		block, err := aes.NewCipher(key[keySize/2:])
+		if err != nil {
+			return nil, err
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+		pad := int(plaintext[len(plaintext)-1])
+		if pad == 0 || pad > aes.BlockSize {
+			return nil, errDecryption
+		}
+		for _, b := range plaintext[len(plaintext)-pad:] {
+			if subtle.ConstantTimeByteEq(b, byte(pad)) != 1 {
+				return nil, errDecryption
+			}
+		}
+		return plaintext[:len(plaintext)-pad], nil
+	}
+	return contentEncryption{keySize, aes.BlockSize, encrypt, decrypt}
+}
+
+// gcm returns AES in Galois/Counter Mode as specified in RFC 7518 section 5.3
+func gcm(keySize int) contentEncryption {
+	encrypt := func(key, iv, plaintext, aad []byte) ([]byte, []byte, error) {
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		out := aead.Seal(nil, iv, plaintext, aad)
+		return out[:len(plaintext)], out[len(plaintext):], nil
+	}
+	decrypt := func(key, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(tag) != aead.Overhead() {
+			return nil, errDecryption
+		}
+		plaintext, err := aead.Open(nil, iv, append(ciphertext[:len(ciphertext):len(ciphertext)], tag...), aad)
+		if err != nil {
+			return nil, errDecryption
+		}
+		return plaintext, nil
+	}
+	return contentEncryption{keySize, 12, encrypt, decrypt}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestContentEncryption_A128CBCHS256(t *testing.T) {
+	// Test vector from RFC 7516 appendix A.2
+	plaintext := []byte{76, 105, 118, 101, 32, 108, 111, 110, 103, 32, 97, 110, 100, 32, 112, 114, 111, 115, 112, 101, 114, 46}
+	aad := []byte{101, 121, 74, 104, 98, 71, 99, 105, 79, 105, 74, 83, 85, 48, 69, 120, 88, 122, 85, 105, 76, 67, 74, 108, 98, 109, 77, 105, 79, 105, 74, 66, 77, 84, 73, 52, 81, 48, 74, 68, 76, 85, 104, 84, 77, 106, 85, 50, 73, 110, 48}
+	ciphertext := []byte{40, 57, 83, 181, 119, 33, 133, 148, 198, 185, 243, 24, 152, 230, 6, 75, 129, 223, 127, 19, 210, 82, 183, 230, 168, 33, 215, 104, 143, 112, 56, 102}
+	tag := []byte{246, 17, 244, 190, 4, 95, 98, 3, 231, 0, 115, 157, 242, 203, 100, 191}
+	key := []byte{4, 211, 31, 197, 84, 157, 252, 254, 11, 100, 157, 250, 63, 170, 106, 206, 107, 124, 212, 45, 111, 107, 9, 219, 200, 177, 0, 240, 143, 156, 44, 207}
+	iv := []byte{3, 22, 60, 12, 43, 67, 104, 105, 108, 108, 105, 99, 111, 116, 104, 101}
+
+	ce := contentEncryptions["A128CBC-HS256"]
+	gotCiphertext, gotTag, err := ce.encrypt(key, iv, plaintext, aad)
+	if err != nil {
+		t.Fatalf("encrypt() failed: %s", err.Error())
+	}
+	if !bytes.Equal(gotCiphertext, ciphertext) || !bytes.Equal(gotTag, tag) {
+		t.Errorf("encrypt() = %v, %v, want %v, %v", gotCiphertext, gotTag, ciphertext, tag)
+	}
+	got, err := ce.decrypt(key, iv, ciphertext, tag, aad)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("decrypt() = %v, %v, want %v", got, err, plaintext)
+	}
+}
+
+func TestContentEncryption(t *testing.T) {
+	for name, ce := range contentEncryptions {
+		t.Run(name, func(t *testing.T) {
+			key := make([]byte, ce.keySize)
+			iv := make([]byte, ce.ivSize)
+			rand.Read(key) // nolint:errcheck
+			rand.Read(iv)  // nolint:errcheck
+			for _, plaintext := range [][]byte{{}, []byte("test"), bytes.Repeat([]byte("a"), 16)} {
+				ciphertext, tag, err := ce.encrypt(key, iv, plaintext, []byte("aad"))
+				if err != nil {
+					t.Fatalf("encrypt() failed: %s", err.Error())
+				}
+				got, err := ce.decrypt(key, iv, ciphertext, tag, []byte("aad"))
+				if err != nil || !bytes.Equal(got, plaintext) {
+					t.Errorf("decrypt() = %v, %v, want %v", got, err, plaintext)
+				}
+				if _, err := ce.decrypt(key, iv, ciphertext, tag, []byte("other")); err == nil {
+					t.Error("decrypt() should fail for modified additional authenticated data")
+				}
+				if _, err := ce.decrypt(key, iv, ciphertext, tag[1:], []byte("aad")); err == nil {
+					t.Error("decrypt() should fail for truncated tag")
+				}
+				tampered := append([]byte{}, tag...)
+				tampered[0] ^= 0xFF
+				if _, err := ce.decrypt(key, iv, ciphertext, tampered, []byte("aad")); err == nil {
+					t.Error("decrypt() should fail for modified tag")
+				}
+				if len(ciphertext) > 0 {
+					tampered = append([]byte{}, ciphertext...)
+					tampered[0] ^= 0xFF
+					if _, err := ce.decrypt(key, iv, tampered, tag, []byte("aad")); err == nil {
+						t.Error("decrypt() should fail for modified ciphertext")
+					}
+				}
+			}
+			if _, _, err := ce.encrypt(key[:5], iv, []byte("test"), nil); err == nil {
+				t.Error("encrypt() should fail for invalid key")
+			}
+			if _, err := ce.decrypt(key[:5], iv, []byte("test"), nil, nil); err == nil {
+				t.Error("decrypt() should fail for invalid key")
+			}
+		})
+	}
+}
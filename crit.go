@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CriticalHeaderHandler is an interface for processors of extension header parameters that may be listed in crit.
+// It receives the value of the parameter from the protected header and the decoded header and returns an error if the token must be rejected.
+type CriticalHeaderHandler interface {
+	HandleCritical(json.RawMessage, Header) error
+}
+
+// reservedParameters contains header parameters defined by RFC 7515, 7516 and 7518 that are not fields of Header. They must not be listed in crit.
+var reservedParameters = map[string]bool{
+	"jwk":      true,
+	"x5u":      true,
+	"x5c":      true,
+	"x5t":      true,
+	"x5t#S256": true,
+	"iv":       true,
+	"tag":      true,
+}
+
+// AddCriticalHeaderHandler tries to add the handler for the extension header parameter but fails when one for the same name already exists.
+// Registering a handler declares the parameter as understood so tokens listing it in crit are accepted if the handler succeeds.
+// Encoded tokens using the parameter in Extensions list it in crit automatically.
+func AddCriticalHeaderHandler(name string, handler CriticalHeaderHandler) error {
+	if err := checkExtensionName(name); err != nil {
+		return err
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := criticalHandlers[name]; ok {
+		return errors.New("critical header handler already registered: use SetCriticalHeaderHandler to force replacement")
+	}
+	criticalHandlers[name] = handler
+	return nil
+}
+
+// SetCriticalHeaderHandler sets the handler for the extension header parameter ignoring previous settings for the same name.
+// It only fails when the name is a header parameter defined by the specifications.
+func SetCriticalHeaderHandler(name string, handler CriticalHeaderHandler) error {
+	if err := checkExtensionName(name); err != nil {
+		return err
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	criticalHandlers[name] = handler
+	return nil
+}
+
+// RemoveCriticalHeaderHandler removes the handler for the extension header parameter
+func RemoveCriticalHeaderHandler(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(criticalHandlers, name)
+}
+
+// checkExtensionName returns an error if the name is not allowed in crit as parameters defined by the specifications must not be listed
+func checkExtensionName(name string) error {
+	if headerParameters[name] || reservedParameters[name] {
+		return fmt.Errorf("header parameter %s is not an extension", name)
+	}
+	return nil
+}
+
+// SetUnencodedPayload sets the b64 header parameter to false and marks it as critical.
+// The content of a token with this header is signed and transmitted without base64url encoding as specified in RFC 7797.
+func (h *Header) SetUnencodedPayload() {
+	b64 := false
+	h.B64 = &b64
+	h.normalizeCritical()
+}
+
+// encodedPayload returns whether the content has to be base64url encoded. This is the case unless b64 is set to false.
+func (h Header) encodedPayload() bool {
+	return h.B64 == nil || *h.B64
+}
+
+// normalizeCritical adds b64 to crit if it is set as RFC 7797 section 6 requires it to be listed as critical.
+// Extension parameters with a registered handler are added as well.
+func (h *Header) normalizeCritical() {
+	if h.B64 != nil {
+		h.Crit = withCritical(h.Crit, "b64")
+	}
+	if len(h.Extensions) == 0 {
+		return
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, name := range sortedKeys(h.Extensions) {
+		if _, ok := criticalHandlers[name]; ok {
+			h.Crit = withCritical(h.Crit, name)
+		}
+	}
+}
+
+// checkCritical verifies that all parameters listed in crit are understood and present as required by RFC 7515 section 4.1.11.
+// The parameters of the protected header are used to check the presence of the critical ones and are passed to their handlers.
+func (h Header) checkCritical(protected map[string]json.RawMessage) error {
+	if h.Crit != nil && len(h.Crit) == 0 {
+		return errors.New("crit header parameter must not be empty")
+	}
+	// Handlers are called without holding the lock as they may use the registry themselves
+	handlers := make([]CriticalHeaderHandler, len(h.Crit))
+	registryMu.RLock()
+	for i, name := range h.Crit {
+		handlers[i] = criticalHandlers[name]
+	}
+	registryMu.RUnlock()
+	for i, name := range h.Crit {
+		if containsString(h.Crit[:i], name) {
+			return fmt.Errorf("critical header parameter %s is listed more than once", name)
+		}
+		if name != "b64" && handlers[i] == nil {
+			return fmt.Errorf("critical header parameter %s is not supported", name)
+		}
+		value, ok := protected[name]
+		if !ok {
+			return fmt.Errorf("critical header parameter %s is missing from protected header", name)
+		}
+		if handlers[i] == nil {
+			continue
+		}
+		if err := handlers[i].HandleCritical(value, h); err != nil {
+			return err
+		}
+	}
+	// RFC 7797 section 6 requires b64 to always be listed as critical
+	if h.B64 != nil && !containsString(h.Crit, "b64") {
+		return errors.New("b64 header parameter must be listed as critical")
+	}
+	return nil
+}
+
+// withCritical returns crit with the name added unless it is already included
+func withCritical(crit []string, name string) []string {
+	if containsString(crit, name) {
+		return crit
+	}
+	// Copy to avoid modifying the backing array of a slice shared with another header
+	return append(crit[:len(crit):len(crit)], name)
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testCriticalHandler accepts an extension parameter only if its value is 1
+type testCriticalHandler struct{}
+
+func (testCriticalHandler) HandleCritical(value json.RawMessage, h Header) error {
+	if string(value) != "1" {
+		return errors.New("value of critical header parameter is not 1")
+	}
+	return nil
+}
+
+func TestAddCriticalHeaderHandler(t *testing.T) {
+	defer RemoveCriticalHeaderHandler("add")
+	tests := []struct {
+		name    string
+		param   string
+		wantErr bool
+	}{
+		{"Normal", "add", false},
+		{"Already exists", "add", true},
+		{"HeaderField", "alg", true},
+		{"B64", "b64", true},
+		{"Reserved", "x5c", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := AddCriticalHeaderHandler(tt.param, testCriticalHandler{}); (err != nil) != tt.wantErr {
+				t.Errorf("AddCriticalHeaderHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetCriticalHeaderHandler(t *testing.T) {
+	if err := SetCriticalHeaderHandler("set", testCriticalHandler{}); err != nil {
+		t.Errorf("SetCriticalHeaderHandler() failed: %s", err.Error())
+	}
+	if err := SetCriticalHeaderHandler("set", testCriticalHandler{}); err != nil {
+		t.Errorf("SetCriticalHeaderHandler() failed to replace handler: %s", err.Error())
+	}
+	if err := SetCriticalHeaderHandler("kid", testCriticalHandler{}); err == nil {
+		t.Error("SetCriticalHeaderHandler() should fail for header parameters defined by the specifications")
+	}
+	RemoveCriticalHeaderHandler("set")
+	if _, ok := criticalHandlers["set"]; ok {
+		t.Error("RemoveCriticalHeaderHandler() did not remove the handler")
+	}
+}
+
+func TestHeader_SetUnencodedPayload(t *testing.T) {
+	h := Header{Typ: "JWT", Crit: []string{"b64"}}
+	h.SetUnencodedPayload()
+	if h.encodedPayload() || !reflect.DeepEqual(h.Crit, []string{"b64"}) {
+		t.Errorf("Header.SetUnencodedPayload() = %+v, want b64 false listed as critical once", h)
+	}
+}
+
+func Test_withCritical(t *testing.T) {
+	crit := make([]string, 1, 2)
+	crit[0] = "exp"
+	got := withCritical(crit, "b64")
+	other := withCritical(crit, "iat")
+	if !reflect.DeepEqual(got, []string{"exp", "b64"}) || !reflect.DeepEqual(other, []string{"exp", "iat"}) {
+		t.Errorf("withCritical() = %v and %v, want slices not sharing their backing array", got, other)
+	}
+}
+
+func TestHeader_checkCritical(t *testing.T) {
+	b64 := false
+	tests := []struct {
+		name      string
+		h         Header
+		protected string
+		wantErr   bool
+	}{
+		{"NoCritical", Header{}, `{}`, false},
+		{"B64", Header{B64: &b64, Crit: []string{"b64"}}, `{"b64":false,"crit":["b64"]}`, false},
+		{"Empty", Header{Crit: []string{}}, `{"crit":[]}`, true},
+		{"Unsupported", Header{Crit: []string{"unknown"}}, `{"crit":["unknown"],"unknown":1}`, true},
+		{"Missing", Header{Crit: []string{"b64"}}, `{"crit":["b64"]}`, true},
+		{"B64NotCritical", Header{B64: &b64}, `{"b64":false}`, true},
+		{"Duplicate", Header{B64: &b64, Crit: []string{"b64", "b64"}}, `{"b64":false,"crit":["b64","b64"]}`, true},
+		{"DefinedParameter", Header{Alg: "test", Crit: []string{"alg"}}, `{"alg":"test","crit":["alg"]}`, true},
+		{"Handled", Header{Crit: []string{"ext"}}, `{"crit":["ext"],"ext":1}`, false},
+		{"HandlerFails", Header{Crit: []string{"ext"}}, `{"crit":["ext"],"ext":2}`, true},
+		{"HandledMissing", Header{Crit: []string{"ext"}}, `{"crit":["ext"]}`, true},
+	}
+	SetCriticalHeaderHandler("ext", testCriticalHandler{}) // nolint:errcheck
+	defer RemoveCriticalHeaderHandler("ext")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var protected map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.protected), &protected); err != nil {
+				t.Fatalf("Invalid test data: %s", err.Error())
+			}
+			if err := tt.h.checkCritical(protected); (err != nil) != tt.wantErr {
+				t.Errorf("Header.checkCritical() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnencodedPayload(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	token := New([]byte(`{"name":"test","use":"testing"}`))
+	token.Header.SetUnencodedPayload()
+	tests := []struct {
+		name   string
+		encode func(JWT) ([]byte, error)
+		decode func([]byte) (JWT, error)
+	}{
+		{"Compact", JWT.Encode, Decode},
+		{"JSON", func(t JWT) ([]byte, error) { return t.EncodeJSON() }, func(in []byte) (JWT, error) { t, _, err := DecodeJSON(in); return t, err }},
+		{"Flattened", func(t JWT) ([]byte, error) { return t.EncodeFlattened(Signer{}) }, func(in []byte) (JWT, error) { t, _, err := DecodeJSON(in); return t, err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.encode(token)
+			if err != nil {
+				t.Fatalf("Encoding failed: %s", err.Error())
+			}
+			got, err := tt.decode(encoded)
+			if err != nil {
+				t.Fatalf("Decoding failed: %s", err.Error())
+			}
+			if !got.Valid() || string(got.Content) != string(token.Content) || got.Header.encodedPayload() {
+				t.Errorf("Decoding returned %+v (validation error: %v), want valid token with unencoded payload", got, got.ValidationError())
+			}
+			header, _ := encodeHeader(got.Header)
+			content, err := ContentFromSigningInput(join(header, got.Content))
+			if err != nil || string(content) != string(token.Content) {
+				t.Errorf("ContentFromSigningInput() = %s, %v, want %s", content, err, token.Content)
+			}
+		})
+	}
+
+	period := New([]byte(`{"value":1.5}`))
+	period.Header.SetUnencodedPayload()
+	if _, err := period.Encode(); err == nil {
+		t.Error("JWT.Encode() should fail for unencoded content containing periods")
+	}
+	if _, err := period.EncodeJSON(); err != nil {
+		t.Errorf("JWT.EncodeJSON() failed for unencoded content containing periods: %s", err.Error())
+	}
+}
+
+func TestHeader_normalizeCritical(t *testing.T) {
+	SetCriticalHeaderHandler("ext", testCriticalHandler{}) // nolint:errcheck
+	defer RemoveCriticalHeaderHandler("ext")
+	b64 := true
+	h := Header{B64: &b64, Extensions: map[string]interface{}{"ext": 1, "other": 2}}
+	h.normalizeCritical()
+	if !reflect.DeepEqual(h.Crit, []string{"b64", "ext"}) {
+		t.Errorf("Header.normalizeCritical() crit = %v, want [b64 ext]", h.Crit)
+	}
+}
+
+func TestCriticalExtension(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	SetEncryptionProvider("test", testKey[:32])
+	SetEncryptionAlgorithm("test")                         // nolint:errcheck
+	SetCriticalHeaderHandler("ext", testCriticalHandler{}) // nolint:errcheck
+	defer RemoveCriticalHeaderHandler("ext")
+	tests := []struct {
+		name   string
+		encode func(JWT) ([]byte, error)
+		decode func([]byte) (JWT, error)
+	}{
+		{"Compact", JWT.Encode, Decode},
+		{"Flattened", func(t JWT) ([]byte, error) { return t.EncodeFlattened(Signer{}) }, func(in []byte) (JWT, error) { t, _, err := DecodeJSON(in); return t, err }},
+		{"Encrypted", JWT.EncodeEncrypted, DecodeEncrypted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, value := range []int{1, 2} {
+				token := New([]byte(`{"name":"test"}`))
+				token.Header.Extensions = map[string]interface{}{"ext": value}
+				encoded, err := tt.encode(token)
+				if err != nil {
+					t.Fatalf("Encoding failed: %s", err.Error())
+				}
+				got, err := tt.decode(encoded)
+				if err != nil {
+					t.Fatalf("Decoding failed: %s", err.Error())
+				}
+				if !reflect.DeepEqual(got.Header.Crit, []string{"ext"}) || got.Header.Extensions["ext"] != float64(value) {
+					t.Errorf("Decoding returned header %+v, want ext listed as critical", got.Header)
+				}
+				if got.Valid() != (value == 1) {
+					t.Errorf("Decoding returned valid = %v (validation error: %v) for ext = %d", got.Valid(), got.ValidationError(), value)
+				}
+			}
+		})
+	}
+}
+
+func TestDecode_Critical(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	sign := func(header string) []byte {
+		h := b64encode([]byte(header))
+		input := join(h, []byte("content"))
+		return join(input, b64encode(append([]byte("test"), input...)))
+	}
+	tests := []struct {
+		name      string
+		token     []byte
+		wantValid bool
+	}{
+		{"Unencoded", sign(`{"typ":"JWT","alg":"test","b64":false,"crit":["b64"]}`), true},
+		{"NotCritical", sign(`{"typ":"JWT","alg":"test","b64":false}`), false},
+		{"Unsupported", sign(`{"typ":"JWT","alg":"test","b64":false,"crit":["b64","unknown"]}`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.token)
+			if err != nil {
+				t.Fatalf("Decode() failed: %s", err.Error())
+			}
+			if got.Valid() != tt.wantValid {
+				t.Errorf("Decode() valid = %v (validation error: %v), want %v", got.Valid(), got.ValidationError(), tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_Unencoded(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	encoded := `{"protected":"eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0","signature":"dGVzdA"}`
+	unencoded := `{"protected":"eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0IiwiYjY0IjpmYWxzZSwiY3JpdCI6WyJiNjQiXX0","signature":"dGVzdA"}`
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"MixedB64", `{"payload":"content","signatures":[` + encoded + `,` + unencoded + `]}`},
+		{"UnprotectedB64", `{"payload":"content","protected":"eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0","header":{"b64":false},"signature":"dGVzdA"}`},
+		{"UnprotectedCrit", `{"payload":"content","protected":"eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0","header":{"crit":["b64"]},"signature":"dGVzdA"}`},
+		{"InvalidPayload", `{"payload":"A","signatures":[` + encoded + `]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := DecodeJSON([]byte(tt.in)); err == nil {
+				t.Error("DecodeJSON() should fail")
+			}
+		})
+	}
+}
@@ -8,7 +8,12 @@ import (
 )
 
 // Decode decodes a JWT and check it's validity (use Validate() on JWT to see if it is valid)
-func Decode(in []byte) (data JWT, err error) {
+func Decode(in []byte) (JWT, error) {
+	return decode(in, false)
+}
+
+// decode decodes a JWT in compact serialization. Unsecured JWTs are only accepted if allowUnsecured is set.
+func decode(in []byte, allowUnsecured bool) (data JWT, err error) {
 	// Split the JWT into it's sections (header, content, hash)
 	sections := bytes.Split(in, []byte("."))
 	if len(sections) != 3 {
@@ -16,10 +21,24 @@ func Decode(in []byte) (data JWT, err error) {
 		return
 	}
 
-	// Decode Header
+	if data, err = decodeHeader(sections[0]); err != nil {
+		return
+	}
+
+	// Decode Content
+	if data.Content, err = decodePayload(data.Header, sections[1]); err != nil {
+		return
+	}
+
+	err = data.verify(sections[0], sections[1], sections[2], allowUnsecured && data.Header.Alg == AlgorithmNone)
+	return
+}
+
+// decodeHeader returns a token containing the decoded header
+func decodeHeader(header []byte) (data JWT, err error) {
 	// Base64
-	headerJSON := make([]byte, base64.RawURLEncoding.DecodedLen(len(sections[0])))
-	if _, err = base64.RawURLEncoding.Decode(headerJSON, sections[0]); err != nil {
+	headerJSON := make([]byte, base64.RawURLEncoding.DecodedLen(len(header)))
+	if _, err = base64.RawURLEncoding.Decode(headerJSON, header); err != nil {
 		return
 	}
 
@@ -29,23 +48,72 @@ func Decode(in []byte) (data JWT, err error) {
 	}
 	if data.Header.Typ != "JWT" {
 		err = errors.New("header suggests token is not a JWT")
-		return
+	}
+	return
+}
+
+// verify decodes the signature and checks it against the encoded header and payload. Only malformed signatures are returned as an error, the result of the verification is stored in the token.
+// Unsecured tokens are verified using UnsecuredProvider instead of the signature provider registered for the algorithm.
+func (data *JWT) verify(header, payload, hash []byte, unsecured bool) error {
+	signature := make([]byte, base64.RawURLEncoding.DecodedLen(len(hash)))
+	// The signature of an unsecured JWT is empty
+	if n, e := base64.RawURLEncoding.Decode(signature, hash); e != nil || (n < 1 && !unsecured) {
+		return errors.New("hash invalid")
 	}
 
-	// Decode Content
-	data.Content = make([]byte, base64.RawURLEncoding.DecodedLen(len(sections[1])))
-	if _, err = base64.RawURLEncoding.Decode(data.Content, sections[1]); err != nil {
-		return
+	protected, _ := protectedParameters(header) // The header has already been decoded successfully
+	if data.validationError = data.Header.checkCritical(protected); data.validationError != nil {
+		return nil
 	}
 
-	// Decode Hash
-	signature := make([]byte, base64.RawURLEncoding.DecodedLen(len(sections[2])))
-	if n, e := base64.RawURLEncoding.Decode(signature, sections[2]); e != nil || n < 1 {
-		err = errors.New("hash invalid")
-		return
+	// The capacity of the header is limited so join does not overwrite the data following it
+	input := join(header[:len(header):len(header)], payload)
+	if unsecured {
+		data.validationError = data.validateWith(UnsecuredProvider{}, input, signature)
+		return nil
 	}
+	data.validationError = data.validate(input, signature)
+	return nil
+}
 
-	data.validationError = data.validate(join(sections[0], sections[1]), signature)
+// decodePayload returns the content of a token from its payload which is base64url encoded unless the header indicates otherwise
+func decodePayload(h Header, payload []byte) ([]byte, error) {
+	if !h.encodedPayload() {
+		return append([]byte(nil), payload...), nil
+	}
+	content := make([]byte, base64.RawURLEncoding.DecodedLen(len(payload)))
+	if _, err := base64.RawURLEncoding.Decode(content, payload); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
 
-	return
+// ContentFromSigningInput extracts the decoded content from the data passed to the Verify function of a SignatureProvider.
+// It can be used by signature providers to supply the content of the token to a KeyResolver.
+func ContentFromSigningInput(data []byte) ([]byte, error) {
+	i := bytes.IndexByte(data, '.')
+	if i < 0 {
+		return nil, errors.New("invalid number of sections")
+	}
+	var h Header
+	if i > 0 {
+		headerJSON, err := b64decode(data[:i])
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(headerJSON, &h); err != nil {
+			return nil, err
+		}
+	}
+	// An unencoded payload may contain periods when using the JSON serialization
+	if h.encodedPayload() && bytes.IndexByte(data[i+1:], '.') >= 0 {
+		return nil, errors.New("invalid number of sections")
+	}
+	return decodePayload(h, data[i+1:])
+}
+
+func b64decode(data []byte) ([]byte, error) {
+	out := make([]byte, base64.RawURLEncoding.DecodedLen(len(data)))
+	n, err := base64.RawURLEncoding.Decode(out, data)
+	return out[:n], err
 }
@@ -6,7 +6,9 @@ import (
 )
 
 func TestDecode(t *testing.T) {
-	SetSignatureProvider("test", TestAlgorithm("test"))
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 	tests := []struct {
 		name     string
@@ -38,3 +40,32 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestContentFromSigningInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"Normal", []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0.eyJuYW1lIjoidGVzdCJ9"), []byte(`{"name":"test"}`), false},
+		{"OneSection", []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0"), nil, true},
+		{"ThreeSections", []byte("A.B.C"), nil, true},
+		{"ContentInvalidBase64", []byte("A.A"), nil, true},
+		{"HeaderInvalidJSON", []byte("YQ.eyJuYW1lIjoidGVzdCJ9"), nil, true},
+		{"EmptyHeader", []byte(".eyJuYW1lIjoidGVzdCJ9"), []byte(`{"name":"test"}`), false},
+		{"Unencoded", []byte(`eyJiNjQiOmZhbHNlfQ.{"value":1.5}`), []byte(`{"value":1.5}`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ContentFromSigningInput(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ContentFromSigningInput() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ContentFromSigningInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
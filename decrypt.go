@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Layers of a nested JWT reported by LayerError
+const (
+	LayerEncryption = "encryption"
+	LayerSignature  = "signature"
+)
+
+// LayerError is the validation error of a nested JWT and indicates which layer of the token failed
+type LayerError struct {
+	Layer string
+	Err   error
+}
+
+func (e LayerError) Error() string {
+	return e.Layer + " layer: " + e.Err.Error()
+}
+
+// Unwrap returns the error that occurred while validating the layer
+func (e LayerError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeEncrypted decodes a JWE in compact serialization and decrypts it (use Valid() on JWT to see if decryption and content validation succeeded).
+// In case the header indicates a nested JWT, the decrypted token is decoded and verified as well and returned instead of the encrypted one.
+// The validation error of a nested JWT is a LayerError.
+func DecodeEncrypted(in []byte) (data JWT, err error) {
+	// Split the JWE into it's sections (header, encrypted key, initialization vector, ciphertext, authentication tag)
+	sections := bytes.Split(in, []byte("."))
+	if len(sections) != 5 {
+		err = errors.New("invalid number of sections")
+		return
+	}
+
+	decoded := make([][]byte, len(sections))
+	for i, s := range sections {
+		if decoded[i], err = b64decode(s); err != nil {
+			return
+		}
+	}
+
+	if err = json.Unmarshal(decoded[0], &data.Header); err != nil {
+		return
+	}
+	if data.Header.Typ != "JWT" {
+		err = errors.New("header suggests token is not a JWT")
+		return
+	}
+
+	protected, _ := protectedParameters(sections[0]) // The header has already been decoded successfully
+	if data.validationError = data.Header.checkCritical(protected); data.validationError == nil {
+		data.Content, data.validationError = data.decrypt(sections[0], decoded[1], decoded[2], decoded[3], decoded[4])
+	}
+	if !data.Header.isNested() {
+		if data.validationError == nil {
+			data.validationError = validateContent(data.Content)
+		}
+		return
+	}
+
+	if data.validationError != nil {
+		data.validationError = LayerError{LayerEncryption, data.validationError}
+		return
+	}
+	inner, e := Decode(data.Content)
+	if e != nil {
+		data.validationError = LayerError{LayerSignature, e}
+		return
+	}
+	if inner.validationError != nil {
+		inner.validationError = LayerError{LayerSignature, inner.validationError}
+	}
+	return inner, nil
+}
+
+// isNested returns whether the content of the token is a JWT itself. Values of cty are compared case-insensitively as required by RFC 7515 section 4.1.10.
+func (h Header) isNested() bool {
+	return strings.EqualFold(h.Cty, "JWT")
+}
+
+func (jwt JWT) decrypt(header, encryptedKey, iv, ciphertext, tag []byte) ([]byte, error) {
+	alg, err := jwt.Header.getEncryptionAlgorithm()
+	if err != nil {
+		return nil, err
+	}
+	ce, ok := contentEncryptions[jwt.Header.Enc]
+	if !ok {
+		return nil, fmt.Errorf("content encryption algorithm %s is not supported", jwt.Header.Enc)
+	}
+	cek, err := alg.UnwrapKey(encryptedKey, ce.keySize, jwt.Header)
+	if err != nil {
+		return nil, err
+	}
+	if len(cek) != ce.keySize || len(iv) != ce.ivSize {
+		return nil, errDecryption
+	}
+	plaintext, err := ce.decrypt(cek, iv, ciphertext, tag, header)
+	if err != nil {
+		return nil, err
+	}
+	switch jwt.Header.Zip {
+	case "":
+		return plaintext, nil
+	case "DEF":
+		registryMu.RLock()
+		limit := maxDecompressedSize
+		registryMu.RUnlock()
+		return decompress(plaintext, limit)
+	}
+	return nil, fmt.Errorf("compression algorithm %s is not supported", jwt.Header.Zip)
+}
+
+func (h Header) getEncryptionAlgorithm() (EncryptionProvider, error) {
+	registryMu.RLock()
+	a, ok := encryptionProviders[h.Alg]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("algorithm %s is not supported", h.Alg)
+	}
+	return a, nil
+}
@@ -0,0 +1,149 @@
+package jwt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecodeEncrypted(t *testing.T) {
+	SetEncryptionProvider("test", testKey[:32])
+	SetEncryptionProvider("other", TestEncryption("0123456789abcdef"))
+	valid, err := JWT{Header{Typ: "JWT"}, []byte(`{"name":"test","use":"testing"}`), nil}.encrypt(testKey[:32], "A256GCM")
+	if err != nil {
+		t.Fatalf("Could not encrypt JWT: %s", err.Error())
+	}
+	sections := bytes.Split(valid, []byte("."))
+	replace := func(i int, s string) []byte {
+		out := make([][]byte, len(sections))
+		copy(out, sections)
+		out[i] = []byte(s)
+		return bytes.Join(out, []byte("."))
+	}
+	header := func(h Header) string {
+		encoded, _ := encodeHeader(h)
+		return string(encoded)
+	}
+	// The header of a token is authenticated so a token using an unsupported compression algorithm has to be encrypted manually
+	zipHeader, _ := encodeHeader(Header{Typ: "JWT", Alg: "test", Enc: "A256GCM", Zip: "unknown", Apu: "dGVzdA"})
+	iv := make([]byte, 12)
+	ciphertext, tag, _ := contentEncryptions["A256GCM"].encrypt([]byte(testKey[:32]), iv, []byte(`{}`), zipHeader)
+	unknownCompression := join(zipHeader, b64encode([]byte("encrypted key")), b64encode(iv), b64encode(ciphertext), b64encode(tag))
+	tests := []struct {
+		name      string
+		token     []byte
+		wantValid bool
+		wantErr   bool
+	}{
+		{"Normal", valid, true, false},
+		{"FourSections", []byte("A.B.C.D"), false, true},
+		{"SixSections", []byte("A.B.C.D.E.F"), false, true},
+		{"InvalidBase64", replace(2, "A"), false, true},
+		{"HeaderInvalidJSON", replace(0, "YQ"), false, true},
+		{"TokenNotJWT", replace(0, "eyJ0eXAiOiJub25lIiwiYWxnIjoibm9uZSJ9"), false, true},
+		{"UnknownAlgorithm", replace(0, header(Header{Typ: "JWT", Alg: "unknown", Enc: "A256GCM", Apu: "dGVzdA"})), false, false},
+		{"UnknownEncryption", replace(0, header(Header{Typ: "JWT", Alg: "test", Enc: "unknown", Apu: "dGVzdA"})), false, false},
+		{"ModifiedHeader", replace(0, header(Header{Typ: "JWT", Alg: "test", Enc: "A256GCM", Apu: "dGVzdA", Kid: "test"})), false, false},
+		{"UnknownCompression", unknownCompression, false, false},
+		{"WrongKeySize", replace(0, header(Header{Typ: "JWT", Alg: "other", Enc: "A256GCM", Apu: "dGVzdA"})), false, false},
+		{"InvalidEncryptedKey", replace(1, "dGVzdA"), false, false},
+		{"InvalidIV", replace(2, "dGVzdA"), false, false},
+		{"ModifiedCiphertext", replace(3, "dGVzdA"), false, false},
+		{"ModifiedTag", replace(4, "dGVzdA"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeEncrypted(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeEncrypted() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Valid() != tt.wantValid {
+				t.Errorf("DecodeEncrypted() valid = %v (validation error: %v), want %v", got.Valid(), got.ValidationError(), tt.wantValid)
+			}
+		})
+	}
+	RemoveEncryptionProvider("other")
+}
+
+func TestDecodeEncrypted_ContentValidation(t *testing.T) {
+	SetEncryptionProvider("test", testKey[:32])
+	AddValidationProvider("encryption", testValidationProvider(0x0)) // nolint:errcheck
+	defer RemoveValidationProvider("encryption")
+	token, err := JWT{Header{Typ: "JWT"}, []byte{0xFF}, nil}.encrypt(testKey[:32], "A256GCM")
+	if err != nil {
+		t.Fatalf("Could not encrypt JWT: %s", err.Error())
+	}
+	got, err := DecodeEncrypted(token)
+	if err != nil {
+		t.Fatalf("DecodeEncrypted() failed: %s", err.Error())
+	}
+	if got.Valid() {
+		t.Error("DecodeEncrypted() should return invalid token when content validation fails")
+	}
+}
+
+func TestDecodeEncrypted_Nested(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	SetEncryptionProvider("test", testKey[:32])
+	content := []byte(`{"name":"test","use":"testing"}`)
+	signed, err := New(content).Encode()
+	if err != nil {
+		t.Fatalf("Could not sign JWT: %s", err.Error())
+	}
+	nested := func(inner []byte, cty string) []byte {
+		token, err := JWT{Header{Typ: "JWT", Cty: cty}, inner, nil}.encrypt(testKey[:32], "A256GCM")
+		if err != nil {
+			t.Fatalf("Could not encrypt JWT: %s", err.Error())
+		}
+		return token
+	}
+	invalidSignature := append(append([]byte{}, signed[:bytes.LastIndexByte(signed, '.')]...), ".dGVzdA"...)
+	valid := nested(signed, "JWT")
+	undecryptable := append(append([]byte{}, valid[:bytes.LastIndexByte(valid, '.')]...), ".dGVzdA"...)
+	tests := []struct {
+		name      string
+		token     []byte
+		wantLayer string
+	}{
+		{"Normal", valid, ""},
+		{"LowerCaseContentType", nested(signed, "jwt"), ""},
+		{"InvalidSignature", nested(invalidSignature, "JWT"), LayerSignature},
+		{"InvalidInnerToken", nested([]byte("not a token"), "JWT"), LayerSignature},
+		{"DecryptionFailed", undecryptable, LayerEncryption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeEncrypted(tt.token)
+			if err != nil {
+				t.Fatalf("DecodeEncrypted() failed: %s", err.Error())
+			}
+			if tt.wantLayer == "" {
+				if !got.Valid() {
+					t.Fatalf("DecodeEncrypted() returned invalid token: %s", got.ValidationError().Error())
+				}
+				if string(got.Content) != string(content) || got.Header.Cty != "" {
+					t.Errorf("DecodeEncrypted() = %+v, want inner token with content %s", got, content)
+				}
+				return
+			}
+			layerErr, ok := got.ValidationError().(LayerError)
+			if !ok {
+				t.Fatalf("DecodeEncrypted() validation error = %v, want LayerError", got.ValidationError())
+			}
+			if layerErr.Layer != tt.wantLayer || layerErr.Unwrap() == nil {
+				t.Errorf("DecodeEncrypted() failed at layer %q, want %q", layerErr.Layer, tt.wantLayer)
+			}
+		})
+	}
+}
+
+func TestLayerError_Error(t *testing.T) {
+	err := LayerError{LayerSignature, errors.New("signature invalid")}
+	if err.Error() != "signature layer: signature invalid" {
+		t.Errorf("LayerError.Error() = %q, want %q", err.Error(), "signature layer: signature invalid")
+	}
+}
@@ -0,0 +1,39 @@
+package jwt
+
+import (
+	"bytes"
+	"errors"
+)
+
+// EncodeDetached signs a JWT using the default algorithm and encodes it without its payload as described in RFC 7515 appendix F.
+// The resulting token has the form header..signature and the content has to be supplied to DecodeDetached separately.
+func (t JWT) EncodeDetached() ([]byte, error) {
+	// The payload is never transmitted as part of the token so unencoded content may contain periods
+	header, _, hash, err := t.sign(false)
+	if err != nil {
+		return nil, err
+	}
+	return join(header, nil, hash), nil
+}
+
+// DecodeDetached decodes a JWT with detached content and checks it's validity using the content supplied (use Validate() on JWT to see if it is valid)
+func DecodeDetached(in, content []byte) (data JWT, err error) {
+	sections := bytes.Split(in, []byte("."))
+	if len(sections) != 3 {
+		err = errors.New("invalid number of sections")
+		return
+	}
+	if len(sections[1]) != 0 {
+		err = errors.New("token does not have detached content")
+		return
+	}
+
+	if data, err = decodeHeader(sections[0]); err != nil {
+		return
+	}
+	data.Content = append([]byte(nil), content...)
+
+	payload, _ := data.payload(false) // Only fails for the compact serialization
+	err = data.verify(sections[0], payload, sections[2], false)
+	return
+}
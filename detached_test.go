@@ -0,0 +1,130 @@
+package jwt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestJWT_EncodeDetached(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	unencoded := New([]byte(`{"value":1.5}`))
+	unencoded.Header.SetUnencodedPayload()
+	tests := []struct {
+		name  string
+		token JWT
+	}{
+		{"Normal", New([]byte(`{"name":"test","use":"testing"}`))},
+		{"Unencoded", unencoded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.token.EncodeDetached()
+			if err != nil {
+				t.Fatalf("JWT.EncodeDetached() error = %v", err)
+			}
+			sections := bytes.Split(got, []byte("."))
+			if len(sections) != 3 || len(sections[1]) != 0 {
+				t.Fatalf("JWT.EncodeDetached() = %s, want header..signature", got)
+			}
+			data, err := DecodeDetached(got, tt.token.Content)
+			if err != nil || !data.Valid() {
+				t.Errorf("DecodeDetached() error = %v, validation error = %v", err, data.ValidationError())
+			}
+			if !reflect.DeepEqual(data.Content, tt.token.Content) {
+				t.Errorf("DecodeDetached() content = %s, want %s", data.Content, tt.token.Content)
+			}
+		})
+	}
+
+	if err := SetSignatureProvider("error", TestAlgorithm("error")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("error")      // nolint:errcheck
+	defer SetSigningAlgorithm("test") // nolint:errcheck
+	if _, err := New([]byte("content")).EncodeDetached(); err == nil {
+		t.Error("JWT.EncodeDetached() should fail when signing fails")
+	}
+}
+
+func TestJWT_Encode_UnencodedPayload(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	token := New([]byte(`{"test":1}`))
+	token.Header.SetUnencodedPayload()
+	res, err := token.Encode()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	sections := bytes.Split(res, []byte("."))
+	if len(sections) != 3 || string(sections[1]) != `{"test":1}` {
+		t.Fatalf("Encoded JWT %s does not contain the unencoded payload", res)
+	}
+	// The signing input has to contain the payload as is as specified in RFC 7797 section 3
+	sig, err := b64decode(sections[2])
+	if err != nil || string(sig) != "test"+string(sections[0])+`.{"test":1}` {
+		t.Errorf("Signature %s was not created over the unencoded payload", sig)
+	}
+	dec, err := Decode(res)
+	if err != nil {
+		t.Fatalf("Could not decode JWT: %s", err.Error())
+	}
+	if !dec.Valid() || string(dec.Content) != `{"test":1}` {
+		t.Errorf("Decoded JWT could not be validated: %v", dec.ValidationError())
+	}
+}
+
+func TestDecodeDetached(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test")                                    // nolint:errcheck
+	AddValidationProvider("detached", testValidationProvider(0x0)) // nolint:errcheck
+	defer RemoveValidationProvider("detached")
+	content := []byte(`{"name":"test","use":"testing"}`)
+	token, err := New(content).EncodeDetached()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	invalidContent, err := New([]byte{0xFF}).EncodeDetached()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	tests := []struct {
+		name      string
+		token     []byte
+		content   []byte
+		wantValid bool
+		wantErr   bool
+	}{
+		{"Normal", token, content, true, false},
+		{"OtherContent", token, []byte(`{"name":"other"}`), false, false},
+		{"ContentValidation", invalidContent, []byte{0xFF}, false, false},
+		{"NotDetached", []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0.eyJuYW1lIjoidGVzdCJ9.dGVzdA"), content, false, true},
+		{"TwoSections", []byte("A.B"), content, false, true},
+		{"HeaderInvalidBase64", []byte("A.._"), content, false, true},
+		{"TokenNotJWT", []byte("eyJ0eXAiOiJub25lIiwiYWxnIjoibm9uZSJ9.._"), content, false, true},
+		{"HashEmpty", []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0.."), content, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := append([]byte(nil), tt.token...)
+			got, err := DecodeDetached(in, tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeDetached() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Valid() != tt.wantValid {
+				t.Errorf("DecodeDetached() valid = %v, want %v (%v)", got.Valid(), tt.wantValid, got.ValidationError())
+			}
+			if !bytes.Equal(in, tt.token) {
+				t.Errorf("DecodeDetached() modified the token to %s", in)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -14,22 +15,50 @@ func New(content []byte) JWT {
 
 // Encode a JWT to a byte slice
 func (t JWT) Encode() ([]byte, error) {
-	if defaultAlgorithm == "" {
-		return nil, errors.New("default algorithm is not set - cannot sign JWT")
+	header, content, hash, err := t.sign(true)
+	if err != nil {
+		return nil, err
+	}
+	return join(header, content, hash), nil
+}
+
+// sign signs the token using the default algorithm and returns the encoded header, payload and signature
+func (t JWT) sign(compact bool) (header, content, hash []byte, err error) {
+	registryMu.RLock()
+	name := defaultAlgorithm
+	alg := signatureProviders[name]
+	registryMu.RUnlock()
+	if name == "" {
+		return nil, nil, nil, errors.New("default algorithm is not set - cannot sign JWT")
 	}
-	alg := signatureProviders[defaultAlgorithm]
 	if alg == nil {
-		return nil, errors.New("cannot access default algorithm")
+		return nil, nil, nil, errors.New("cannot access default algorithm")
 	}
 	alg.Header(&t.Header)
-	header := encodeHeader(t.Header)
-	content := b64encode(t.Content)
+	t.Header.normalizeCritical()
+	if header, err = encodeHeader(t.Header); err != nil {
+		return nil, nil, nil, err
+	}
+	if content, err = t.payload(compact); err != nil {
+		return nil, nil, nil, err
+	}
 	sig, err := alg.Sign(join(header, content))
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	hash := b64encode(sig)
-	return join(header, content, hash), nil
+	return header, content, b64encode(sig), nil
+}
+
+// payload returns the content as it is included in the token. It is base64url encoded unless the header requests an unencoded payload as specified in RFC 7797.
+// Unencoded content must not contain periods when using the compact serialization.
+func (t JWT) payload(compact bool) ([]byte, error) {
+	if t.Header.encodedPayload() {
+		return b64encode(t.Content), nil
+	}
+	if compact && bytes.IndexByte(t.Content, '.') >= 0 {
+		return nil, errors.New("unencoded content must not contain periods when using compact serialization")
+	}
+	return t.Content, nil
 }
 
 func b64encode(data []byte) []byte {
@@ -38,9 +67,12 @@ func b64encode(data []byte) []byte {
 	return out
 }
 
-func encodeHeader(h Header) []byte {
-	json, _ := json.Marshal(h)
-	return b64encode(json)
+func encodeHeader(h Header) ([]byte, error) {
+	json, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return b64encode(json), nil
 }
 
 func join(b ...[]byte) []byte {
@@ -70,18 +70,27 @@ func TestNew(t *testing.T) {
 
 func Test_encodeHeader(t *testing.T) {
 	tests := []struct {
-		name string
-		h    Header
-		want []byte
+		name    string
+		h       Header
+		want    []byte
+		wantErr bool
 	}{
-		{"Normal", Header{Typ: "JWT", Alg: "EdDSA"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSJ9")},
-		{"WithKeyID", Header{Typ: "JWT", Alg: "EdDSA", Kid: "unique_key_id"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImtpZCI6InVuaXF1ZV9rZXlfaWQifQ")},
-		{"WithKeyURL", Header{Typ: "JWT", Alg: "EdDSA", Jku: "https://example.com/get_key"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImprdSI6Imh0dHBzOi8vZXhhbXBsZS5jb20vZ2V0X2tleSJ9")},
-		{"WithKeyIDAndURL", Header{Typ: "JWT", Alg: "EdDSA", Kid: "unique_key_id", Jku: "https://example.com/get_key"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImtpZCI6InVuaXF1ZV9rZXlfaWQiLCJqa3UiOiJodHRwczovL2V4YW1wbGUuY29tL2dldF9rZXkifQ")},
+		{"Normal", Header{Typ: "JWT", Alg: "EdDSA"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSJ9"), false},
+		{"WithKeyID", Header{Typ: "JWT", Alg: "EdDSA", Kid: "unique_key_id"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImtpZCI6InVuaXF1ZV9rZXlfaWQifQ"), false},
+		{"WithKeyURL", Header{Typ: "JWT", Alg: "EdDSA", Jku: "https://example.com/get_key"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImprdSI6Imh0dHBzOi8vZXhhbXBsZS5jb20vZ2V0X2tleSJ9"), false},
+		{"WithKeyIDAndURL", Header{Typ: "JWT", Alg: "EdDSA", Kid: "unique_key_id", Jku: "https://example.com/get_key"}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImtpZCI6InVuaXF1ZV9rZXlfaWQiLCJqa3UiOiJodHRwczovL2V4YW1wbGUuY29tL2dldF9rZXkifQ"), false},
+		{"WithExtension", Header{Typ: "JWT", Alg: "EdDSA", Extensions: map[string]interface{}{"ver": 2, "app": "test"}}, []byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJFZERTQSIsImFwcCI6InRlc3QiLCJ2ZXIiOjJ9"), false},
+		{"ExtensionNotEncodable", Header{Typ: "JWT", Alg: "EdDSA", Extensions: map[string]interface{}{"ver": make(chan int)}}, nil, true},
+		{"ExtensionDefinedParameter", Header{Typ: "JWT", Alg: "EdDSA", Extensions: map[string]interface{}{"kid": "other"}}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := encodeHeader(tt.h); !reflect.DeepEqual(got, tt.want) {
+			got, err := encodeHeader(tt.h)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("encodeHeader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("encodeHeader() = %v, want %v", got, tt.want)
 			}
 		})
@@ -101,7 +110,9 @@ func TestJWT_Encode(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			SetSignatureProvider("test", tt.alg)
+			if err := SetSignatureProvider("test", tt.alg); err != nil {
+				t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+			}
 			SetSigningAlgorithm("test") // nolint:errcheck
 			gotResult, err := tt.t.Encode()
 			if (err != nil) != tt.wantErr {
@@ -0,0 +1,98 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// EncodeEncrypted encrypts a JWT using the default encryption algorithms and encodes it using the JWE compact serialization defined in RFC 7516
+func (t JWT) EncodeEncrypted() ([]byte, error) {
+	alg, enc, err := getDefaultEncryption()
+	if err != nil {
+		return nil, err
+	}
+	if compressionEnabled() {
+		t.Header.Zip = "DEF"
+	}
+	return t.encrypt(alg, enc)
+}
+
+// EncodeNested signs a JWT using the default signing algorithm and encrypts the result using the default encryption algorithms.
+// The resulting nested JWT as specified in RFC 7519 section 5.2 indicates the signed token as its content using the header parameter cty.
+func (t JWT) EncodeNested() ([]byte, error) {
+	alg, enc, err := getDefaultEncryption()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := t.Encode()
+	if err != nil {
+		return nil, err
+	}
+	outer := JWT{Header{Typ: "JWT", Cty: "JWT"}, signed, nil}
+	if compressionEnabled() {
+		outer.Header.Zip = "DEF"
+	}
+	return outer.encrypt(alg, enc)
+}
+
+func getDefaultEncryption() (EncryptionProvider, string, error) {
+	registryMu.RLock()
+	name := defaultEncryption
+	alg := encryptionProviders[name]
+	enc := defaultContentEncryption
+	registryMu.RUnlock()
+	if name == "" {
+		return nil, "", errors.New("default encryption algorithm is not set - cannot encrypt JWT")
+	}
+	if alg == nil {
+		return nil, "", errors.New("cannot access default encryption algorithm")
+	}
+	return alg, enc, nil
+}
+
+func compressionEnabled() bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return compressContent
+}
+
+func (t JWT) encrypt(alg EncryptionProvider, enc string) ([]byte, error) {
+	ce, ok := contentEncryptions[enc]
+	if !ok {
+		return nil, errors.New("content encryption algorithm is not supported")
+	}
+	plaintext := t.Content
+	switch t.Header.Zip {
+	case "":
+	case "DEF":
+		plaintext = compress(t.Content)
+	default:
+		return nil, errors.New("compression algorithm is not supported")
+	}
+	alg.Header(&t.Header)
+	t.Header.Enc = enc
+	t.Header.normalizeCritical()
+	// The key management algorithm may add parameters to the header that are required for decryption
+	cek, encryptedKey, err := alg.WrapKey(ce.keySize, &t.Header)
+	if err != nil {
+		return nil, err
+	}
+	if len(cek) != ce.keySize {
+		return nil, errors.New("content encryption key has invalid size")
+	}
+	iv := make([]byte, ce.ivSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	// The encoded header is used as additional authenticated data
+	header, err := encodeHeader(t.Header)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, tag, err := ce.encrypt(cek, iv, plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+	return join(header, b64encode(encryptedKey), b64encode(iv), b64encode(ciphertext), b64encode(tag)), nil
+}
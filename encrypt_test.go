@@ -0,0 +1,183 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Defining a key management algorithm for testing
+
+type TestEncryption string
+
+func (e TestEncryption) WrapKey(size int, h *Header) ([]byte, []byte, error) {
+	if string(e) == "error" {
+		return nil, nil, errors.New("Here's the error you requested")
+	}
+	h.Apu = "dGVzdA"
+	return []byte(e), []byte("encrypted key"), nil
+}
+
+func (e TestEncryption) UnwrapKey(encryptedKey []byte, size int, h Header) ([]byte, error) {
+	if string(encryptedKey) != "encrypted key" || h.Apu != "dGVzdA" {
+		return nil, errors.New("decryption failed")
+	}
+	return []byte(e), nil
+}
+
+func (e TestEncryption) Header(h *Header) {
+	h.Alg = "test"
+}
+
+const testKey = TestEncryption("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+
+func TestJWT_EncodeEncrypted(t *testing.T) {
+	SetEncryptionProvider("test", testKey[:32])
+	SetEncryptionAlgorithm("test") // nolint:errcheck
+	content := []byte(`{"name":"test","use":"testing"}`)
+	for enc := range contentEncryptions {
+		t.Run(enc, func(t *testing.T) {
+			if err := SetContentEncryptionAlgorithm(enc); err != nil {
+				t.Fatalf("SetContentEncryptionAlgorithm() failed: %s", err.Error())
+			}
+			SetEncryptionProvider("test", testKey[:contentEncryptions[enc].keySize])
+			token, err := New(content).EncodeEncrypted()
+			if err != nil {
+				t.Fatalf("JWT.EncodeEncrypted() failed: %s", err.Error())
+			}
+			dec, err := DecodeEncrypted(token)
+			if err != nil {
+				t.Fatalf("DecodeEncrypted() failed: %s", err.Error())
+			}
+			if !dec.Valid() || string(dec.Content) != string(content) {
+				t.Errorf("DecodeEncrypted() = %s (validation error: %v), want %s", dec.Content, dec.ValidationError(), content)
+			}
+			if dec.Header.Enc != enc || dec.Header.Alg != "test" {
+				t.Errorf("DecodeEncrypted() returned unexpected header %+v", dec.Header)
+			}
+		})
+	}
+	SetContentEncryptionAlgorithm("A256GCM") // nolint:errcheck
+	SetEncryptionProvider("test", testKey[:32])
+}
+
+func TestJWT_EncodeEncrypted_Edgecases(t *testing.T) {
+	token := New([]byte(`{"name":"test","use":"testing"}`))
+	defaultEncryption = ""
+	if _, err := token.EncodeEncrypted(); err == nil {
+		t.Error("JWT.EncodeEncrypted() should fail when default algorithm is not set")
+	}
+	defaultEncryption = "sample"
+	if _, err := token.EncodeEncrypted(); err == nil {
+		t.Error("JWT.EncodeEncrypted() should fail when default algorithm does not exist")
+	}
+	if _, err := token.encrypt(TestEncryption("error"), "A256GCM"); err == nil {
+		t.Error("JWT.encrypt() should fail when the key cannot be wrapped")
+	}
+	if _, err := token.encrypt(testKey[:16], "A256GCM"); err == nil {
+		t.Error("JWT.encrypt() should fail when the content encryption key has the wrong size")
+	}
+	if _, err := token.encrypt(testKey[:32], "unknown"); err == nil {
+		t.Error("JWT.encrypt() should fail for unsupported content encryption algorithm")
+	}
+	if SetContentEncryptionAlgorithm("unknown") == nil {
+		t.Error("SetContentEncryptionAlgorithm() should fail for unsupported content encryption algorithm")
+	}
+}
+
+func TestJWT_EncodeNested(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	SetEncryptionProvider("test", testKey[:32])
+	SetEncryptionAlgorithm("test") // nolint:errcheck
+	token, err := New([]byte(`{"name":"test","use":"testing"}`)).EncodeNested()
+	if err != nil {
+		t.Fatalf("JWT.EncodeNested() failed: %s", err.Error())
+	}
+	outer := JWT{}
+	if err := json.Unmarshal(mustB64Decode(bytes.Split(token, []byte("."))[0]), &outer.Header); err != nil {
+		t.Fatalf("JWT.EncodeNested() returned invalid header: %s", err.Error())
+	}
+	if outer.Header.Cty != "JWT" || outer.Header.Typ != "JWT" {
+		t.Errorf("JWT.EncodeNested() returned header %+v, want typ and cty JWT", outer.Header)
+	}
+
+	if err := SetSignatureProvider("error", TestAlgorithm("error")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("error") // nolint:errcheck
+	if _, err := New([]byte(`{}`)).EncodeNested(); err == nil {
+		t.Error("JWT.EncodeNested() should fail when signing fails")
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	RemoveSignatureProvider("error")
+
+	defaultEncryption = ""
+	if _, err := New([]byte(`{}`)).EncodeNested(); err == nil {
+		t.Error("JWT.EncodeNested() should fail when default encryption algorithm is not set")
+	}
+	SetEncryptionAlgorithm("test") // nolint:errcheck
+}
+
+func mustB64Decode(data []byte) []byte {
+	out, err := b64decode(data)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func TestJWT_EncodeEncrypted_Compression(t *testing.T) {
+	SetEncryptionProvider("test", testKey[:32])
+	SetEncryptionAlgorithm("test") // nolint:errcheck
+	SetCompression(true)
+	defer SetCompression(false)
+	content := bytes.Repeat([]byte(`{"permission":"read"}`), 100)
+	token, err := New(content).EncodeEncrypted()
+	if err != nil {
+		t.Fatalf("JWT.EncodeEncrypted() failed: %s", err.Error())
+	}
+	if len(token) >= len(b64encode(content)) {
+		t.Errorf("JWT.EncodeEncrypted() returned token of %d bytes that is not compressed", len(token))
+	}
+	dec, err := DecodeEncrypted(token)
+	if err != nil {
+		t.Fatalf("DecodeEncrypted() failed: %s", err.Error())
+	}
+	if !dec.Valid() || !bytes.Equal(dec.Content, content) || dec.Header.Zip != "DEF" {
+		t.Errorf("DecodeEncrypted() = %+v (validation error: %v), want decompressed content", dec, dec.ValidationError())
+	}
+
+	SetMaxDecompressedSize(len(content) - 1) // nolint:errcheck
+	dec, err = DecodeEncrypted(token)
+	SetMaxDecompressedSize(1 << 20) // nolint:errcheck
+	if err != nil {
+		t.Fatalf("DecodeEncrypted() failed: %s", err.Error())
+	}
+	if dec.Valid() {
+		t.Error("DecodeEncrypted() should return invalid token when decompressed content exceeds size limit")
+	}
+
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+	token, err = New(content).EncodeNested()
+	if err != nil {
+		t.Fatalf("JWT.EncodeNested() failed: %s", err.Error())
+	}
+	dec, err = DecodeEncrypted(token)
+	if err != nil {
+		t.Fatalf("DecodeEncrypted() failed: %s", err.Error())
+	}
+	if !dec.Valid() || !bytes.Equal(dec.Content, content) {
+		t.Errorf("DecodeEncrypted() = %+v (validation error: %v), want decompressed nested content", dec, dec.ValidationError())
+	}
+
+	if _, err := (JWT{Header{Typ: "JWT", Zip: "unknown"}, content, nil}).encrypt(testKey[:32], "A256GCM"); err == nil {
+		t.Error("JWT.encrypt() should fail for unsupported compression algorithm")
+	}
+}
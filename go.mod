@@ -1,12 +1,11 @@
 module github.com/fossoreslp/go-jwt
 
-go 1.12
+go 1.24
 
 require (
-	github.com/agl/ed25519 v0.0.0-20170116200512-5312a6153412 // indirect
-	github.com/fossoreslp/go-uuid-v4 v0.0.0-20180811222708-1631314229c0
-	github.com/kr/pretty v0.1.0 // indirect
-	github.com/otrv4/ed448 v0.0.0-20190315175342-dd0da1df3ff0
-	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	github.com/cloudflare/circl v1.3.7
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
+	golang.org/x/crypto v0.17.0
 )
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,84 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// headerParameters contains the names of all header parameters that are fields of Header
+var headerParameters = jsonNames(reflect.TypeOf(Header{}))
+
+// header has the same fields as Header but uses the default JSON encoding
+type header Header
+
+// MarshalJSON encodes the header including its extension parameters which are appended in alphabetical order
+func (h Header) MarshalJSON() ([]byte, error) {
+	out, err := json.Marshal(header(h))
+	if err != nil || len(h.Extensions) == 0 {
+		return out, err
+	}
+	buf := bytes.NewBuffer(out[:len(out)-1])
+	for _, name := range sortedKeys(h.Extensions) {
+		if headerParameters[name] {
+			return nil, fmt.Errorf("header parameter %s must not be set as an extension", name)
+		}
+		key, _ := json.Marshal(name) // Strings can always be encoded
+		value, err := json.Marshal(h.Extensions[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',') // typ and alg are always encoded so the object is never empty
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes the header and stores all parameters not defined by this package in Extensions.
+// Extension parameters are added to the existing ones so a header can be combined from multiple JSON objects.
+func (h *Header) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*header)(h)); err != nil {
+		return err
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+	for name, value := range params {
+		if headerParameters[name] {
+			continue
+		}
+		if h.Extensions == nil {
+			h.Extensions = make(map[string]interface{})
+		}
+		h.Extensions[name] = value
+	}
+	return nil
+}
+
+// jsonNames returns the JSON names of all encoded fields of a struct type
+func jsonNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestHeader_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []string
+		want    Header
+		wantErr bool
+	}{
+		{"Normal", []string{`{"typ":"JWT","alg":"test"}`}, Header{Typ: "JWT", Alg: "test"}, false},
+		{"Extensions", []string{`{"typ":"JWT","alg":"test","ver":2,"app":"test"}`}, Header{Typ: "JWT", Alg: "test", Extensions: map[string]interface{}{"ver": float64(2), "app": "test"}}, false},
+		{"Combined", []string{`{"typ":"JWT","ver":2}`, `{"alg":"test","app":"test"}`}, Header{Typ: "JWT", Alg: "test", Extensions: map[string]interface{}{"ver": float64(2), "app": "test"}}, false},
+		{"InvalidJSON", []string{`{"typ":"JWT"`}, Header{}, true},
+		{"InvalidField", []string{`{"typ":1}`}, Header{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Header
+			var err error
+			for _, in := range tt.in {
+				if err = json.Unmarshal([]byte(in), &got); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Header.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Header.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeader_MarshalJSON(t *testing.T) {
+	h := Header{Typ: "JWT", Alg: "test", Extensions: map[string]interface{}{"ver": 2}}
+	encoded, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("Header.MarshalJSON() failed: %s", err.Error())
+	}
+	var got Header
+	if err := json.Unmarshal(encoded, &got); err != nil || got.Extensions["ver"] != float64(2) {
+		t.Errorf("Header.MarshalJSON() = %s, want extension ver to be encoded", encoded)
+	}
+}
@@ -0,0 +1,26 @@
+package keyid
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	id, err := New(bytes.NewReader(bytes.Repeat([]byte{0xff}, 16)))
+	if err != nil {
+		t.Fatalf("New() failed: %s", err.Error())
+	}
+	if id != "ffffffff-ffff-4fff-bfff-ffffffffffff" {
+		t.Errorf("New() = %q, want %q", id, "ffffffff-ffff-4fff-bfff-ffffffffffff")
+	}
+	id, err = New(bytes.NewReader(make([]byte, 16)))
+	if err != nil {
+		t.Fatalf("New() failed: %s", err.Error())
+	}
+	if id != "00000000-0000-4000-8000-000000000000" {
+		t.Errorf("New() = %q, want %q", id, "00000000-0000-4000-8000-000000000000")
+	}
+	if _, err := New(bytes.NewReader(make([]byte, 15))); err == nil {
+		t.Error("New() should fail if the random source is exhausted")
+	}
+}
@@ -0,0 +1,162 @@
+package pemutil
+
+import (
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+const (
+	// KeyIDHeader is the PEM header used to store the key ID
+	KeyIDHeader = "Key-ID"
+
+	// KeyURLHeader is the PEM header used to store the key URL
+	KeyURLHeader = "Key-URL"
+)
+
+// Block is a PEM block with the key ID and key URL extracted from its headers
+type Block struct {
+	Type   string
+	Bytes  []byte
+	KeyID  string
+	KeyURL string
+}
+
+// Decode decodes the first PEM block in data
+func Decode(data []byte) (Block, error) {
+	b, _ := pem.Decode(data)
+	if b == nil {
+		return Block{}, errors.New("could not decode PEM block")
+	}
+	return Block{b.Type, b.Bytes, b.Headers[KeyIDHeader], b.Headers[KeyURLHeader]}, nil
+}
+
+// DecodeType decodes the first PEM block in data and checks that it has one of the types
+func DecodeType(data []byte, types ...string) (Block, error) {
+	b, err := Decode(data)
+	if err != nil {
+		return Block{}, err
+	}
+	if !contains(types, b.Type) {
+		return Block{}, errors.New("unsupported PEM block type " + b.Type)
+	}
+	return b, nil
+}
+
+// Encode encodes a key as PEM storing key ID and key URL in the headers if they are set
+func Encode(typ string, key []byte, keyID, keyURL string) []byte {
+	h := make(map[string]string)
+	if keyID != "" {
+		h[KeyIDHeader] = keyID
+	}
+	if keyURL != "" {
+		h[KeyURLHeader] = keyURL
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Headers: h, Bytes: key})
+}
+
+// ReadDir decodes the first PEM block of every file with the extension .pem in dir in lexical order.
+// The key ID defaults to the file name without extension if the block does not have a Key-ID header.
+func ReadDir(dir string) ([]Block, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	blocks := make([]Block, 0, len(files))
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		b, err := Decode(data)
+		if err != nil {
+			return nil, errors.New(filepath.Base(f) + ": " + err.Error())
+		}
+		if b.KeyID == "" {
+			b.KeyID = strings.TrimSuffix(filepath.Base(f), ".pem")
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// KeyAdder is a provider public keys can be added to for verification
+type KeyAdder interface {
+	AddPublicKey(publickey.PublicKey) error
+}
+
+// KeyDir describes the PEM block types a provider reads from a key directory
+type KeyDir struct {
+	Private   []string                                 // Block types of the key used for signing
+	Public    []string                                 // Block types of keys added for verification
+	Ignored   []string                                 // Block types that are skipped
+	Name      string                                   // Name of the signing key used in errors, "private key" if empty
+	PublicKey func(Block) (publickey.PublicKey, error) // Decodes public key blocks, the block content is used as is if nil
+}
+
+// Load reads the PEM blocks in dir using ReadDir. The directory has to contain exactly one signing key which is passed to load.
+// All public keys are added to the provider returned by load except the one with the key ID of the signing key as it may be stored alongside it.
+func (k KeyDir) Load(dir string, load func(Block) (KeyAdder, error)) error {
+	name := k.Name
+	if name == "" {
+		name = "private key"
+	}
+	blocks, err := ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var private *Block
+	var keys []publickey.PublicKey
+	for i, b := range blocks {
+		switch {
+		case contains(k.Ignored, b.Type):
+			continue
+		case contains(k.Public, b.Type):
+			pub := publickey.New(b.Bytes, b.KeyID)
+			if k.PublicKey != nil {
+				if pub, err = k.PublicKey(b); err != nil {
+					return err
+				}
+			}
+			keys = append(keys, pub)
+		case contains(k.Private, b.Type):
+			if private != nil {
+				return errors.New("directory contains more than one " + name)
+			}
+			private = &blocks[i]
+		default:
+			return errors.New("unsupported PEM block type " + b.Type)
+		}
+	}
+	if private == nil {
+		return errors.New("directory does not contain a " + name)
+	}
+	p, err := load(*private)
+	if err != nil {
+		return err
+	}
+	for _, pub := range keys {
+		if pub.GetKeyID() == private.KeyID {
+			continue
+		}
+		if err := p.AddPublicKey(pub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package pemutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		keyID  string
+		keyURL string
+		want   string
+	}{
+		{"Plain", "", "", "-----BEGIN TEST-----\ndGVzdA==\n-----END TEST-----\n"},
+		{"Key ID", "key_id", "", "-----BEGIN TEST-----\nKey-ID: key_id\n\ndGVzdA==\n-----END TEST-----\n"},
+		{"Key ID and URL", "key_id", "key_url", "-----BEGIN TEST-----\nKey-ID: key_id\nKey-URL: key_url\n\ndGVzdA==\n-----END TEST-----\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Encode("TEST", []byte("test"), tt.keyID, tt.keyURL)
+			if string(got) != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+			b, err := Decode(got)
+			if err != nil {
+				t.Fatalf("Decode() failed: %s", err.Error())
+			}
+			if want := (Block{"TEST", []byte("test"), tt.keyID, tt.keyURL}); !reflect.DeepEqual(b, want) {
+				t.Errorf("Decode() = %v, want %v", b, want)
+			}
+		})
+	}
+	if _, err := Decode([]byte("no PEM block")); err == nil {
+		t.Error("Decode() should fail when there is no PEM block")
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		t.Fatalf("Could not create directory: %s", err.Error())
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+	files := map[string][]byte{
+		"b.pem":   Encode("TEST", []byte("b"), "", ""),
+		"a.pem":   Encode("TEST", []byte("a"), "key_id", "key_url"),
+		"c.txt":   []byte("ignored"),
+		"d.pem.1": []byte("ignored"),
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("Could not write file: %s", err.Error())
+		}
+	}
+	got, err := ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %s", err.Error())
+	}
+	want := []Block{{"TEST", []byte("a"), "key_id", "key_url"}, {"TEST", []byte("b"), "b", ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDir() = %v, want %v", got, want)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "e.pem"), []byte("no PEM block"), 0600); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+	if _, err := ReadDir(dir); err == nil {
+		t.Error("ReadDir() should fail for files without PEM block")
+	}
+	if err := os.Mkdir(filepath.Join(dir, "f.pem"), 0700); err != nil {
+		t.Fatalf("Could not create directory: %s", err.Error())
+	}
+	if _, err := ReadDir(dir); err == nil {
+		t.Error("ReadDir() should fail when a file cannot be read")
+	}
+	if _, err := ReadDir("["); err == nil {
+		t.Error("ReadDir() should fail for malformed pattern")
+	}
+}
+
+func TestDecodeType(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"First type", Encode("A", []byte("test"), "", ""), false},
+		{"Second type", Encode("B", []byte("test"), "", ""), false},
+		{"Other type", Encode("C", []byte("test"), "", ""), true},
+		{"No PEM block", []byte("no PEM block"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeType(tt.data, "A", "B"); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type testProvider struct {
+	private Block
+	keys    []publickey.PublicKey
+}
+
+func (p *testProvider) AddPublicKey(k publickey.PublicKey) error {
+	if string(k.GetPublicKey()) == "invalid" {
+		return errors.New("invalid key")
+	}
+	p.keys = append(p.keys, k)
+	return nil
+}
+
+func TestKeyDir_Load(t *testing.T) {
+	keyDir := KeyDir{Private: []string{"PRIVATE", "OTHER PRIVATE"}, Public: []string{"PUBLIC"}, Ignored: []string{"IGNORED"}}
+	decoded := keyDir
+	decoded.PublicKey = func(b Block) (publickey.PublicKey, error) {
+		if string(b.Bytes) == "undecodable" {
+			return publickey.PublicKey{}, errors.New("could not decode key")
+		}
+		return publickey.New(append([]byte("decoded "), b.Bytes...), b.KeyID), nil
+	}
+	tests := []struct {
+		name    string
+		keyDir  KeyDir
+		files   map[string][]byte
+		want    []publickey.PublicKey
+		wantErr bool
+	}{
+		{"Keys", keyDir, map[string][]byte{
+			"signing.pem":        Encode("PRIVATE", []byte("private"), "", ""),
+			"signing_public.pem": Encode("PUBLIC", []byte("own"), "signing", ""),
+			"other.pem":          Encode("PUBLIC", []byte("other"), "", ""),
+			"legacy.pem":         Encode("IGNORED", []byte("legacy"), "", ""),
+			"ignored.txt":        []byte("not a key"),
+		}, []publickey.PublicKey{publickey.New([]byte("other"), "other")}, false},
+		{"Decoded keys", decoded, map[string][]byte{
+			"signing.pem": Encode("OTHER PRIVATE", []byte("private"), "", ""),
+			"other.pem":   Encode("PUBLIC", []byte("other"), "", ""),
+		}, []publickey.PublicKey{publickey.New([]byte("decoded other"), "other")}, false},
+		{"Undecodable public key", decoded, map[string][]byte{
+			"signing.pem": Encode("PRIVATE", []byte("private"), "", ""),
+			"other.pem":   Encode("PUBLIC", []byte("undecodable"), "", ""),
+		}, nil, true},
+		{"No private key", keyDir, map[string][]byte{
+			"other.pem": Encode("PUBLIC", []byte("other"), "", ""),
+		}, nil, true},
+		{"More than one private key", keyDir, map[string][]byte{
+			"signing.pem": Encode("PRIVATE", []byte("private"), "", ""),
+			"second.pem":  Encode("OTHER PRIVATE", []byte("private"), "", ""),
+		}, nil, true},
+		{"Unsupported block type", keyDir, map[string][]byte{
+			"signing.pem":     Encode("PRIVATE", []byte("private"), "", ""),
+			"unsupported.pem": Encode("CERTIFICATE", []byte("test"), "", ""),
+		}, nil, true},
+		{"No PEM block", keyDir, map[string][]byte{
+			"signing.pem":     Encode("PRIVATE", []byte("private"), "", ""),
+			"unsupported.pem": []byte("no PEM block"),
+		}, nil, true},
+		{"Invalid private key", keyDir, map[string][]byte{
+			"signing.pem": Encode("PRIVATE", []byte("invalid"), "", ""),
+		}, nil, true},
+		{"Public key rejected", keyDir, map[string][]byte{
+			"signing.pem": Encode("PRIVATE", []byte("private"), "", ""),
+			"other.pem":   Encode("PUBLIC", []byte("invalid"), "", ""),
+		}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "jwt")
+			if err != nil {
+				t.Fatalf("Could not create directory: %s", err.Error())
+			}
+			defer os.RemoveAll(dir) // nolint:errcheck
+			for name, data := range tt.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+					t.Fatalf("Could not write file: %s", err.Error())
+				}
+			}
+			p := &testProvider{}
+			err = tt.keyDir.Load(dir, func(b Block) (KeyAdder, error) {
+				if string(b.Bytes) == "invalid" {
+					return nil, errors.New("invalid key")
+				}
+				p.private = b
+				return p, nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KeyDir.Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if p.private.KeyID != "signing" || string(p.private.Bytes) != "private" {
+				t.Errorf("KeyDir.Load() loaded private key %v", p.private)
+			}
+			if !reflect.DeepEqual(p.keys, tt.want) {
+				t.Errorf("KeyDir.Load() added keys %v, want %v", p.keys, tt.want)
+			}
+		})
+	}
+	if err := keyDir.Load("[", nil); err == nil {
+		t.Error("KeyDir.Load() should fail for malformed pattern")
+	}
+}
@@ -0,0 +1,123 @@
+// Package providertest checks behaviour shared by the signature providers of this module.
+// All checks only use the public methods of the providers, so every provider is tested the same way.
+package providertest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/keyresolver"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Provider is a signature provider whose keys can be managed and which may consult a key resolver
+type Provider interface {
+	jwt.KeyedSignatureProvider
+	SetKeyResolver(jwt.KeyResolver)
+}
+
+// signingInput returns the signing input of a token using the header
+func signingInput(t *testing.T, h jwt.Header) []byte {
+	h.Typ = "JWT"
+	header, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("Could not encode header: %s", err.Error())
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"test"}`)))
+}
+
+// sign signs a token using the header with the signer and sets the key ID of the header to the one of the signer
+func sign(t *testing.T, signer jwt.KeyedSignatureProvider, h jwt.Header) ([]byte, []byte, jwt.Header) {
+	h.Kid = signer.CurrentKey().GetKeyID()
+	data := signingInput(t, h)
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatalf("Could not sign data: %s", err.Error())
+	}
+	return data, sig, h
+}
+
+// KeyResolver checks that the verifier consults its key resolver for key IDs it does not know.
+// The header has to contain the algorithm and, if required by the algorithm, the curve used by both providers.
+func KeyResolver(t *testing.T, signer, verifier Provider, h jwt.Header) {
+	data, sig, h := sign(t, signer, h)
+	if verifier.Verify(data, sig, h) == nil {
+		t.Error("Provider.Verify() should fail for unknown key ID without a key resolver")
+	}
+	verifier.SetKeyResolver(keyresolver.NewStaticResolver(verifier.CurrentKey()))
+	if verifier.Verify(data, sig, h) == nil {
+		t.Error("Provider.Verify() should fail when the resolver does not know the key")
+	}
+	verifier.SetKeyResolver(keyresolver.NewStaticResolver(signer.CurrentKey()))
+	if err := verifier.Verify(data, sig, h); err != nil {
+		t.Errorf("Provider.Verify() failed using key from resolver: %s", err.Error())
+	}
+	if verifier.Verify(data, make([]byte, len(sig)), h) == nil {
+		t.Error("Provider.Verify() should fail for invalid signature using key from resolver")
+	}
+	verifier.SetKeyResolver(nil)
+}
+
+// Signer checks that a provider using an external signer and a provider holding the same private key can verify each other's signatures.
+// The header has to contain the algorithm and, if required by the algorithm, the curve used by both providers.
+func Signer(t *testing.T, external, local jwt.KeyedSignatureProvider, h jwt.Header) {
+	if !reflect.DeepEqual(external.CurrentKey(), local.CurrentKey()) {
+		t.Errorf("Provider.CurrentKey() = %v, want %v", external.CurrentKey(), local.CurrentKey())
+	}
+	data, sig, h := sign(t, external, h)
+	if err := local.Verify(data, sig, h); err != nil {
+		t.Errorf("Signature created using signer should be valid for provider holding the private key: %s", err.Error())
+	}
+	if err := external.Verify(data, sig, h); err != nil {
+		t.Errorf("Signature created using signer should be valid for the provider using it: %s", err.Error())
+	}
+	data, sig, h = sign(t, local, h)
+	if err := external.Verify(data, sig, h); err != nil {
+		t.Errorf("Signature created using the private key should be valid for provider using signer: %s", err.Error())
+	}
+}
+
+// KeyValidity checks that the verifier only accepts keys during their validity period.
+// The header has to contain the algorithm and, if required by the algorithm, the curve used by both providers.
+func KeyValidity(t *testing.T, signer, verifier jwt.KeyedSignatureProvider, h jwt.Header) {
+	data, sig, h := sign(t, signer, h)
+	now := time.Now()
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		expires   time.Time
+		wantErr   bool
+	}{
+		{"Unrestricted", time.Time{}, time.Time{}, false},
+		{"Valid", now.Add(-time.Hour), now.Add(time.Hour), false},
+		{"Not valid yet", now.Add(time.Hour), time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Keys that are not valid yet are accepted when added but rejected when verifying
+			if err := verifier.AddPublicKey(publickey.New(signer.CurrentKey().GetPublicKey(), tt.name).WithValidity(tt.notBefore, tt.expires)); err != nil {
+				t.Fatalf("Provider.AddPublicKey() error = %v", err)
+			}
+			h.Kid = tt.name
+			if err := verifier.Verify(data, sig, h); (err != nil) != tt.wantErr {
+				t.Errorf("Provider.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	// Keys expiring after they were added are rejected as well
+	if err := verifier.AddPublicKey(publickey.New(signer.CurrentKey().GetPublicKey(), "Expiring").WithValidity(time.Time{}, time.Now().Add(50*time.Millisecond))); err != nil {
+		t.Fatalf("Provider.AddPublicKey() error = %v", err)
+	}
+	h.Kid = "Expiring"
+	if err := verifier.Verify(data, sig, h); err != nil {
+		t.Errorf("Provider.Verify() failed before the key expired: %s", err.Error())
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := verifier.Verify(data, sig, h); err == nil {
+		t.Error("Provider.Verify() succeeded using an expired key")
+	}
+}
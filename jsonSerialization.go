@@ -0,0 +1,275 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// Signer selects the signature provider used to create a signature with EncodeJSON or EncodeFlattened
+type Signer struct {
+	Algorithm   string                 // Name of the signature provider, the default signing algorithm is used if empty
+	Unprotected map[string]interface{} // Header parameters added to the signature without integrity protection
+}
+
+// Signature contains a signature of a token decoded from the JWS JSON serialization
+type Signature struct {
+	Header          Header                 // Combined protected and unprotected header parameters
+	Unprotected     map[string]interface{} // Header parameters without integrity protection
+	signer          string                 // Algorithm and key ID identifying the key that verified the signature
+	validationError error
+}
+
+// Valid returns whether the signature could be verified
+func (s Signature) Valid() bool {
+	return s.validationError == nil
+}
+
+// ValidationError returns the error that occurred during verification of the signature or nil
+func (s Signature) ValidationError() error {
+	return s.validationError
+}
+
+// jsonSignature is a signature in the JWS JSON serialization as defined in RFC 7515 section 7.2
+type jsonSignature struct {
+	Protected string                 `json:"protected,omitempty"`
+	Header    map[string]interface{} `json:"header,omitempty"`
+	Signature string                 `json:"signature,omitempty"`
+}
+
+// jsonSerialization contains both the general and the flattened JWS JSON serialization
+type jsonSerialization struct {
+	Payload    string          `json:"payload"`
+	Signatures []jsonSignature `json:"signatures,omitempty"`
+	jsonSignature
+}
+
+// EncodeJSON signs a JWT using the signers and encodes it using the general JWS JSON serialization defined in RFC 7515 section 7.2.1.
+// The default signing algorithm is used in case no signers are supplied.
+func (t JWT) EncodeJSON(signers ...Signer) ([]byte, error) {
+	if len(signers) == 0 {
+		signers = []Signer{{}}
+	}
+	payload, _ := t.payload(false) // Only fails for the compact serialization
+	out := jsonSerialization{Payload: string(payload)}
+	for _, s := range signers {
+		sig, err := t.signJSON(s, out.Payload)
+		if err != nil {
+			return nil, err
+		}
+		out.Signatures = append(out.Signatures, sig)
+	}
+	return json.Marshal(out)
+}
+
+// EncodeFlattened signs a JWT using the signer and encodes it using the flattened JWS JSON serialization defined in RFC 7515 section 7.2.2
+func (t JWT) EncodeFlattened(signer Signer) ([]byte, error) {
+	payload, _ := t.payload(false) // Only fails for the compact serialization
+	out := jsonSerialization{Payload: string(payload)}
+	sig, err := t.signJSON(signer, out.Payload)
+	if err != nil {
+		return nil, err
+	}
+	out.jsonSignature = sig
+	return json.Marshal(out)
+}
+
+func (t JWT) signJSON(s Signer, payload string) (jsonSignature, error) {
+	registryMu.RLock()
+	name := s.Algorithm
+	if name == "" {
+		name = defaultAlgorithm
+	}
+	alg := signatureProviders[name]
+	registryMu.RUnlock()
+	if name == "" {
+		return jsonSignature{}, errors.New("default algorithm is not set - cannot sign JWT")
+	}
+	if alg == nil {
+		return jsonSignature{}, fmt.Errorf("algorithm %s does not exist", name)
+	}
+	alg.Header(&t.Header)
+	t.Header.normalizeCritical()
+	header, err := encodeHeader(t.Header)
+	if err != nil {
+		return jsonSignature{}, err
+	}
+	protected, _ := protectedParameters(header) // The header has just been encoded so it can always be decoded
+	for k := range s.Unprotected {
+		if _, ok := protected[k]; ok {
+			return jsonSignature{}, fmt.Errorf("header parameter %s must not be both protected and unprotected", k)
+		}
+	}
+	sig, err := alg.Sign(join(header, []byte(payload)))
+	if err != nil {
+		return jsonSignature{}, err
+	}
+	return jsonSignature{string(header), s.Unprotected, string(b64encode(sig))}, nil
+}
+
+// DecodeJSON decodes a JWS in general or flattened JSON serialization and verifies all of it's signatures.
+// The token is valid when the signatures satisfy the policy set using SetSignaturePolicy and the content is valid (use Valid() on JWT to check).
+// By default at least one signature has to be valid.
+// Its header is the header of the first valid signature or the first signature in case none of them is valid.
+// The result of verifying every single signature is returned as well.
+func DecodeJSON(in []byte) (JWT, []Signature, error) {
+	registryMu.RLock()
+	policy := signaturePolicy
+	registryMu.RUnlock()
+	return DecodeJSONWithPolicy(in, policy)
+}
+
+// DecodeJSONWithPolicy works just like DecodeJSON but uses the supplied policy instead of the one set using SetSignaturePolicy
+func DecodeJSONWithPolicy(in []byte, policy SignaturePolicy) (data JWT, signatures []Signature, err error) {
+	var s jsonSerialization
+	if err = json.Unmarshal(in, &s); err != nil {
+		return
+	}
+	raw := s.Signatures
+	if s.Protected != "" || s.Header != nil || s.Signature != "" {
+		if len(raw) != 0 {
+			err = errors.New("token must not use both general and flattened serialization")
+			return
+		}
+		raw = []jsonSignature{s.jsonSignature}
+	}
+	if len(raw) == 0 {
+		err = errors.New("token does not contain any signatures")
+		return
+	}
+	if err = checkDuplicateSignatures(raw); err != nil {
+		return
+	}
+
+	signatures = make([]Signature, len(raw))
+	for i, r := range raw {
+		if signatures[i], err = decodeSignature(r, s.Payload); err != nil {
+			signatures = nil
+			return
+		}
+		// RFC 7797 section 3 requires all signatures to agree on whether the payload is encoded
+		if signatures[i].Header.encodedPayload() != signatures[0].Header.encodedPayload() {
+			err = errors.New("signatures must not differ in the b64 header parameter")
+			signatures = nil
+			return
+		}
+	}
+
+	if data.Content, err = decodePayload(signatures[0].Header, []byte(s.Payload)); err != nil {
+		signatures = nil
+		return
+	}
+
+	data.Header = signatures[0].Header
+	for _, sig := range signatures {
+		if sig.Valid() {
+			data.Header = sig.Header
+			break
+		}
+	}
+	data.validationError = policy.check(signatures)
+	if data.validationError == nil {
+		data.validationError = validateContent(data.Content)
+	}
+
+	return
+}
+
+// checkDuplicateSignatures returns an error if two signatures share the same protected header and signature value.
+// Unprotected header parameters are ignored as they can be changed without invalidating the signature.
+func checkDuplicateSignatures(raw []jsonSignature) error {
+	seen := make(map[string]bool, len(raw))
+	for _, r := range raw {
+		signature, err := b64decode([]byte(r.Signature))
+		if err != nil {
+			return err
+		}
+		id := r.Protected + "." + string(signature)
+		if seen[id] {
+			return errors.New("token contains duplicate signatures")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// decodeSignature decodes the header of a signature and verifies it. Errors during verification are stored in the signature.
+func decodeSignature(r jsonSignature, payload string) (sig Signature, err error) {
+	protected, err := protectedParameters([]byte(r.Protected))
+	if err != nil {
+		return
+	}
+	for k := range r.Header {
+		if _, ok := protected[k]; ok {
+			err = fmt.Errorf("header parameter %s must not be both protected and unprotected", k)
+			return
+		}
+		if k == "crit" || k == "b64" {
+			err = fmt.Errorf("header parameter %s must be protected", k)
+			return
+		}
+	}
+	signature, err := b64decode([]byte(r.Signature))
+	if err != nil {
+		return
+	}
+
+	// Parameters from the protected header are decoded first and then extended by the unprotected ones as they are disjoint
+	if r.Protected != "" {
+		headerJSON, _ := b64decode([]byte(r.Protected)) // Already decoded successfully by protectedParameters
+		if err = json.Unmarshal(headerJSON, &sig.Header); err != nil {
+			return
+		}
+	}
+	if r.Header != nil {
+		unprotected, _ := json.Marshal(r.Header) // Values decoded from JSON can always be encoded again
+		if err = json.Unmarshal(unprotected, &sig.Header); err != nil {
+			return
+		}
+	}
+	sig.Unprotected = r.Header
+
+	if sig.Header.Typ != "JWT" {
+		sig.validationError = errors.New("header suggests token is not a JWT")
+		return
+	}
+	if sig.validationError = sig.Header.checkCritical(protected); sig.validationError != nil {
+		return
+	}
+	alg, e := sig.Header.getAlgorithm()
+	if e != nil {
+		sig.validationError = e
+		return
+	}
+	sig.signer = signerID(alg, sig.Header)
+	sig.validationError = alg.Verify(join([]byte(r.Protected), []byte(payload)), signature, sig.Header)
+	return
+}
+
+// signerID identifies the key verifying a signature by the algorithm and the key ID the provider looks the key up by.
+// Providers verify signatures without a key ID using their current key, so its key ID is used in that case to count both forms of the same key as one signer.
+func signerID(alg SignatureProvider, h Header) string {
+	kid := h.Kid
+	if p, ok := alg.(interface{ CurrentKey() publickey.PublicKey }); ok && kid == "" {
+		kid = p.CurrentKey().GetKeyID()
+	}
+	return h.Alg + "." + kid
+}
+
+// protectedParameters returns the names of the parameters in the encoded protected header
+func protectedParameters(header []byte) (map[string]json.RawMessage, error) {
+	params := make(map[string]json.RawMessage)
+	if len(header) == 0 {
+		return params, nil
+	}
+	headerJSON, err := b64decode(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(headerJSON, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
@@ -0,0 +1,211 @@
+package jwt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJWT_EncodeJSON(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	if err := SetSignatureProvider("other", TestAlgorithm("other")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	if err := SetSignatureProvider("error", TestAlgorithm("error")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	defer RemoveSignatureProvider("other")
+	defer RemoveSignatureProvider("error")
+	SetSigningAlgorithm("test") // nolint:errcheck
+	token := New([]byte(`{"name":"test","use":"testing"}`))
+	tests := []struct {
+		name           string
+		signers        []Signer
+		wantSignatures int
+		wantErr        bool
+	}{
+		{"Default", nil, 1, false},
+		{"Multiple", []Signer{{Algorithm: "test"}, {Algorithm: "other", Unprotected: map[string]interface{}{"kid": "other"}}}, 2, false},
+		{"UnknownAlgorithm", []Signer{{Algorithm: "unknown"}}, 0, true},
+		{"SigningFailed", []Signer{{Algorithm: "test"}, {Algorithm: "error"}}, 0, true},
+		{"ProtectedParameter", []Signer{{Algorithm: "test", Unprotected: map[string]interface{}{"alg": "none"}}}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := token.EncodeJSON(tt.signers...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("JWT.EncodeJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			var s jsonSerialization
+			if err := json.Unmarshal(got, &s); err != nil {
+				t.Fatalf("JWT.EncodeJSON() returned invalid JSON: %s", err.Error())
+			}
+			if len(s.Signatures) != tt.wantSignatures || s.Signature != "" || s.Protected != "" {
+				t.Errorf("JWT.EncodeJSON() = %s, want general serialization with %d signatures", got, tt.wantSignatures)
+			}
+			dec, signatures, err := DecodeJSON(got)
+			if err != nil {
+				t.Fatalf("DecodeJSON() failed: %s", err.Error())
+			}
+			if !dec.Valid() || string(dec.Content) != string(token.Content) || len(signatures) != tt.wantSignatures {
+				t.Errorf("DecodeJSON() = %+v with %d signatures (validation error: %v)", dec, len(signatures), dec.ValidationError())
+			}
+		})
+	}
+
+	defaultAlgorithm = ""
+	if _, err := token.EncodeJSON(); err == nil {
+		t.Error("JWT.EncodeJSON() should fail when default algorithm is not set")
+	}
+	SetSigningAlgorithm("test") // nolint:errcheck
+}
+
+func TestJWT_EncodeFlattened(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	token := New([]byte(`{"name":"test","use":"testing"}`))
+	got, err := token.EncodeFlattened(Signer{"test", map[string]interface{}{"kid": "unprotected"}})
+	if err != nil {
+		t.Fatalf("JWT.EncodeFlattened() failed: %s", err.Error())
+	}
+	var s jsonSerialization
+	if err := json.Unmarshal(got, &s); err != nil {
+		t.Fatalf("JWT.EncodeFlattened() returned invalid JSON: %s", err.Error())
+	}
+	if len(s.Signatures) != 0 || s.Signature == "" || s.Protected == "" || s.Header["kid"] != "unprotected" {
+		t.Errorf("JWT.EncodeFlattened() = %s, want flattened serialization with unprotected key ID", got)
+	}
+	dec, signatures, err := DecodeJSON(got)
+	if err != nil {
+		t.Fatalf("DecodeJSON() failed: %s", err.Error())
+	}
+	if !dec.Valid() || dec.Header.Kid != "unprotected" || dec.Header.Alg != "test" {
+		t.Errorf("DecodeJSON() = %+v (validation error: %v), want header combining protected and unprotected parameters", dec, dec.ValidationError())
+	}
+	if len(signatures) != 1 || signatures[0].Unprotected["kid"] != "unprotected" {
+		t.Errorf("DecodeJSON() returned signatures %+v", signatures)
+	}
+
+	if _, err := token.EncodeFlattened(Signer{Algorithm: "unknown"}); err == nil {
+		t.Error("JWT.EncodeFlattened() should fail for unknown algorithm")
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	// The test algorithm accepts any signature that is four bytes longer than the signing input
+	payload := `"payload":"eyJuYW1lIjoidGVzdCJ9"`
+	protected := `"protected":"eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0"`
+	valid := `"signature":"` + string(b64encode(make([]byte, 4+len("eyJ0eXAiOiJKV1QiLCJhbGciOiJ0ZXN0In0.eyJuYW1lIjoidGVzdCJ9")))) + `"`
+	unprotectedOnly := `"signature":"` + string(b64encode(make([]byte, 4+len(".eyJuYW1lIjoidGVzdCJ9")))) + `"`
+	invalid := `"signature":"dGVzdA"`
+	tests := []struct {
+		name           string
+		in             string
+		wantSignatures int
+		wantValid      bool
+		wantErr        bool
+	}{
+		{"Flattened", `{` + payload + `,` + protected + `,` + valid + `}`, 1, true, false},
+		{"General", `{` + payload + `,"signatures":[{` + protected + `,` + valid + `}]}`, 1, true, false},
+		{"UnprotectedOnly", `{` + payload + `,"header":{"typ":"JWT","alg":"test"},` + unprotectedOnly + `}`, 1, true, false},
+		{"OneValidSignature", `{` + payload + `,"signatures":[{` + protected + `,` + invalid + `},{` + protected + `,` + valid + `}]}`, 2, true, false},
+		{"InvalidSignature", `{` + payload + `,` + protected + `,` + invalid + `}`, 1, false, false},
+		{"NoValidSignature", `{` + payload + `,"signatures":[{` + protected + `,` + invalid + `},{` + protected + `,"signature":"aW52YWxpZA"}]}`, 2, false, false},
+		{"UnknownAlgorithm", `{` + payload + `,"header":{"typ":"JWT","alg":"unknown"},` + unprotectedOnly + `}`, 1, false, false},
+		{"NotJWT", `{` + payload + `,"header":{"alg":"test"},` + unprotectedOnly + `}`, 1, false, false},
+		{"InvalidJSON", `{`, 0, false, true},
+		{"BothSerializations", `{` + payload + `,` + protected + `,` + valid + `,"signatures":[{` + protected + `,` + valid + `}]}`, 0, false, true},
+		{"NoSignatures", `{` + payload + `}`, 0, false, true},
+		{"InvalidPayload", `{"payload":"A",` + protected + `,` + valid + `}`, 0, false, true},
+		{"InvalidProtectedBase64", `{` + payload + `,"protected":"A",` + valid + `}`, 0, false, true},
+		{"InvalidProtectedJSON", `{` + payload + `,"protected":"YQ",` + valid + `}`, 0, false, true},
+		{"InvalidProtectedHeader", `{` + payload + `,"protected":"eyJ0eXAiOjF9",` + valid + `}`, 0, false, true},
+		{"InvalidUnprotectedHeader", `{` + payload + `,"header":{"typ":1},` + valid + `}`, 0, false, true},
+		{"InvalidSignatureBase64", `{` + payload + `,` + protected + `,"signature":"A"}`, 0, false, true},
+		{"DuplicateSignature", `{` + payload + `,"signatures":[{` + protected + `,` + valid + `},{` + protected + `,` + valid + `}]}`, 0, false, true},
+		{"DuplicateSignatureUnprotected", `{` + payload + `,"signatures":[{` + protected + `,` + valid + `},{` + protected + `,"header":{"x":1},` + valid + `}]}`, 0, false, true},
+		{"DuplicateParameter", `{` + payload + `,` + protected + `,"header":{"alg":"test"},` + valid + `}`, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, signatures, err := DecodeJSON([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(signatures) != tt.wantSignatures {
+				t.Errorf("DecodeJSON() returned %d signatures, want %d", len(signatures), tt.wantSignatures)
+			}
+			if got.Valid() != tt.wantValid {
+				t.Errorf("DecodeJSON() valid = %v (validation error: %v), want %v", got.Valid(), got.ValidationError(), tt.wantValid)
+			}
+			if tt.wantValid && string(got.Content) != `{"name":"test"}` {
+				t.Errorf("DecodeJSON() content = %s, want %s", got.Content, `{"name":"test"}`)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_ContentValidation(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test")                                // nolint:errcheck
+	AddValidationProvider("json", testValidationProvider(0x0)) // nolint:errcheck
+	defer RemoveValidationProvider("json")
+	token, err := JWT{Header{Typ: "JWT"}, []byte{0xFF}, nil}.EncodeFlattened(Signer{})
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	got, signatures, err := DecodeJSON(token)
+	if err != nil {
+		t.Fatalf("DecodeJSON() failed: %s", err.Error())
+	}
+	if got.Valid() || !signatures[0].Valid() || signatures[0].ValidationError() != nil {
+		t.Error("DecodeJSON() should return invalid token with valid signature when content validation fails")
+	}
+}
+
+func TestDecodeJSONWithPolicy_DuplicateSignatures(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	token, err := New([]byte(`{"test":1}`)).EncodeFlattened(Signer{Algorithm: "test"})
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	var flattened map[string]interface{}
+	if err := json.Unmarshal(token, &flattened); err != nil {
+		t.Fatalf("Could not decode JWT: %s", err.Error())
+	}
+	sig := map[string]interface{}{"protected": flattened["protected"], "signature": flattened["signature"]}
+	modified := map[string]interface{}{"protected": flattened["protected"], "header": map[string]interface{}{"x": 1}, "signature": flattened["signature"]}
+	tests := []struct {
+		name       string
+		signatures []map[string]interface{}
+	}{
+		{"Copies", []map[string]interface{}{sig, sig, sig}},
+		{"CopiesWithUnprotectedHeader", []map[string]interface{}{sig, modified, sig}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, _ := json.Marshal(map[string]interface{}{"payload": flattened["payload"], "signatures": tt.signatures})
+			got, _, err := DecodeJSONWithPolicy(in, Quorum(3))
+			if err == nil && got.Valid() {
+				t.Error("DecodeJSONWithPolicy() should not accept a copied signature as multiple signers")
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// subjectPublicKeyInfo is the PKIX structure containing a public key as defined in RFC 5280 section 4.1
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// Key contains the parameters of a JSON web key as defined in RFC 7517
+type Key struct {
+	Kty    string   `json:"kty"`
+	Kid    string   `json:"kid,omitempty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	Crv    string   `json:"crv,omitempty"`
+	N      string   `json:"n,omitempty"`
+	E      string   `json:"e,omitempty"`
+	X      string   `json:"x,omitempty"`
+	Y      string   `json:"y,omitempty"`
+	K      string   `json:"k,omitempty"`
+}
+
+// Set contains a JSON web key set
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// ParseSet decodes a JSON web key set
+func ParseSet(in []byte) (Set, error) {
+	var s Set
+	if err := json.Unmarshal(in, &s); err != nil {
+		return Set{}, err
+	}
+	return s, nil
+}
+
+// PublicKey converts the JSON web key to a public key that can be used with the signature providers.
+// RSA and EC keys are encoded as PKIX, OKP and symmetric keys are returned as raw bytes.
+// The key type, algorithm, use and key operations are kept as metadata so providers reject keys that are not intended for them.
+func (k Key) PublicKey() (publickey.PublicKey, error) {
+	pub, err := k.rawPublicKey()
+	if err != nil {
+		return publickey.PublicKey{}, err
+	}
+	return pub.WithKeyType(k.Kty).WithAlgorithm(k.Alg).WithUse(k.Use).WithKeyOps(k.KeyOps...), nil
+}
+
+// rawPublicKey converts the key material of the JSON web key to a public key without metadata
+func (k Key) rawPublicKey() (publickey.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return publickey.PublicKey{}, errors.New("RSA exponent is too large")
+		}
+		enc, err := x509.MarshalPKIXPublicKey(&rsa.PublicKey{N: n, E: int(e.Int64())})
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		return publickey.New(enc, k.Kid), nil
+	case "EC":
+		c := curve(k.Crv)
+		if c == nil {
+			return publickey.PublicKey{}, errors.New("unsupported curve")
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		if c == secp256k1.S256() {
+			enc, err := marshalSecp256k1(x, y)
+			if err != nil {
+				return publickey.PublicKey{}, err
+			}
+			return publickey.New(enc, k.Kid), nil
+		}
+		if !c.IsOnCurve(x, y) {
+			return publickey.PublicKey{}, errors.New("point is not on curve")
+		}
+		enc, err := x509.MarshalPKIXPublicKey(&ecdsa.PublicKey{Curve: c, X: x, Y: y})
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		return publickey.New(enc, k.Kid), nil
+	case "OKP":
+		if k.Crv != "Ed25519" && k.Crv != "Ed448" {
+			return publickey.PublicKey{}, errors.New("unsupported curve")
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		return publickey.New(x, k.Kid), nil
+	case "oct":
+		key, err := decode(k.K)
+		if err != nil {
+			return publickey.PublicKey{}, err
+		}
+		return publickey.New(key, k.Kid), nil
+	}
+	return publickey.PublicKey{}, errors.New("unsupported key type")
+}
+
+// NewECKey returns the JSON web key representing an elliptic curve public key on one of the NIST curves or secp256k1
+func NewECKey(pub *ecdsa.PublicKey) (Key, error) {
+	var crv string
+	switch pub.Curve {
+	case elliptic.P256():
+		crv = "P-256"
+	case elliptic.P384():
+		crv = "P-384"
+	case elliptic.P521():
+		crv = "P-521"
+	case secp256k1.S256():
+		crv = "secp256k1"
+	default:
+		return Key{}, errors.New("unsupported curve")
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	return Key{Kty: "EC", Crv: crv, X: encodeInt(pub.X, size), Y: encodeInt(pub.Y, size)}, nil
+}
+
+func curve(crv string) elliptic.Curve {
+	switch crv {
+	case "P-256":
+		return elliptic.P256()
+	case "P-384":
+		return elliptic.P384()
+	case "P-521":
+		return elliptic.P521()
+	case "secp256k1":
+		return secp256k1.S256()
+	}
+	return nil
+}
+
+// marshalSecp256k1 encodes a point on secp256k1 as PKIX as the curve is not supported by crypto/x509.
+// It fails if the point is not on the curve.
+func marshalSecp256k1(x, y *big.Int) ([]byte, error) {
+	if x.BitLen() > 256 || y.BitLen() > 256 {
+		return nil, errors.New("point is not on curve")
+	}
+	point := make([]byte, 65)
+	point[0] = 4
+	x.FillBytes(point[1:33])
+	y.FillBytes(point[33:])
+	if _, err := secp256k1.ParsePubKey(point); err != nil {
+		return nil, errors.New("point is not on curve")
+	}
+	params, _ := asn1.Marshal(oidCurveSecp256k1) // An object identifier can always be marshaled
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+func decode(in string) ([]byte, error) {
+	if in == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	return base64.RawURLEncoding.DecodeString(in)
+}
+
+func decodeInt(in string) (*big.Int, error) {
+	b, err := decode(in)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encodeInt encodes the integer as a big-endian byte slice of the specified size as required for coordinates by RFC 7518 section 6.2.1.2
+func encodeInt(i *big.Int, size int) string {
+	b := i.Bytes()
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
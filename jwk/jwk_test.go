@@ -0,0 +1,159 @@
+package jwk
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestParseSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    Set
+		wantErr bool
+	}{
+		{"Normal", []byte(`{"keys":[{"kty":"oct","kid":"key_id","k":"dGVzdA"}]}`), Set{[]Key{{Kty: "oct", Kid: "key_id", K: "dGVzdA"}}}, false},
+		{"Invalid JSON", []byte(`{"keys":`), Set{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSet(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSet() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey_PublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Could not generate RSA key: %s", err.Error())
+	}
+	rsaPKIX, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate EC key: %s", err.Error())
+	}
+	ecPKIX, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	n := b64(rsaKey.N.Bytes())
+	e := b64(big.NewInt(int64(rsaKey.E)).Bytes())
+	x := b64(ecKey.X.Bytes())
+	y := b64(ecKey.Y.Bytes())
+	tests := []struct {
+		name    string
+		k       Key
+		want    publickey.PublicKey
+		wantErr bool
+	}{
+		{"RSA", Key{Kty: "RSA", Kid: "key_id", N: n, E: e}, publickey.New(rsaPKIX, "key_id").WithKeyType("RSA"), false},
+		{"RSA with metadata", Key{Kty: "RSA", Kid: "key_id", Use: "sig", KeyOps: []string{"verify"}, Alg: "RS256", N: n, E: e}, publickey.New(rsaPKIX, "key_id").WithKeyType("RSA").WithAlgorithm("RS256").WithUse("sig").WithKeyOps("verify"), false},
+		{"RSA missing modulus", Key{Kty: "RSA", E: e}, publickey.PublicKey{}, true},
+		{"RSA missing exponent", Key{Kty: "RSA", N: n}, publickey.PublicKey{}, true},
+		{"RSA exponent too large", Key{Kty: "RSA", N: n, E: b64([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x01})}, publickey.PublicKey{}, true},
+		{"EC", Key{Kty: "EC", Kid: "key_id", Crv: "P-256", X: x, Y: y}, publickey.New(ecPKIX, "key_id").WithKeyType("EC"), false},
+		{"EC unknown curve", Key{Kty: "EC", Crv: "P-224", X: x, Y: y}, publickey.PublicKey{}, true},
+		{"EC missing X", Key{Kty: "EC", Crv: "P-256", Y: y}, publickey.PublicKey{}, true},
+		{"EC missing Y", Key{Kty: "EC", Crv: "P-256", X: x}, publickey.PublicKey{}, true},
+		{"EC point not on curve", Key{Kty: "EC", Crv: "P-384", X: x, Y: y}, publickey.PublicKey{}, true},
+		{"Ed25519", Key{Kty: "OKP", Kid: "key_id", Crv: "Ed25519", X: "dGVzdA"}, publickey.New([]byte("test"), "key_id").WithKeyType("OKP"), false},
+		{"OKP unknown curve", Key{Kty: "OKP", Crv: "X25519", X: "dGVzdA"}, publickey.PublicKey{}, true},
+		{"OKP invalid base64", Key{Kty: "OKP", Crv: "Ed448", X: "!"}, publickey.PublicKey{}, true},
+		{"Symmetric", Key{Kty: "oct", Kid: "key_id", K: "dGVzdA"}, publickey.New([]byte("test"), "key_id").WithKeyType("oct"), false},
+		{"Symmetric missing key", Key{Kty: "oct"}, publickey.PublicKey{}, true},
+		{"Unknown type", Key{Kty: "unknown"}, publickey.PublicKey{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.k.PublicKey()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Key.PublicKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Key.PublicKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewECKey(t *testing.T) {
+	for _, c := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		t.Run(c.Params().Name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(c, rand.Reader)
+			if err != nil {
+				t.Fatalf("Could not generate EC key: %s", err.Error())
+			}
+			k, err := NewECKey(&key.PublicKey)
+			if err != nil {
+				t.Fatalf("NewECKey() failed: %s", err.Error())
+			}
+			size := base64.RawURLEncoding.EncodedLen((c.Params().BitSize + 7) / 8)
+			if len(k.X) != size || len(k.Y) != size {
+				t.Errorf("NewECKey() returned coordinates of length %d and %d, want %d", len(k.X), len(k.Y), size)
+			}
+			pkix, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			if pub, err := k.PublicKey(); err != nil || !reflect.DeepEqual(pub.GetPublicKey(), pkix) {
+				t.Errorf("Key.PublicKey() = %v, %v for key returned by NewECKey()", pub, err)
+			}
+		})
+	}
+	if _, err := NewECKey(&ecdsa.PublicKey{Curve: elliptic.P224(), X: big.NewInt(1), Y: big.NewInt(1)}); err == nil {
+		t.Error("NewECKey() should fail for unsupported curve")
+	}
+}
+
+func TestSecp256k1(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("Could not generate secp256k1 key: %s", err.Error())
+	}
+	k, err := NewECKey(priv.PubKey().ToECDSA())
+	if err != nil {
+		t.Fatalf("NewECKey() failed: %s", err.Error())
+	}
+	if k.Crv != "secp256k1" {
+		t.Errorf("NewECKey() set curve %q, want secp256k1", k.Crv)
+	}
+	pub, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Key.PublicKey() failed: %s", err.Error())
+	}
+	var spki subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(pub.GetPublicKey(), &spki); err != nil {
+		t.Fatalf("Key.PublicKey() did not return a PKIX key: %s", err.Error())
+	}
+	var curve asn1.ObjectIdentifier
+	asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve) // nolint:errcheck
+	if !spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) || !curve.Equal(oidCurveSecp256k1) || !bytes.Equal(spki.PublicKey.Bytes, priv.PubKey().SerializeUncompressed()) {
+		t.Errorf("Key.PublicKey() = %+v, want secp256k1 key %x", spki, priv.PubKey().SerializeUncompressed())
+	}
+	k.Y = k.X
+	if _, err := k.PublicKey(); err == nil {
+		t.Error("Key.PublicKey() should fail for point not on curve")
+	}
+	k.X = b64(bytes.Repeat([]byte{0xff}, 33))
+	if _, err := k.PublicKey(); err == nil {
+		t.Error("Key.PublicKey() should fail for coordinates that are too large")
+	}
+}
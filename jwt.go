@@ -2,21 +2,38 @@ package jwt
 
 import (
 	"errors"
+	"sync"
 )
 
 var (
+	registryMu          sync.RWMutex
 	signatureProviders  map[string]SignatureProvider
 	defaultAlgorithm    string
 	validationProviders map[string]ContentValidationProvider
+	signaturePolicy     = AnySignature
+	criticalHandlers    map[string]CriticalHeaderHandler
+
+	encryptionProviders      map[string]EncryptionProvider
+	defaultEncryption        string
+	defaultContentEncryption = "A256GCM"
+	compressContent          bool
+	maxDecompressedSize      = 1 << 20
 )
 
 func init() {
 	signatureProviders = make(map[string]SignatureProvider)
 	validationProviders = make(map[string]ContentValidationProvider)
+	criticalHandlers = make(map[string]CriticalHeaderHandler)
+	encryptionProviders = make(map[string]EncryptionProvider)
 }
 
 // AddSignatureProvider tries to add the signature provider to the list but fails when one with the same name already exists.
 func AddSignatureProvider(name string, provider SignatureProvider) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == AlgorithmNone {
+		return errUnsecured
+	}
 	if _, ok := signatureProviders[name]; ok {
 		return errors.New("algorithm already registered: use SetSignatureProvider to force replacement")
 	}
@@ -25,17 +42,31 @@ func AddSignatureProvider(name string, provider SignatureProvider) error {
 }
 
 // SetSignatureProvider sets the signature provider ignoring previous settings for the same name.
-func SetSignatureProvider(name string, provider SignatureProvider) {
+// It fails for the algorithm none as unsecured JWTs must not be signed or verified by a provider.
+func SetSignatureProvider(name string, provider SignatureProvider) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == AlgorithmNone {
+		return errUnsecured
+	}
 	signatureProviders[name] = provider
+	return nil
 }
 
 // RemoveSignatureProvider removes a signature provider by name
 func RemoveSignatureProvider(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	delete(signatureProviders, name)
 }
 
 // SetSigningAlgorithm sets the default algorithm that will be used with Encode and by the Marshalers for encoding
 func SetSigningAlgorithm(name string) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == AlgorithmNone {
+		return errUnsecured
+	}
 	if _, ok := signatureProviders[name]; !ok {
 		return errors.New("algorithm does not exist")
 	}
@@ -43,8 +74,76 @@ func SetSigningAlgorithm(name string) error {
 	return nil
 }
 
+// AddEncryptionProvider tries to add the encryption provider to the list but fails when one with the same name already exists.
+func AddEncryptionProvider(name string, provider EncryptionProvider) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := encryptionProviders[name]; ok {
+		return errors.New("algorithm already registered: use SetEncryptionProvider to force replacement")
+	}
+	encryptionProviders[name] = provider
+	return nil
+}
+
+// SetEncryptionProvider sets the encryption provider ignoring previous settings for the same name.
+func SetEncryptionProvider(name string, provider EncryptionProvider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	encryptionProviders[name] = provider
+}
+
+// RemoveEncryptionProvider removes an encryption provider by name
+func RemoveEncryptionProvider(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(encryptionProviders, name)
+}
+
+// SetEncryptionAlgorithm sets the default key management algorithm that will be used with EncodeEncrypted
+func SetEncryptionAlgorithm(name string) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := encryptionProviders[name]; !ok {
+		return errors.New("algorithm does not exist")
+	}
+	defaultEncryption = name
+	return nil
+}
+
+// SetContentEncryptionAlgorithm sets the algorithm used by EncodeEncrypted to encrypt the content. It defaults to A256GCM.
+func SetContentEncryptionAlgorithm(enc string) error {
+	if _, ok := contentEncryptions[enc]; !ok {
+		return errors.New("content encryption algorithm is not supported")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	defaultContentEncryption = enc
+	return nil
+}
+
+// SetCompression sets whether the content is compressed using DEFLATE before it is encrypted by EncodeEncrypted and EncodeNested. It is disabled by default.
+func SetCompression(enabled bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	compressContent = enabled
+}
+
+// SetMaxDecompressedSize sets the maximum size in bytes that the content of a compressed token may have after decompression. It defaults to 1 MiB.
+// Decompression is aborted once the limit is exceeded to prevent tokens from exhausting memory.
+func SetMaxDecompressedSize(size int) error {
+	if size <= 0 {
+		return errors.New("size limit must be positive")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	maxDecompressedSize = size
+	return nil
+}
+
 // AddValidationProvider adds a content validation provider
 func AddValidationProvider(name string, provider ContentValidationProvider) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	if _, ok := validationProviders[name]; ok {
 		return errors.New("there is already a content validation provider with this name")
 	}
@@ -54,5 +153,7 @@ func AddValidationProvider(name string, provider ContentValidationProvider) erro
 
 // RemoveValidationProvider removes a content validation provider by name
 func RemoveValidationProvider(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	delete(validationProviders, name)
 }
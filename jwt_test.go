@@ -53,3 +53,32 @@ func TestRemoveSignatureProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestAddEncryptionProvider(t *testing.T) {
+	if err := AddEncryptionProvider("add", testKey); err != nil {
+		t.Errorf("AddEncryptionProvider() failed: %s", err.Error())
+	}
+	if AddEncryptionProvider("add", testKey) == nil {
+		t.Error("AddEncryptionProvider() should fail when provider already exists")
+	}
+	if err := SetEncryptionAlgorithm("add"); err != nil {
+		t.Errorf("SetEncryptionAlgorithm() failed: %s", err.Error())
+	}
+	RemoveEncryptionProvider("add")
+	if SetEncryptionAlgorithm("add") == nil {
+		t.Error("SetEncryptionAlgorithm() should fail for removed provider")
+	}
+	if _, ok := encryptionProviders["add"]; ok {
+		t.Error("RemoveEncryptionProvider() did not remove provider")
+	}
+}
+
+func TestSetMaxDecompressedSize(t *testing.T) {
+	if SetMaxDecompressedSize(0) == nil {
+		t.Error("SetMaxDecompressedSize() should fail for size limit 0")
+	}
+	if err := SetMaxDecompressedSize(1024); err != nil || maxDecompressedSize != 1024 {
+		t.Errorf("SetMaxDecompressedSize() did not set size limit: %v", err)
+	}
+	SetMaxDecompressedSize(1 << 20) // nolint:errcheck
+}
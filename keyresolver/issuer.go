@@ -0,0 +1,38 @@
+package keyresolver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// IssuerResolver selects the resolver to use based on the issuer claim of the token
+type IssuerResolver struct {
+	resolvers map[string]jwt.KeyResolver
+}
+
+// NewIssuerResolver returns an IssuerResolver using the supplied resolvers by issuer
+func NewIssuerResolver(resolvers map[string]jwt.KeyResolver) IssuerResolver {
+	m := make(map[string]jwt.KeyResolver, len(resolvers))
+	for iss, r := range resolvers {
+		m[iss] = r
+	}
+	return IssuerResolver{m}
+}
+
+// Resolve passes the request on to the resolver registered for the issuer of the token
+func (r IssuerResolver) Resolve(h jwt.Header, c []byte) ([]publickey.PublicKey, error) {
+	var iss struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(c, &iss); err != nil {
+		return nil, err
+	}
+	res, ok := r.resolvers[iss.Issuer]
+	if !ok {
+		return nil, fmt.Errorf("no keys available for issuer %q", iss.Issuer)
+	}
+	return res.Resolve(h, c)
+}
@@ -0,0 +1,85 @@
+package keyresolver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/jwk"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// JWKSResolver resolves keys from a JSON web key set retrieved from a URL.
+// The key set is cached and fetched again once the refresh interval has passed.
+type JWKSResolver struct {
+	URL     string
+	Refresh time.Duration
+	Client  *http.Client
+
+	mu      sync.Mutex
+	fetched time.Time
+	cache   StaticResolver
+}
+
+// NewJWKSResolver returns a JWKSResolver for the key set at url which is refreshed after the specified interval
+func NewJWKSResolver(url string, refresh time.Duration) *JWKSResolver {
+	return &JWKSResolver{URL: url, Refresh: refresh, Client: http.DefaultClient}
+}
+
+// Resolve returns the keys from the key set matching the key ID in the header or all keys if the header does not contain a key ID
+func (r *JWKSResolver) Resolve(h jwt.Header, c []byte) ([]publickey.PublicKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fetched.IsZero() || time.Since(r.fetched) > r.Refresh {
+		if err := r.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	return r.cache.Resolve(h, c)
+}
+
+func (r *JWKSResolver) fetch() error {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Get(r.URL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() // nolint:errcheck
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not retrieve key set: %s", res.Status)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	set, err := jwk.ParseSet(body)
+	if err != nil {
+		return errors.New("could not decode key set")
+	}
+	keys := make([]publickey.PublicKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Symmetric keys must never be retrieved from a key set as anyone able to influence the set could choose the secret
+		if k.Kty == "oct" {
+			continue
+		}
+		pub, err := k.PublicKey()
+		if err != nil {
+			// Keys of unsupported types are skipped so one bad entry does not break the whole set
+			continue
+		}
+		keys = append(keys, pub)
+	}
+	r.cache = NewStaticResolver(keys...)
+	r.fetched = time.Now()
+	return nil
+}
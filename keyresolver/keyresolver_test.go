@@ -0,0 +1,122 @@
+package keyresolver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+var (
+	key1 = publickey.New([]byte("key1"), "key_id_1")
+	key2 = publickey.New([]byte("key2"), "key_id_2")
+)
+
+func TestStaticResolver_Resolve(t *testing.T) {
+	r := NewStaticResolver(key1, key2)
+	tests := []struct {
+		name    string
+		h       jwt.Header
+		want    []publickey.PublicKey
+		wantErr bool
+	}{
+		{"Key ID", jwt.Header{Kid: "key_id_2"}, []publickey.PublicKey{key2}, false},
+		{"No key ID", jwt.Header{}, []publickey.PublicKey{key1, key2}, false},
+		{"Unknown key ID", jwt.Header{Kid: "unknown"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(tt.h, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StaticResolver.Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StaticResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssuerResolver_Resolve(t *testing.T) {
+	r := NewIssuerResolver(map[string]jwt.KeyResolver{
+		"issuer1": NewStaticResolver(key1),
+		"issuer2": NewStaticResolver(key2),
+	})
+	tests := []struct {
+		name    string
+		c       []byte
+		want    []publickey.PublicKey
+		wantErr bool
+	}{
+		{"Issuer 1", []byte(`{"iss":"issuer1"}`), []publickey.PublicKey{key1}, false},
+		{"Issuer 2", []byte(`{"iss":"issuer2"}`), []publickey.PublicKey{key2}, false},
+		{"Unknown issuer", []byte(`{"iss":"unknown"}`), nil, true},
+		{"Invalid content", []byte(`{"iss":`), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(jwt.Header{}, tt.c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IssuerResolver.Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IssuerResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWKSResolver_Resolve(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/jwks":
+			w.Write([]byte(`{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"key_id_1","x":"a2V5MQ"},{"kty":"OKP","crv":"Ed25519","kid":"enc","use":"enc","x":"a2V5MQ"},{"kty":"oct","kid":"secret","k":"a2V5MQ"},{"kty":"unknown","kid":"unknown"}]}`)) // nolint:errcheck
+		case "/invalid":
+			w.Write([]byte(`{"keys":`)) // nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := NewJWKSResolver(srv.URL+"/jwks", time.Hour)
+	got, err := r.Resolve(jwt.Header{Kid: "key_id_1"}, nil)
+	if err != nil {
+		t.Fatalf("JWKSResolver.Resolve() failed: %s", err.Error())
+	}
+	if want := []publickey.PublicKey{key1.WithKeyType("OKP")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("JWKSResolver.Resolve() = %v, want %v", got, want)
+	}
+	if _, err := r.Resolve(jwt.Header{Kid: "enc"}, nil); err == nil {
+		t.Error("JWKSResolver.Resolve() should not return keys intended for encryption")
+	}
+	if _, err := r.Resolve(jwt.Header{Kid: "secret"}, nil); err == nil {
+		t.Error("JWKSResolver.Resolve() should not return symmetric keys")
+	}
+	if requests != 1 {
+		t.Errorf("JWKSResolver.Resolve() should cache the key set but it was requested %d times", requests)
+	}
+	r.Refresh = 0
+	time.Sleep(time.Millisecond)
+	if _, err := r.Resolve(jwt.Header{Kid: "key_id_1"}, nil); err != nil || requests != 2 {
+		t.Errorf("JWKSResolver.Resolve() should fetch the key set again after the refresh interval but requested it %d times (error: %v)", requests, err)
+	}
+
+	if _, err := NewJWKSResolver(srv.URL+"/missing", time.Hour).Resolve(jwt.Header{}, nil); err == nil {
+		t.Error("JWKSResolver.Resolve() should fail when the key set cannot be retrieved")
+	}
+	if _, err := NewJWKSResolver(srv.URL+"/invalid", time.Hour).Resolve(jwt.Header{}, nil); err == nil {
+		t.Error("JWKSResolver.Resolve() should fail when the key set is invalid")
+	}
+	if _, err := (&JWKSResolver{URL: "http://[::1]:0/"}).Resolve(jwt.Header{}, nil); err == nil {
+		t.Error("JWKSResolver.Resolve() should fail when the server is unreachable")
+	}
+}
@@ -0,0 +1,35 @@
+package keyresolver
+
+import (
+	"errors"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// StaticResolver resolves keys from a fixed set of public keys
+type StaticResolver struct {
+	keys map[string][]publickey.PublicKey
+	all  []publickey.PublicKey
+}
+
+// NewStaticResolver returns a StaticResolver for the supplied keys
+func NewStaticResolver(keys ...publickey.PublicKey) StaticResolver {
+	m := make(map[string][]publickey.PublicKey)
+	for _, k := range keys {
+		m[k.GetKeyID()] = append(m[k.GetKeyID()], k)
+	}
+	return StaticResolver{m, keys}
+}
+
+// Resolve returns the keys matching the key ID in the header or all keys if the header does not contain a key ID
+func (r StaticResolver) Resolve(h jwt.Header, c []byte) ([]publickey.PublicKey, error) {
+	if h.Kid == "" {
+		return r.all, nil
+	}
+	keys, ok := r.keys[h.Kid]
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+	return keys, nil
+}
@@ -0,0 +1,172 @@
+package keywatch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+)
+
+// Loader parses the watched key files and returns a provider using the keys they contain
+type Loader func() (jwt.KeyedSignatureProvider, error)
+
+// Watcher polls key files and directories and replaces the registered signature provider whenever their content changes.
+// Tokens that are being validated while the provider is replaced are verified using the provider they started with.
+// Replacing the provider discards all state that has been added at runtime, e.g. keys added using jwt.RegisterPublicKey, key resolvers or random sources, so the loader has to set it up again.
+type Watcher struct {
+	name     string
+	paths    []string
+	load     Loader
+	interval time.Duration
+
+	mu      sync.Mutex
+	sum     []byte
+	handler func(error)
+	stop    chan struct{}
+}
+
+// NewWatcher loads the keys using the loader and registers the provider under the supplied name.
+// Once the watcher has been started, the paths are checked for changes every interval. Paths may point to files or directories.
+func NewWatcher(name string, load Loader, interval time.Duration, paths ...string) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, errors.New("polling interval must be positive")
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no paths to watch")
+	}
+	w := &Watcher{name: name, paths: paths, load: load, interval: interval}
+	if _, err := w.Reload(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetErrorHandler sets a function that is called whenever reloading the keys fails while the watcher is running.
+// The previous provider stays registered in that case. The handler may be called from a different goroutine.
+func (w *Watcher) SetErrorHandler(handler func(error)) {
+	w.mu.Lock()
+	w.handler = handler
+	w.mu.Unlock()
+}
+
+// Start starts polling the paths for changes. It is a noop when the watcher has already been started.
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		return
+	}
+	w.stop = make(chan struct{})
+	go w.run(w.stop)
+}
+
+// Stop stops polling the paths for changes
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	w.stop = nil
+}
+
+func (w *Watcher) run(stop chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := w.Reload(); err != nil {
+				w.mu.Lock()
+				handler := w.handler
+				w.mu.Unlock()
+				if handler != nil {
+					handler(err)
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Reload checks the paths for changes and replaces the registered provider when their content differs from the last successful load.
+// It returns whether the provider has been replaced. When the keys cannot be loaded, the previous provider stays registered and loading is retried on the next call.
+// The paths are hashed again after loading, so keys that changed while being loaded are not registered until they are loaded consistently.
+func (w *Watcher) Reload() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sum, err := checksum(w.paths)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(sum, w.sum) {
+		return false, nil
+	}
+	p, err := w.load()
+	if err != nil {
+		return false, err
+	}
+	loaded, err := checksum(w.paths)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(sum, loaded) {
+		return false, errors.New("key files changed while loading keys")
+	}
+	if err := jwt.SetSignatureProvider(w.name, p); err != nil {
+		return false, err
+	}
+	w.sum = sum
+	return true, nil
+}
+
+// checksum hashes the names and contents of all files at the paths.
+// Hidden entries in directories are skipped as Kubernetes uses them to swap mounted secrets atomically while the visible files are symlinks into them.
+func checksum(paths []string) ([]byte, error) {
+	h := sha256.New()
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		files := []string{p}
+		if info.IsDir() {
+			entries, err := ioutil.ReadDir(p)
+			if err != nil {
+				return nil, err
+			}
+			files = files[:0]
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".") {
+					continue
+				}
+				f := filepath.Join(p, e.Name())
+				if info, err := os.Stat(f); err != nil || info.IsDir() {
+					continue
+				}
+				files = append(files, f)
+			}
+		}
+		for _, f := range files {
+			data, err := ioutil.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			for _, b := range [][]byte{[]byte(f), data} {
+				binary.Write(h, binary.BigEndian, uint64(len(b))) // nolint:errcheck
+				h.Write(b)                                        // nolint:errcheck
+			}
+		}
+	}
+	return h.Sum(nil), nil
+}
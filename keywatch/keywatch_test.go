@@ -0,0 +1,239 @@
+package keywatch
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/alg-hs"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func writeKey(t *testing.T, dir string, key string) {
+	s, err := hs.NewSettings(bytes.Repeat([]byte(key), 8), "key_id")
+	if err != nil {
+		t.Fatalf("Could not create settings: %s", err.Error())
+	}
+	enc, err := s.ExportPEM()
+	if err != nil {
+		t.Fatalf("Could not export key: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "key.pem"), enc, 0600); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+}
+
+func loader(dir string) Loader {
+	return func() (jwt.KeyedSignatureProvider, error) {
+		p, err := hs.LoadProviderFromDir(dir, hs.HS256)
+		return &p, err
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		t.Fatalf("Could not create directory: %s", err.Error())
+	}
+	return dir
+}
+
+func TestNewWatcher(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+	writeKey(t, dir, "key1")
+	if _, err := NewWatcher("HS256", loader(dir), 0, dir); err == nil {
+		t.Error("NewWatcher() should fail for zero polling interval")
+	}
+	if _, err := NewWatcher("HS256", loader(dir), time.Minute); err == nil {
+		t.Error("NewWatcher() should fail without paths")
+	}
+	if _, err := NewWatcher("HS256", loader(dir), time.Minute, filepath.Join(dir, "missing")); err == nil {
+		t.Error("NewWatcher() should fail for missing paths")
+	}
+	if _, err := NewWatcher("HS256", func() (jwt.KeyedSignatureProvider, error) { return nil, errors.New("could not load keys") }, time.Minute, dir); err == nil {
+		t.Error("NewWatcher() should fail when the keys cannot be loaded")
+	}
+	if _, err := NewWatcher("HS256", loader(dir), time.Minute, dir, filepath.Join(dir, "key.pem")); err != nil {
+		t.Errorf("NewWatcher() failed: %s", err.Error())
+	}
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+	writeKey(t, dir, "key1")
+	w, err := NewWatcher("HS256", loader(dir), time.Minute, dir)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %s", err.Error())
+	}
+	jwt.SetSigningAlgorithm("HS256") // nolint:errcheck
+	token, err := jwt.New([]byte(`{"test": 1}`)).Encode()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+
+	if ok, err := w.Reload(); ok || err != nil {
+		t.Errorf("Watcher.Reload() = %v, %v but keys did not change", ok, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("ignored"), 0600); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0700); err != nil {
+		t.Fatalf("Could not create directory: %s", err.Error())
+	}
+	if ok, err := w.Reload(); ok || err != nil {
+		t.Errorf("Watcher.Reload() = %v, %v but only hidden files and directories were added", ok, err)
+	}
+
+	writeKey(t, dir, "key2")
+	if ok, err := w.Reload(); !ok || err != nil {
+		t.Errorf("Watcher.Reload() = %v, %v but keys changed", ok, err)
+	}
+	if dec, err := jwt.Decode(token); err != nil || dec.Valid() {
+		t.Errorf("Token signed with replaced key should be invalid (error: %v)", err)
+	}
+	token, err = jwt.New([]byte(`{"test": 1}`)).Encode()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	if dec, err := jwt.Decode(token); err != nil || !dec.Valid() {
+		t.Errorf("Token signed with new key should be valid (error: %v, validation error: %v)", err, dec.ValidationError())
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "key.pem"), []byte("partially written"), 0600); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+	if ok, err := w.Reload(); ok || err == nil {
+		t.Errorf("Watcher.Reload() = %v, %v but keys are invalid", ok, err)
+	}
+	if dec, err := jwt.Decode(token); err != nil || !dec.Valid() {
+		t.Errorf("Previous provider should stay registered when keys are invalid (error: %v, validation error: %v)", err, dec.ValidationError())
+	}
+	if err := os.Chmod(filepath.Join(dir, "key.pem"), 0); err == nil && os.Getuid() != 0 {
+		if _, err := w.Reload(); err == nil {
+			t.Error("Watcher.Reload() should fail when a file cannot be read")
+		}
+	}
+}
+
+// hs256Token creates a compact HS256 token signed using the key with the key ID
+func hs256Token(key []byte, keyID string) []byte {
+	input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"`+keyID+`","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"test": 1}`))
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(input)) // nolint:errcheck
+	return []byte(input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+func TestWatcher_ReloadDiscardsRuntimeKeys(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+	writeKey(t, dir, "key1")
+	w, err := NewWatcher("HS256", loader(dir), time.Minute, dir)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %s", err.Error())
+	}
+	key := bytes.Repeat([]byte("runtime"), 8)
+	if _, err := jwt.RegisterPublicKey(publickey.New(key, "runtime").WithKeyType("oct"), "HS256"); err != nil {
+		t.Fatalf("RegisterPublicKey() failed: %s", err.Error())
+	}
+	token := hs256Token(key, "runtime")
+	if dec, err := jwt.Decode(token); err != nil || !dec.Valid() {
+		t.Fatalf("Token signed with registered key should be valid (error: %v, validation error: %v)", err, dec.ValidationError())
+	}
+	writeKey(t, dir, "key2")
+	if ok, err := w.Reload(); !ok || err != nil {
+		t.Fatalf("Watcher.Reload() = %v, %v but keys changed", ok, err)
+	}
+	if dec, err := jwt.Decode(token); err != nil || dec.Valid() {
+		t.Errorf("Key registered at runtime should be discarded on reload (error: %v)", err)
+	}
+}
+
+func TestWatcher_ReloadChangedWhileLoading(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+	writeKey(t, dir, "key1")
+	change := false
+	load := func() (jwt.KeyedSignatureProvider, error) {
+		p, err := loader(dir)()
+		if change {
+			change = false
+			writeKey(t, dir, "key3")
+		}
+		return p, err
+	}
+	w, err := NewWatcher("HS256", load, time.Minute, dir)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %s", err.Error())
+	}
+
+	writeKey(t, dir, "key2")
+	change = true
+	if ok, err := w.Reload(); ok || err == nil {
+		t.Errorf("Watcher.Reload() = %v, %v but keys changed while loading", ok, err)
+	}
+	if ok, err := w.Reload(); !ok || err != nil {
+		t.Errorf("Watcher.Reload() = %v, %v but keys changed", ok, err)
+	}
+	if dec, err := jwt.Decode(hs256Token(bytes.Repeat([]byte("key3"), 8), "key_id")); err != nil || !dec.Valid() {
+		t.Errorf("Key present after loading should be used (error: %v, validation error: %v)", err, dec.ValidationError())
+	}
+	if dec, err := jwt.Decode(hs256Token(bytes.Repeat([]byte("key2"), 8), "key_id")); err != nil || dec.Valid() {
+		t.Errorf("Key replaced while loading should not be used (error: %v)", err)
+	}
+}
+
+func TestWatcher_Start(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+	writeKey(t, dir, "key1")
+	w, err := NewWatcher("HS256", loader(dir), 10*time.Millisecond, dir)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %s", err.Error())
+	}
+	jwt.SetSigningAlgorithm("HS256") // nolint:errcheck
+	token, err := jwt.New([]byte(`{"test": 1}`)).Encode()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	errs := make(chan error, 16)
+	w.SetErrorHandler(func(err error) { errs <- err })
+	w.Start()
+	w.Start()
+	defer w.Stop()
+
+	if err := os.Remove(filepath.Join(dir, "key.pem")); err != nil {
+		t.Fatalf("Could not remove file: %s", err.Error())
+	}
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher did not report error for missing private key")
+	}
+	writeKey(t, dir, "key2")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dec, err := jwt.Decode(token)
+		if err != nil {
+			t.Fatalf("Could not decode JWT: %s", err.Error())
+		}
+		if !dec.Valid() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Watcher did not reload keys after change")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	w.Stop()
+	w.Stop()
+}
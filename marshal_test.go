@@ -6,7 +6,9 @@ import (
 )
 
 func TestJWT_MarshalText(t *testing.T) {
-	SetSignatureProvider("test", TestAlgorithm("test"))
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 	tests := []struct {
 		name    string
@@ -31,7 +33,9 @@ func TestJWT_MarshalText(t *testing.T) {
 }
 
 func TestJWT_UnmarshalText(t *testing.T) {
-	SetSignatureProvider("test", TestAlgorithm("test"))
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 	type args struct {
 		in []byte
@@ -59,7 +63,9 @@ func TestJWT_UnmarshalText(t *testing.T) {
 }
 
 func TestJWT_MarshalBinary(t *testing.T) {
-	SetSignatureProvider("test", TestAlgorithm("test"))
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 	tests := []struct {
 		name    string
@@ -84,7 +90,9 @@ func TestJWT_MarshalBinary(t *testing.T) {
 }
 
 func TestJWT_UnmarshalBinary(t *testing.T) {
-	SetSignatureProvider("test", TestAlgorithm("test"))
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 	type args struct {
 		in []byte
@@ -1,14 +1,25 @@
 package publickey
 
+import (
+	"errors"
+	"time"
+)
+
 // PublicKey represents a public key
 type PublicKey struct {
-	key []byte
-	kid string
+	key       []byte
+	kid       string
+	alg       string
+	kty       string
+	use       string
+	ops       []string
+	notBefore time.Time
+	expires   time.Time
 }
 
 // New returns a new PublicKey with the arguments as values
 func New(key []byte, id string) PublicKey {
-	return PublicKey{key, id}
+	return PublicKey{key: key, kid: id}
 }
 
 // GetPublicKey returns the key as a byte slice
@@ -20,3 +31,132 @@ func (s PublicKey) GetPublicKey() []byte {
 func (s PublicKey) GetKeyID() string {
 	return s.kid
 }
+
+// WithAlgorithm returns a copy of the key that may only be used with the algorithm, e.g. "RS256"
+func (s PublicKey) WithAlgorithm(alg string) PublicKey {
+	s.alg = alg
+	return s
+}
+
+// GetAlgorithm returns the algorithm the key is intended for or an empty string if it is not restricted
+func (s PublicKey) GetAlgorithm() string {
+	return s.alg
+}
+
+// WithKeyType returns a copy of the key with the key type as defined in RFC 7518 section 6.1, e.g. "RSA", "EC", "OKP" or "oct"
+func (s PublicKey) WithKeyType(kty string) PublicKey {
+	s.kty = kty
+	return s
+}
+
+// GetKeyType returns the key type or an empty string if it is unknown
+func (s PublicKey) GetKeyType() string {
+	return s.kty
+}
+
+// WithUse returns a copy of the key that may only be used for the purpose, either "sig" for signatures or "enc" for encryption
+func (s PublicKey) WithUse(use string) PublicKey {
+	s.use = use
+	return s
+}
+
+// GetUse returns the intended use of the key or an empty string if it is not restricted
+func (s PublicKey) GetUse() string {
+	return s.use
+}
+
+// WithKeyOps returns a copy of the key that may only be used for the operations as defined in RFC 7517 section 4.3, e.g. "verify" or "wrapKey"
+func (s PublicKey) WithKeyOps(ops ...string) PublicKey {
+	s.ops = append([]string(nil), ops...)
+	return s
+}
+
+// GetKeyOps returns the operations the key may be used for or nil if they are not restricted
+func (s PublicKey) GetKeyOps() []string {
+	return s.ops
+}
+
+// WithValidity returns a copy of the key that may only be used between notBefore and expires. Zero times are not checked.
+func (s PublicKey) WithValidity(notBefore, expires time.Time) PublicKey {
+	s.notBefore = notBefore
+	s.expires = expires
+	return s
+}
+
+// GetNotBefore returns the time before which the key must not be used or a zero time if it is not restricted
+func (s PublicKey) GetNotBefore() time.Time {
+	return s.notBefore
+}
+
+// GetExpires returns the time after which the key must not be used or a zero time if it does not expire
+func (s PublicKey) GetExpires() time.Time {
+	return s.expires
+}
+
+// GetValidity returns the period in which the key may be used
+func (s PublicKey) GetValidity() Validity {
+	return Validity{NotBefore: s.notBefore, Expires: s.expires}
+}
+
+// CheckUsage returns an error if the metadata of the key does not permit using it with the algorithm and key type for the use and operation or if it is not valid at the current time.
+// Metadata that is not set on the key as well as empty arguments are not checked.
+func (s PublicKey) CheckUsage(alg, kty, use, op string) error {
+	if err := s.CheckMetadata(alg, kty, use, op); err != nil {
+		return err
+	}
+	return s.GetValidity().Check(time.Now())
+}
+
+// CheckMetadata works like CheckUsage but does not check the validity period of the key.
+// Providers storing keys use it together with GetValidity to check the validity period each time the key is used.
+func (s PublicKey) CheckMetadata(alg, kty, use, op string) error {
+	if s.alg != "" && alg != "" && s.alg != alg {
+		return errors.New("key is intended for algorithm " + s.alg + " instead of " + alg)
+	}
+	if s.kty != "" && kty != "" && s.kty != kty {
+		return errors.New("key has type " + s.kty + " instead of " + kty)
+	}
+	if s.use != "" && use != "" && s.use != use {
+		return errors.New("key is intended for use " + s.use + " instead of " + use)
+	}
+	if len(s.ops) > 0 && op != "" && !contains(s.ops, op) {
+		return errors.New("key does not permit operation " + op)
+	}
+	return nil
+}
+
+// Validity is the period in which a key may be used. Zero times are not checked.
+type Validity struct {
+	NotBefore time.Time
+	Expires   time.Time
+}
+
+// IsZero reports whether the validity period is unrestricted
+func (v Validity) IsZero() bool {
+	return v.NotBefore.IsZero() && v.Expires.IsZero()
+}
+
+// Expired reports whether the validity period ended before t
+func (v Validity) Expired(t time.Time) bool {
+	return !v.Expires.IsZero() && t.After(v.Expires)
+}
+
+// Check returns an error if t is not within the validity period
+func (v Validity) Check(t time.Time) error {
+	if !v.NotBefore.IsZero() && t.Before(v.NotBefore) {
+		return errors.New("key is not valid yet")
+	}
+	if v.Expired(t) {
+		return errors.New("key expired")
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
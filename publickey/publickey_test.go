@@ -3,6 +3,7 @@ package publickey
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPublicKey_GetPublicKey(t *testing.T) {
@@ -59,3 +60,92 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestPublicKey_Metadata(t *testing.T) {
+	nbf := time.Now().Add(-time.Hour)
+	exp := time.Now().Add(time.Hour)
+	ops := []string{"verify"}
+	k := New([]byte("test"), "key_id").WithAlgorithm("RS256").WithKeyType("RSA").WithUse("sig").WithKeyOps(ops...).WithValidity(nbf, exp)
+	ops[0] = "modified"
+	if k.GetAlgorithm() != "RS256" || k.GetKeyType() != "RSA" || k.GetUse() != "sig" || !reflect.DeepEqual(k.GetKeyOps(), []string{"verify"}) || !k.GetNotBefore().Equal(nbf) || !k.GetExpires().Equal(exp) {
+		t.Errorf("PublicKey metadata does not match the values set: %v", k)
+	}
+	if !reflect.DeepEqual(k.GetPublicKey(), []byte("test")) || k.GetKeyID() != "key_id" {
+		t.Errorf("Setting metadata changed the key: %v", k)
+	}
+	if New(nil, "").WithKeyOps().GetKeyOps() != nil {
+		t.Error("PublicKey.WithKeyOps() without operations should not restrict the operations")
+	}
+}
+
+func TestPublicKey_CheckUsage(t *testing.T) {
+	key := New([]byte("test"), "key_id")
+	type args struct {
+		alg, kty, use, op string
+	}
+	tests := []struct {
+		name    string
+		key     PublicKey
+		args    args
+		wantErr bool
+	}{
+		{"No metadata", key, args{"RS256", "RSA", "sig", "verify"}, false},
+		{"Matching metadata", key.WithAlgorithm("RS256").WithKeyType("RSA").WithUse("sig").WithKeyOps("sign", "verify"), args{"RS256", "RSA", "sig", "verify"}, false},
+		{"Unchecked arguments", key.WithAlgorithm("RS256").WithKeyType("RSA").WithUse("sig").WithKeyOps("sign"), args{"", "", "", ""}, false},
+		{"Other algorithm", key.WithAlgorithm("RS256"), args{"PS256", "RSA", "sig", "verify"}, true},
+		{"Other key type", key.WithKeyType("EC"), args{"RS256", "RSA", "sig", "verify"}, true},
+		{"Other use", key.WithUse("enc"), args{"RS256", "RSA", "sig", "verify"}, true},
+		{"Operation not permitted", key.WithKeyOps("sign"), args{"RS256", "RSA", "sig", "verify"}, true},
+		{"Not valid yet", key.WithValidity(time.Now().Add(time.Hour), time.Time{}), args{"RS256", "RSA", "sig", "verify"}, true},
+		{"Expired", key.WithValidity(time.Time{}, time.Now().Add(-time.Hour)), args{"RS256", "RSA", "sig", "verify"}, true},
+		{"Valid", key.WithValidity(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)), args{"RS256", "RSA", "sig", "verify"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.CheckUsage(tt.args.alg, tt.args.kty, tt.args.use, tt.args.op); (err != nil) != tt.wantErr {
+				t.Errorf("PublicKey.CheckUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublicKey_CheckMetadata(t *testing.T) {
+	key := New([]byte("test"), "key_id")
+	tests := []struct {
+		name    string
+		key     PublicKey
+		wantErr bool
+	}{
+		{"Not valid yet", key.WithValidity(time.Now().Add(time.Hour), time.Time{}), false},
+		{"Expired", key.WithValidity(time.Time{}, time.Now().Add(-time.Hour)), false},
+		{"Other algorithm", key.WithAlgorithm("PS256"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.CheckMetadata("RS256", "RSA", "sig", "verify"); (err != nil) != tt.wantErr {
+				t.Errorf("PublicKey.CheckMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidity_Check(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		validity Validity
+		wantErr  bool
+	}{
+		{"Unrestricted", Validity{}, false},
+		{"Valid", Validity{now.Add(-time.Hour), now.Add(time.Hour)}, false},
+		{"Not valid yet", Validity{NotBefore: now.Add(time.Hour)}, true},
+		{"Expired", Validity{Expires: now.Add(-time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.validity.Check(now); (err != nil) != tt.wantErr {
+				t.Errorf("Validity.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package jwt
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"sort"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+var (
+	oidPublicKeyRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidPublicKeyECDSA   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeyEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+	oidPublicKeyEd448   = asn1.ObjectIdentifier{1, 3, 101, 113}
+
+	oidCurveP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidCurveP384      = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidCurveP521      = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+	oidCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// subjectPublicKeyInfo is the PKIX structure containing a public key as defined in RFC 5280 section 4.1
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// RegisterPublicKey adds the key for verification to every registered signature provider that can use it and returns the names of these algorithms.
+// Only algorithms listed in allowed are considered, so the key has to be explicitly allowed for e.g. both RS256 and PS256 to be used with both.
+// PKIX-encoded RSA, ECDSA and EdDSA keys are detected automatically. Raw keys, e.g. those converted from JSON web keys, have to carry the key type OKP or oct as metadata.
+// If the key specifies an algorithm it is only added for that algorithm. Keys whose metadata does not permit verifying signatures are rejected.
+// If the key cannot be added to one of the providers, it is removed from the others again so it is either added to all of them or to none.
+// Providers have to be registered as pointers, e.g. jwt.SetSignatureProvider("RS256", &provider), to be able to add keys.
+func RegisterPublicKey(key publickey.PublicKey, allowed ...string) ([]string, error) {
+	if len(allowed) == 0 {
+		return nil, errors.New("no algorithms allowed")
+	}
+	kty, algorithms, key, err := keyAlgorithms(key)
+	if err != nil {
+		return nil, err
+	}
+	if key.GetKeyType() != "" && key.GetKeyType() != kty {
+		return nil, errors.New("key type " + key.GetKeyType() + " does not match the key")
+	}
+	permitted := make(map[string]bool, len(allowed))
+	for _, alg := range allowed {
+		permitted[alg] = true
+	}
+	providers := make(map[string]KeyedSignatureProvider)
+	registryMu.RLock()
+	for _, alg := range algorithms {
+		if !permitted[alg] || key.CheckUsage(alg, kty, "sig", "verify") != nil {
+			continue
+		}
+		if p, ok := signatureProviders[alg].(KeyedSignatureProvider); ok {
+			providers[alg] = p
+		}
+	}
+	registryMu.RUnlock()
+	if len(providers) == 0 {
+		return nil, errors.New("no compatible signature provider registered for the key")
+	}
+	names := make([]string, 0, len(providers))
+	for alg := range providers {
+		names = append(names, alg)
+	}
+	sort.Strings(names)
+	for i, alg := range names {
+		if err := providers[alg].AddPublicKey(key); err != nil {
+			for _, added := range names[:i] {
+				providers[added].RemovePublicKey(key.GetKeyID())
+			}
+			return nil, errors.New("could not add key for " + alg + ": " + err.Error())
+		}
+	}
+	return names, nil
+}
+
+// keyAlgorithms returns the key type and the signature algorithms the key can be used with.
+// EdDSA keys encoded as PKIX are returned as raw keys as this is the format the EdDSA provider uses.
+func keyAlgorithms(key publickey.PublicKey) (string, []string, publickey.PublicKey, error) {
+	var spki subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(key.GetPublicKey(), &spki); err != nil || len(rest) != 0 {
+		switch key.GetKeyType() {
+		case "OKP":
+			return "OKP", []string{"EdDSA"}, key, nil
+		case "oct":
+			return "oct", []string{"HS256", "HS384", "HS512"}, key, nil
+		}
+		return "", nil, key, errors.New("key is neither encoded as PKIX nor marked as OKP or oct key")
+	}
+	alg := spki.Algorithm.Algorithm
+	switch {
+	case alg.Equal(oidPublicKeyRSA):
+		return "RSA", []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, key, nil
+	case alg.Equal(oidPublicKeyECDSA):
+		var curve asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil {
+			return "", nil, key, errors.New("could not decode curve of EC key")
+		}
+		switch {
+		case curve.Equal(oidCurveP256):
+			return "EC", []string{"ES256"}, key, nil
+		case curve.Equal(oidCurveP384):
+			return "EC", []string{"ES384"}, key, nil
+		case curve.Equal(oidCurveP521):
+			return "EC", []string{"ES512"}, key, nil
+		case curve.Equal(oidCurveSecp256k1):
+			return "EC", []string{"ES256K"}, key, nil
+		}
+		return "", nil, key, errors.New("unsupported curve")
+	case alg.Equal(oidPublicKeyEd25519), alg.Equal(oidPublicKeyEd448):
+		raw := publickey.New(spki.PublicKey.Bytes, key.GetKeyID()).
+			WithAlgorithm(key.GetAlgorithm()).
+			WithKeyType(key.GetKeyType()).
+			WithUse(key.GetUse()).
+			WithKeyOps(key.GetKeyOps()...).
+			WithValidity(key.GetNotBefore(), key.GetExpires())
+		return "OKP", []string{"EdDSA"}, raw, nil
+	}
+	return "", nil, key, errors.New("unsupported key algorithm")
+}
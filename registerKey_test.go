@@ -0,0 +1,151 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// keyedTestAlgorithm is a signature provider for testing that records the keys added to it
+type keyedTestAlgorithm struct {
+	TestAlgorithm
+	keys []publickey.PublicKey
+	err  error
+}
+
+func (alg *keyedTestAlgorithm) AddPublicKey(key publickey.PublicKey) error {
+	if alg.err != nil {
+		return alg.err
+	}
+	alg.keys = append(alg.keys, key)
+	return nil
+}
+
+func (alg *keyedTestAlgorithm) RemovePublicKey(keyid string) {
+	for i, key := range alg.keys {
+		if key.GetKeyID() == keyid {
+			alg.keys = append(alg.keys[:i], alg.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func (alg *keyedTestAlgorithm) CurrentKey() publickey.PublicKey {
+	return publickey.PublicKey{}
+}
+
+func TestRegisterPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Could not generate RSA key: %s", err.Error())
+	}
+	rsaPKIX, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate EC key: %s", err.Error())
+	}
+	ecPKIX, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	secp256k1PKIX, _ := asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: mustMarshal(oidCurveSecp256k1)}},
+		PublicKey: asn1.BitString{Bytes: []byte{0x04}, BitLength: 8},
+	})
+	ed25519Raw := make([]byte, 32)
+	ed25519PKIX, _ := asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyEd25519},
+		PublicKey: asn1.BitString{Bytes: ed25519Raw, BitLength: 256},
+	})
+
+	names := []string{"RS256", "RS512", "PS256", "ES384", "ES256K", "EdDSA", "HS256"}
+	providers := make(map[string]*keyedTestAlgorithm)
+	for _, name := range names {
+		providers[name] = &keyedTestAlgorithm{TestAlgorithm: TestAlgorithm(name)}
+		if err := SetSignatureProvider(name, providers[name]); err != nil {
+			t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+		}
+	}
+	if err := SetSignatureProvider("ES256", TestAlgorithm("ES256")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	defer func() {
+		for _, name := range append(names, "ES256") {
+			RemoveSignatureProvider(name)
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		key     publickey.PublicKey
+		allowed []string
+		want    []string
+		wantErr bool
+	}{
+		{"RSA", publickey.New(rsaPKIX, "rsa"), []string{"RS256", "RS384", "RS512", "PS256"}, []string{"PS256", "RS256", "RS512"}, false},
+		{"RSA only allowed algorithms", publickey.New(rsaPKIX, "rsa"), []string{"RS256", "HS256"}, []string{"RS256"}, false},
+		{"RSA with algorithm", publickey.New(rsaPKIX, "rsa").WithAlgorithm("PS256"), []string{"RS256", "PS256"}, []string{"PS256"}, false},
+		{"RSA for encryption", publickey.New(rsaPKIX, "rsa").WithUse("enc"), []string{"RS256", "PS256"}, nil, true},
+		{"RSA with wrong key type", publickey.New(rsaPKIX, "rsa").WithKeyType("EC"), []string{"RS256"}, nil, true},
+		{"EC", publickey.New(ecPKIX, "ec"), []string{"ES256", "ES384", "ES512"}, []string{"ES384"}, false},
+		{"secp256k1", publickey.New(secp256k1PKIX, "secp256k1"), []string{"ES256", "ES256K"}, []string{"ES256K"}, false},
+		{"Ed25519 PKIX", publickey.New(ed25519PKIX, "ed25519"), []string{"EdDSA", "HS256"}, []string{"EdDSA"}, false},
+		{"Ed25519 raw", publickey.New(ed25519Raw, "ed25519").WithKeyType("OKP"), []string{"EdDSA", "HS256"}, []string{"EdDSA"}, false},
+		{"Symmetric", publickey.New([]byte("secret"), "oct").WithKeyType("oct"), []string{"EdDSA", "HS256", "HS384"}, []string{"HS256"}, false},
+		{"Raw key without type", publickey.New(ed25519Raw, "raw"), []string{"EdDSA", "HS256"}, nil, true},
+		{"No algorithms allowed", publickey.New(rsaPKIX, "rsa"), nil, nil, true},
+		{"Provider without key management", publickey.New(mustMarshalP256(t), "p256"), []string{"ES256"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RegisterPublicKey(tt.key, tt.allowed...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RegisterPublicKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if k := providers["EdDSA"].keys[0]; !reflect.DeepEqual(k.GetPublicKey(), ed25519Raw) || k.GetKeyID() != "ed25519" {
+		t.Errorf("RegisterPublicKey() did not convert the PKIX-encoded Ed25519 key to a raw key: %v", k)
+	}
+	if len(providers["RS256"].keys) != 2 || len(providers["PS256"].keys) != 2 || len(providers["HS256"].keys) != 1 {
+		t.Error("RegisterPublicKey() did not add the keys to the providers")
+	}
+
+	providers["RS512"].err = errors.New("failed")
+	if got, err := RegisterPublicKey(publickey.New(rsaPKIX, "rsa2"), "RS256", "RS512"); err == nil || got != nil {
+		t.Errorf("RegisterPublicKey() = %v, %v but should fail when the key cannot be added to a provider", got, err)
+	}
+	for _, k := range providers["RS256"].keys {
+		if k.GetKeyID() == "rsa2" {
+			t.Error("RegisterPublicKey() did not remove the key from the providers it was added to before failing")
+		}
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func mustMarshalP256(t *testing.T) []byte {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate EC key: %s", err.Error())
+	}
+	b, _ := x509.MarshalPKIXPublicKey(&k.PublicKey)
+	return b
+}
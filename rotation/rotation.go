@@ -0,0 +1,177 @@
+package rotation
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+// EventType indicates how the set of verification keys changed
+type EventType int
+
+const (
+	// KeyRotated is emitted when a new signing key has been generated
+	KeyRotated EventType = 1
+
+	// KeyRetired is emitted when a previous key has been removed after its grace period
+	KeyRetired EventType = 2
+)
+
+// Event is emitted whenever the set of keys valid for verification changes
+type Event struct {
+	Type EventType
+	Key  publickey.PublicKey   // Key that has been added or retired
+	Keys []publickey.PublicKey // All keys currently valid for verification
+}
+
+// Manager rotates the signing key of a registered signature provider.
+// Previous public keys are kept for verification until their grace period has passed.
+type Manager struct {
+	name     string
+	generate func() (jwt.KeyedSignatureProvider, error)
+	interval time.Duration
+	grace    time.Duration
+
+	mu       sync.Mutex
+	current  jwt.KeyedSignatureProvider
+	previous []publickey.PublicKey
+	handler  func(Event)
+	stop     chan struct{}
+}
+
+// NewManager generates the first provider and registers it under the supplied name.
+// A new provider is generated every interval once the manager has been started while previous keys are retired after the grace period.
+func NewManager(name string, generate func() (jwt.KeyedSignatureProvider, error), interval, grace time.Duration) (*Manager, error) {
+	if interval <= 0 {
+		return nil, errors.New("rotation interval must be positive")
+	}
+	if grace < 0 {
+		return nil, errors.New("grace period must not be negative")
+	}
+	p, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	if err := jwt.SetSignatureProvider(name, p); err != nil {
+		return nil, err
+	}
+	return &Manager{name: name, generate: generate, interval: interval, grace: grace, current: p}, nil
+}
+
+// SetEventHandler sets a function that is called whenever the set of verification keys changes.
+// It can be used to publish the updated key set and may be called from a different goroutine.
+func (m *Manager) SetEventHandler(handler func(Event)) {
+	m.mu.Lock()
+	m.handler = handler
+	m.mu.Unlock()
+}
+
+// Start starts rotating the key every interval. It is a noop when the manager has already been started.
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	go m.run(m.stop)
+}
+
+// Stop stops the automatic rotation. Keys that have already been rotated will still be retired after their grace period.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	m.stop = nil
+}
+
+func (m *Manager) run(stop chan struct{}) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// A failed rotation keeps the current key in use until the next attempt
+			m.Rotate() // nolint:errcheck
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Rotate generates a new provider, adds all keys that are still valid for verification to it and registers it in place of the current one.
+func (m *Manager) Rotate() error {
+	e, err := m.rotate()
+	if err != nil {
+		return err
+	}
+	m.notify(e)
+	return nil
+}
+
+func (m *Manager) rotate() (Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.generate()
+	if err != nil {
+		return Event{}, err
+	}
+	old := m.current.CurrentKey()
+	for _, k := range append([]publickey.PublicKey{old}, m.previous...) {
+		if err := p.AddPublicKey(k); err != nil {
+			return Event{}, err
+		}
+	}
+	if err := jwt.SetSignatureProvider(m.name, p); err != nil {
+		return Event{}, err
+	}
+	m.previous = append(m.previous, old)
+	m.current = p
+	kid := old.GetKeyID()
+	time.AfterFunc(m.grace, func() { m.retire(kid) })
+	return Event{KeyRotated, p.CurrentKey(), m.keys()}, nil
+}
+
+// Keys returns all keys currently valid for verification starting with the key used for signing
+func (m *Manager) Keys() []publickey.PublicKey {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.keys()
+}
+
+func (m *Manager) retire(kid string) {
+	m.mu.Lock()
+	var e *Event
+	for i, k := range m.previous {
+		if k.GetKeyID() == kid {
+			m.previous = append(m.previous[:i:i], m.previous[i+1:]...)
+			m.current.RemovePublicKey(kid)
+			e = &Event{KeyRetired, k, m.keys()}
+			break
+		}
+	}
+	m.mu.Unlock()
+	if e != nil {
+		m.notify(*e)
+	}
+}
+
+// notify passes the event to the handler without holding the lock so the handler may call methods of the manager
+func (m *Manager) notify(e Event) {
+	m.mu.Lock()
+	handler := m.handler
+	m.mu.Unlock()
+	if handler != nil {
+		handler(e)
+	}
+}
+
+func (m *Manager) keys() []publickey.PublicKey {
+	return append([]publickey.PublicKey{m.current.CurrentKey()}, m.previous...)
+}
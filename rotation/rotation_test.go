@@ -0,0 +1,111 @@
+package rotation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fossoreslp/go-jwt"
+	"github.com/fossoreslp/go-jwt/alg-es"
+)
+
+func generate() (jwt.KeyedSignatureProvider, error) {
+	p, err := es.NewProvider(es.ES256)
+	return &p, err
+}
+
+func failingGenerate() (jwt.KeyedSignatureProvider, error) {
+	return nil, errors.New("could not generate provider")
+}
+
+func TestNewManager(t *testing.T) {
+	if _, err := NewManager("ES256", generate, 0, time.Minute); err == nil {
+		t.Error("NewManager() should fail for zero rotation interval")
+	}
+	if _, err := NewManager("ES256", generate, time.Minute, -time.Minute); err == nil {
+		t.Error("NewManager() should fail for negative grace period")
+	}
+	if _, err := NewManager("ES256", failingGenerate, time.Minute, time.Minute); err == nil {
+		t.Error("NewManager() should fail when the provider cannot be generated")
+	}
+	if _, err := NewManager(jwt.AlgorithmNone, generate, time.Minute, time.Minute); err == nil {
+		t.Error("NewManager() should fail for algorithm none")
+	}
+	m, err := NewManager("ES256", generate, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %s", err.Error())
+	}
+	if l := len(m.Keys()); l != 1 {
+		t.Errorf("Manager.Keys() should return one key after initialization but returned %d", l)
+	}
+}
+
+func TestManager_Rotate(t *testing.T) {
+	m, err := NewManager("ES256", generate, time.Hour, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %s", err.Error())
+	}
+	events := make(chan Event, 4)
+	m.SetEventHandler(func(e Event) { events <- e })
+	jwt.SetSigningAlgorithm("ES256") // nolint:errcheck
+	token, err := jwt.New([]byte(`{"test": 1}`)).Encode()
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	first := m.Keys()[0]
+
+	if err := m.Rotate(); err != nil {
+		t.Fatalf("Manager.Rotate() failed: %s", err.Error())
+	}
+	e := <-events
+	if e.Type != KeyRotated || len(e.Keys) != 2 || e.Keys[1].GetKeyID() != first.GetKeyID() || e.Key.GetKeyID() != e.Keys[0].GetKeyID() {
+		t.Errorf("Manager.Rotate() emitted unexpected event %+v", e)
+	}
+	if dec, err := jwt.Decode(token); err != nil || !dec.Valid() {
+		t.Errorf("Token signed with previous key should be valid during grace period (error: %v, validation error: %v)", err, dec.ValidationError())
+	}
+
+	select {
+	case e = <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Previous key was not retired after grace period")
+	}
+	if e.Type != KeyRetired || e.Key.GetKeyID() != first.GetKeyID() || len(e.Keys) != 1 {
+		t.Errorf("Manager retired key with unexpected event %+v", e)
+	}
+	if dec, err := jwt.Decode(token); err != nil || dec.Valid() {
+		t.Errorf("Token signed with retired key should be invalid (error: %v)", err)
+	}
+	if _, err := jwt.New([]byte(`{"test": 1}`)).Encode(); err != nil {
+		t.Errorf("Could not encode JWT after rotation: %s", err.Error())
+	}
+
+	m.generate = failingGenerate
+	if m.Rotate() == nil {
+		t.Error("Manager.Rotate() should fail when the provider cannot be generated")
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	m, err := NewManager("ES256", generate, 20*time.Millisecond, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %s", err.Error())
+	}
+	events := make(chan Event, 16)
+	m.SetEventHandler(func(e Event) { events <- e })
+	m.Start()
+	m.Start()
+	select {
+	case e := <-events:
+		if e.Type != KeyRotated {
+			t.Errorf("Manager should emit KeyRotated but emitted %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Manager did not rotate key after interval")
+	}
+	m.Stop()
+	m.Stop()
+	if l := len(m.Keys()); l < 2 {
+		t.Errorf("Manager.Keys() should contain previous keys during grace period but contained %d keys", l)
+	}
+}
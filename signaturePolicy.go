@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SignaturePolicy determines how many signatures of a token decoded from the JSON serialization have to be valid for the token to be valid.
+// Signatures are counted per signer which is identified by the algorithm and the key verifying the signature, so several signatures created with the same key only count once.
+// Signatures without a key ID are attributed to the current key of the provider as that is the key used to verify them.
+type SignaturePolicy struct {
+	all    bool
+	quorum int
+}
+
+var (
+	// AnySignature requires at least one signature to be valid. It is the default policy.
+	AnySignature = SignaturePolicy{false, 1}
+
+	// AllSignatures requires every signature to be valid and to be created by a different signer.
+	// Signatures can be removed from a token without invalidating the others, so use Quorum to require signatures from several signers.
+	AllSignatures = SignaturePolicy{true, 0}
+)
+
+// Quorum requires valid signatures from at least n different signers. Values below one are treated as one.
+func Quorum(n int) SignaturePolicy {
+	return SignaturePolicy{false, n}
+}
+
+// SetSignaturePolicy sets the policy used by DecodeJSON
+func SetSignaturePolicy(policy SignaturePolicy) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	signaturePolicy = policy
+}
+
+// check returns an error if the signatures do not satisfy the policy
+func (p SignaturePolicy) check(signatures []Signature) error {
+	valid := 0
+	signers := make(map[string]bool)
+	for _, sig := range signatures {
+		if sig.Valid() {
+			valid++
+			signers[sig.signer] = true
+		}
+	}
+	if p.all && valid != len(signatures) {
+		if len(signatures) == 1 {
+			return signatures[0].validationError
+		}
+		return fmt.Errorf("%d of %d signatures could not be verified", len(signatures)-valid, len(signatures))
+	}
+	if p.all && len(signers) != valid {
+		return errors.New("signatures must be created by different signers")
+	}
+	required := p.quorum
+	if required < 1 {
+		required = 1
+	}
+	if len(signers) >= required {
+		return nil
+	}
+	if valid == 0 {
+		if len(signatures) == 1 {
+			return signatures[0].validationError
+		}
+		return errors.New("none of the signatures could be verified")
+	}
+	return fmt.Errorf("only %d of %d required signers could be verified", len(signers), required)
+}
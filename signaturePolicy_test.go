@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
+func TestSignaturePolicy_check(t *testing.T) {
+	valid := Signature{Header{Typ: "JWT"}, nil, "test.a", nil}
+	other := Signature{Header{Typ: "JWT"}, nil, "test.b", nil}
+	invalid := Signature{Header{Typ: "JWT"}, nil, "test.c", errors.New("signature invalid")}
+	tests := []struct {
+		name       string
+		policy     SignaturePolicy
+		signatures []Signature
+		wantErr    bool
+	}{
+		{"AnyOneValid", AnySignature, []Signature{invalid, valid}, false},
+		{"AnyNoneValid", AnySignature, []Signature{invalid, invalid}, true},
+		{"AnySingleInvalid", AnySignature, []Signature{invalid}, true},
+		{"ZeroValueIsAny", SignaturePolicy{}, []Signature{invalid, valid}, false},
+		{"AllValid", AllSignatures, []Signature{valid, other}, false},
+		{"AllSameSigner", AllSignatures, []Signature{valid, valid}, true},
+		{"AllOneInvalid", AllSignatures, []Signature{valid, invalid}, true},
+		{"AllSingleInvalid", AllSignatures, []Signature{invalid}, true},
+		{"QuorumReached", Quorum(2), []Signature{valid, invalid, other}, false},
+		{"QuorumSameSigner", Quorum(2), []Signature{valid, invalid, valid}, true},
+		{"QuorumMissed", Quorum(2), []Signature{valid, invalid, invalid}, true},
+		{"QuorumNoneValid", Quorum(2), []Signature{invalid, invalid}, true},
+		{"QuorumAboveSignatures", Quorum(3), []Signature{valid, other}, true},
+		{"QuorumZero", Quorum(0), []Signature{invalid}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.check(tt.signatures); (err != nil) != tt.wantErr {
+				t.Errorf("SignaturePolicy.check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetSignaturePolicy(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	if err := SetSignatureProvider("other", TestAlgorithm("other")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	defer RemoveSignatureProvider("other")
+	token, err := New([]byte(`{"name":"test","use":"testing"}`)).EncodeJSON(Signer{Algorithm: "test"}, Signer{Algorithm: "other"})
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	RemoveSignatureProvider("other")
+
+	SetSignaturePolicy(AllSignatures)
+	defer SetSignaturePolicy(AnySignature)
+	got, signatures, err := DecodeJSON(token)
+	if err != nil {
+		t.Fatalf("DecodeJSON() failed: %s", err.Error())
+	}
+	if got.Valid() || !signatures[0].Valid() || signatures[1].Valid() {
+		t.Error("DecodeJSON() should return invalid token when not all signatures can be verified")
+	}
+	if got, _, _ := DecodeJSONWithPolicy(token, Quorum(1)); !got.Valid() {
+		t.Errorf("DecodeJSONWithPolicy() should ignore the policy set using SetSignaturePolicy: %v", got.ValidationError())
+	}
+
+	SetSignaturePolicy(AnySignature)
+	if got, _, _ := DecodeJSON(token); !got.Valid() || got.Header.Alg != "test" {
+		t.Errorf("DecodeJSON() should return valid token with header of the valid signature: %v", got.ValidationError())
+	}
+}
+
+// currentKeyTestAlgorithm is a signature provider for testing that sets a key ID and reports it as the key ID of its current key
+type currentKeyTestAlgorithm struct {
+	TestAlgorithm
+	kid string
+}
+
+func (alg currentKeyTestAlgorithm) Header(h *Header) {
+	alg.TestAlgorithm.Header(h)
+	h.Kid = alg.kid
+}
+
+func (alg currentKeyTestAlgorithm) CurrentKey() publickey.PublicKey {
+	return publickey.New(nil, alg.kid)
+}
+
+func TestSignaturePolicy_SameKeyWithoutKeyID(t *testing.T) {
+	defer SetSignatureProvider("test", TestAlgorithm("test")) // nolint:errcheck
+	if err := SetSignatureProvider("test", currentKeyTestAlgorithm{"test", "key_id"}); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	if err := SetSignatureProvider("test-without-kid", currentKeyTestAlgorithm{"test", ""}); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	defer RemoveSignatureProvider("test-without-kid")
+	token, err := New([]byte(`{"name":"test","use":"testing"}`)).EncodeJSON(Signer{Algorithm: "test"}, Signer{Algorithm: "test-without-kid"})
+	if err != nil {
+		t.Fatalf("Could not encode JWT: %s", err.Error())
+	}
+	got, signatures, err := DecodeJSONWithPolicy(token, Quorum(2))
+	if err != nil {
+		t.Fatalf("DecodeJSONWithPolicy() failed: %s", err.Error())
+	}
+	if !signatures[0].Valid() || !signatures[1].Valid() {
+		t.Fatal("Both signatures should be valid")
+	}
+	if got.Valid() {
+		t.Error("DecodeJSONWithPolicy() should not count signatures of the same key with and without key ID as different signers")
+	}
+	if got, _, _ := DecodeJSONWithPolicy(token, AllSignatures); got.Valid() {
+		t.Error("AllSignatures should reject signatures of the same key with and without key ID")
+	}
+}
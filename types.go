@@ -1,12 +1,29 @@
 package jwt
 
+import (
+	"github.com/fossoreslp/go-jwt/jwk"
+	"github.com/fossoreslp/go-jwt/publickey"
+)
+
 // Header contains the header data of a JSON web token
 type Header struct {
-	Typ string `json:"typ"`
-	Alg string `json:"alg"`
-	Kid string `json:"kid,omitempty"`
-	Jku string `json:"jku,omitempty"`
-	Crv string `json:"crv,omitempty"`
+	Typ  string   `json:"typ"`
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid,omitempty"`
+	Jku  string   `json:"jku,omitempty"`
+	Cty  string   `json:"cty,omitempty"`
+	B64  *bool    `json:"b64,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+	Crv  string   `json:"crv,omitempty"`
+	Enc  string   `json:"enc,omitempty"`
+	Zip  string   `json:"zip,omitempty"`
+	Epk  *jwk.Key `json:"epk,omitempty"`
+	Apu  string   `json:"apu,omitempty"`
+	Apv  string   `json:"apv,omitempty"`
+	P2s  string   `json:"p2s,omitempty"`
+	P2c  int      `json:"p2c,omitempty"`
+	// Extensions contains additional header parameters not defined by this package
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // JWT contains the decoded header and encoded content of a JSON web token
@@ -23,7 +40,29 @@ type SignatureProvider interface {
 	Header(*Header)
 }
 
+// KeyedSignatureProvider is a SignatureProvider that manages the public keys used for verification.
+// Providers have to be used as pointers to satisfy it as adding and removing keys modifies them.
+type KeyedSignatureProvider interface {
+	SignatureProvider
+	AddPublicKey(publickey.PublicKey) error
+	RemovePublicKey(string)
+	CurrentKey() publickey.PublicKey
+}
+
+// EncryptionProvider is an interface for key management algorithms used to encrypt and decrypt a JWE
+type EncryptionProvider interface {
+	WrapKey(int, *Header) ([]byte, []byte, error)
+	UnwrapKey([]byte, int, Header) ([]byte, error)
+	Header(*Header)
+}
+
 // ContentValidationProvider is an interface for verification providers used to validate the content of a JWT
 type ContentValidationProvider interface {
 	Validate([]byte) error
 }
+
+// KeyResolver is an interface for sources of keys used by signature providers to verify a JWS.
+// It receives the header and the decoded content of the token and returns all keys that may have been used to sign it.
+type KeyResolver interface {
+	Resolve(Header, []byte) ([]publickey.PublicKey, error)
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import "errors"
+
+// AlgorithmNone is the algorithm of unsecured JWTs as defined in RFC 7519 section 6
+const AlgorithmNone = "none"
+
+var errUnsecured = errors.New("unsecured JWTs using algorithm none are only supported by UnsafeEncodeUnsecured and UnsafeDecodeAllowingUnsecured")
+
+// UnsecuredProvider is the signature provider for unsecured JWTs which have an empty signature.
+// It cannot be registered and is only used by UnsafeEncodeUnsecured and UnsafeDecodeAllowingUnsecured.
+type UnsecuredProvider struct{}
+
+// Sign returns an empty signature
+func (UnsecuredProvider) Sign(data []byte) ([]byte, error) {
+	return nil, nil
+}
+
+// Verify returns an error unless the signature is empty
+func (UnsecuredProvider) Verify(data, signature []byte, h Header) error {
+	if len(signature) != 0 {
+		return errors.New("unsecured JWT must not contain a signature")
+	}
+	return nil
+}
+
+// Header sets the algorithm to none
+func (UnsecuredProvider) Header(h *Header) {
+	h.Alg = AlgorithmNone
+}
+
+// UnsafeEncodeUnsecured encodes a JWT without signing it. Anyone can modify the content of the resulting token.
+// It should only be used for testing or when the integrity of the token is guaranteed by other means.
+func (t JWT) UnsafeEncodeUnsecured() ([]byte, error) {
+	var alg UnsecuredProvider
+	alg.Header(&t.Header)
+	t.Header.normalizeCritical()
+	header, err := encodeHeader(t.Header)
+	if err != nil {
+		return nil, err
+	}
+	content, err := t.payload(true)
+	if err != nil {
+		return nil, err
+	}
+	return join(header, content, nil), nil
+}
+
+// UnsafeDecodeAllowingUnsecured works just like Decode but also accepts unsecured JWTs using the algorithm none.
+// The content of an unsecured token is not protected in any way so it must not be trusted unless its integrity is guaranteed by other means.
+// Whether the token was unsecured can be checked by comparing Header.Alg to AlgorithmNone.
+func UnsafeDecodeAllowingUnsecured(in []byte) (JWT, error) {
+	return decode(in, true)
+}
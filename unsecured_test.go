@@ -0,0 +1,111 @@
+package jwt
+
+import (
+	"testing"
+)
+
+func TestUnsecuredProvider(t *testing.T) {
+	var p UnsecuredProvider
+	var h Header
+	p.Header(&h)
+	if h.Alg != AlgorithmNone {
+		t.Errorf("UnsecuredProvider.Header() set alg to %s, want none", h.Alg)
+	}
+	if sig, err := p.Sign([]byte("data")); err != nil || len(sig) != 0 {
+		t.Errorf("UnsecuredProvider.Sign() = %v, %v, want empty signature", sig, err)
+	}
+	if err := p.Verify([]byte("data"), nil, h); err != nil {
+		t.Errorf("UnsecuredProvider.Verify() failed for empty signature: %s", err.Error())
+	}
+	if err := p.Verify([]byte("data"), []byte("signature"), h); err == nil {
+		t.Error("UnsecuredProvider.Verify() should fail for non-empty signature")
+	}
+}
+
+func TestUnsecuredRegistry(t *testing.T) {
+	if err := AddSignatureProvider(AlgorithmNone, TestAlgorithm(AlgorithmNone)); err == nil {
+		t.Error("AddSignatureProvider() should fail for algorithm none")
+	}
+	if err := SetSignatureProvider(AlgorithmNone, TestAlgorithm(AlgorithmNone)); err == nil {
+		t.Error("SetSignatureProvider() should fail for algorithm none")
+	}
+	if _, ok := signatureProviders[AlgorithmNone]; ok {
+		t.Error("SetSignatureProvider() registered a provider for algorithm none")
+	}
+	if err := SetSigningAlgorithm(AlgorithmNone); err == nil {
+		t.Error("SetSigningAlgorithm() should fail for algorithm none")
+	}
+	if _, err := (Header{Alg: AlgorithmNone}).getAlgorithm(); err == nil {
+		t.Error("Header.getAlgorithm() should never return a provider for algorithm none")
+	}
+}
+
+func TestUnsafeDecodeAllowingUnsecured(t *testing.T) {
+	if err := SetSignatureProvider("test", TestAlgorithm("test")); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
+	SetSigningAlgorithm("test")                                     // nolint:errcheck
+	AddValidationProvider("unsecured", testValidationProvider(0x0)) // nolint:errcheck
+	defer RemoveValidationProvider("unsecured")
+
+	unsecured, err := New([]byte(`{"name":"test"}`)).UnsafeEncodeUnsecured()
+	if err != nil {
+		t.Fatalf("JWT.UnsafeEncodeUnsecured() failed: %s", err.Error())
+	}
+	invalidContent, err := New([]byte{0xFF}).UnsafeEncodeUnsecured()
+	if err != nil {
+		t.Fatalf("JWT.UnsafeEncodeUnsecured() failed: %s", err.Error())
+	}
+	secured, err := New([]byte(`{"name":"test"}`)).Encode()
+	if err != nil {
+		t.Fatalf("JWT.Encode() failed: %s", err.Error())
+	}
+	header := "eyJ0eXAiOiJKV1QiLCJhbGciOiJub25lIn0" // {"typ":"JWT","alg":"none"}
+	payload := "eyJuYW1lIjoidGVzdCJ9"
+	withSignature := []byte(header + "." + payload + "." + string(b64encode(append([]byte(AlgorithmNone), header+"."+payload...))))
+
+	tests := []struct {
+		name        string
+		token       []byte
+		wantValid   bool
+		wantErr     bool
+		decodeValid bool
+	}{
+		{"Unsecured", unsecured, true, false, false},
+		{"Secured", secured, true, false, true},
+		{"WithSignature", withSignature, false, false, false},
+		{"ContentValidation", invalidContent, false, false, false},
+		{"InvalidSignature", []byte(header + "." + payload + ".A"), false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnsafeDecodeAllowingUnsecured(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnsafeDecodeAllowingUnsecured() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Valid() != tt.wantValid {
+				t.Errorf("UnsafeDecodeAllowingUnsecured() valid = %v (validation error: %v), want %v", got.Valid(), got.ValidationError(), tt.wantValid)
+			}
+			// Ordinary decoding must never accept unsecured tokens
+			got, err = Decode(tt.token)
+			if err == nil && got.Valid() != tt.decodeValid {
+				t.Errorf("Decode() valid = %v (validation error: %v), want %v", got.Valid(), got.ValidationError(), tt.decodeValid)
+			}
+		})
+	}
+}
+
+func TestUnsecured_OtherSerializations(t *testing.T) {
+	if err := SetSignatureProvider(AlgorithmNone, TestAlgorithm(AlgorithmNone)); err == nil {
+		defer RemoveSignatureProvider(AlgorithmNone)
+		t.Error("SetSignatureProvider() should fail for algorithm none")
+	}
+	got, _, err := DecodeJSON([]byte(`{"payload":"eyJuYW1lIjoidGVzdCJ9","protected":"eyJ0eXAiOiJKV1QiLCJhbGciOiJub25lIn0","signature":""}`))
+	if err != nil || got.Valid() {
+		t.Errorf("DecodeJSON() should return invalid token for algorithm none (error: %v)", err)
+	}
+	got, err = DecodeDetached([]byte("eyJ0eXAiOiJKV1QiLCJhbGciOiJub25lIn0.."), []byte(`{"name":"test"}`))
+	if err == nil && got.Valid() {
+		t.Error("DecodeDetached() should not accept unsecured tokens")
+	}
+}
@@ -19,14 +19,25 @@ func (jwt JWT) validate(data, signature []byte) error {
 	if err != nil {
 		return err
 	}
+	return jwt.validateWith(alg, data, signature)
+}
 
+// validateWith checks the signature using the supplied signature provider and validates the content
+func (jwt JWT) validateWith(alg SignatureProvider, data, signature []byte) error {
 	// Check the hash using the Verify function of the algorithm declared by the header
 	if err := alg.Verify(data, signature, jwt.Header); err != nil {
 		return err
 	}
 
+	return validateContent(jwt.Content)
+}
+
+// validateContent checks the content using all registered content validation providers
+func validateContent(content []byte) error {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 	for _, p := range validationProviders {
-		if err := p.Validate(jwt.Content); err != nil {
+		if err := p.Validate(content); err != nil {
 			return err
 		}
 	}
@@ -34,7 +45,13 @@ func (jwt JWT) validate(data, signature []byte) error {
 }
 
 func (h Header) getAlgorithm() (SignatureProvider, error) {
+	// Unsecured JWTs are never accepted using the registry, even if a provider has been registered for none
+	if h.Alg == AlgorithmNone {
+		return nil, errUnsecured
+	}
+	registryMu.RLock()
 	a, ok := signatureProviders[h.Alg]
+	registryMu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("algorithm %s is not supported", h.Alg)
 	}
@@ -44,7 +44,9 @@ func TestJWT_ValidationError(t *testing.T) {
 
 func TestHeader_getAlgorithm(t *testing.T) {
 	alg := TestAlgorithm("test")
-	SetSignatureProvider("test", alg)
+	if err := SetSignatureProvider("test", alg); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 	tests := []struct {
 		name    string
@@ -104,7 +106,9 @@ func (_ testValidationProvider) Validate(c []byte) error {
 
 func TestValidation(t *testing.T) {
 	alg := TestAlgorithm("test")
-	SetSignatureProvider("test", alg)
+	if err := SetSignatureProvider("test", alg); err != nil {
+		t.Fatalf("SetSignatureProvider() failed: %s", err.Error())
+	}
 	SetSigningAlgorithm("test") // nolint:errcheck
 
 	token := JWT{Header{Typ: "JWT", Alg: "test"}, []byte{0x00}, nil}